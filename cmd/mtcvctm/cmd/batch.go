@@ -1,16 +1,21 @@
 package cmd
 
 import (
+	"crypto"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/sirosfoundation/mtcvctm/internal/action"
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
 	"github.com/sirosfoundation/mtcvctm/pkg/config"
 	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/anoncreds"
+	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/jsonschema"
 	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/mddl"
 	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/vctmfmt"
 	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/w3c"
@@ -31,6 +36,43 @@ var (
 	batchNormalize      bool
 	batchDisableRules   string
 	batchVerboseRules   bool
+	batchIssuerMetadata bool
+	batchIssuer         string
+	batchCoverage       bool
+	batchCoverageJSON   string
+	batchEmitReadme     bool
+	batchIndent         string
+	batchStrict         bool
+	batchNoRegistry     bool
+	batchAssetBaseURL   string
+	batchProfile        string
+	batchEmitValueType  bool
+	batchMDDLIncludeSD  bool
+	batchHashImages     bool
+	batchVCTMDraft      int
+	batchRegistryPretty bool
+	batchRegistryFile   string
+	batchRegistryVer    string
+	batchGeneratedAt    string
+	batchWatch          bool
+	batchCheck          bool
+	batchDryRun         bool
+	batchLayout         string
+	batchMDDLEncoding   string
+	batchHTML           bool
+	batchSignKey        string
+	batchVCTMIntegrity  bool
+	batchLocaleFallback string
+	batchLanguage       string
+	batchResolveExtends bool
+	batchRemoteImages   bool
+	batchRemoteMaxBytes int64
+	batchRemoteTimeout  time.Duration
+	batchMaxInline      int64
+	batchNoSVGMinify    bool
+	batchInheritClaims  bool
+	batchSiteFiles      bool
+	batchRobotsFile     string
 )
 
 var batchCmd = &cobra.Command{
@@ -43,6 +85,7 @@ Supports multiple output formats:
   - vctm: SD-JWT VC Type Metadata (default)
   - mddl: mso_mdoc credential configuration (ISO 18013-5)
   - w3c:  W3C Verifiable Credential schema
+  - anoncreds: AnonCreds schema and credential definition stub
   - all:  Generate all formats
 
 This command is designed for use in GitHub Actions to automatically
@@ -51,7 +94,8 @@ update credential metadata files when markdown sources change.
 Example:
   mtcvctm batch --input ./credentials --output ./vctm --base-url https://registry.example.com
   mtcvctm batch --format all --input ./credentials --output ./dist
-  mtcvctm batch --github-action --vctm-branch vctm`,
+  mtcvctm batch --github-action --vctm-branch vctm
+  mtcvctm batch --input ./credentials --output ./dist --watch`,
 	RunE: runBatch,
 }
 
@@ -61,23 +105,131 @@ func init() {
 	batchCmd.Flags().StringVarP(&batchInputDir, "input", "i", ".", "Input directory containing markdown files")
 	batchCmd.Flags().StringVarP(&batchOutputDir, "output", "o", ".", "Output directory for credential files")
 	batchCmd.Flags().StringVar(&batchBaseURL, "base-url", "", "Base URL for generating image URLs")
+	batchCmd.Flags().StringVar(&batchAssetBaseURL, "asset-base-url", "", "Base URL for image and template URIs (default: base URL)")
 	batchCmd.Flags().BoolVar(&batchGitHubMode, "github-action", false, "Run in GitHub Action mode")
 	batchCmd.Flags().StringVar(&batchVCTMBranch, "vctm-branch", "vctm", "Branch name for VCTM files in GitHub Action mode")
 	batchCmd.Flags().StringVar(&batchCommitMsg, "commit-message", "Update VCTM files", "Commit message for GitHub Action mode")
 	batchCmd.Flags().BoolVar(&batchNoInlineImages, "no-inline-images", false, "Use URLs instead of embedding images as data URLs")
-	batchCmd.Flags().StringVarP(&batchFormatFlag, "format", "f", "vctm", "Output format(s): vctm, mddl, w3c, all (comma-separated)")
+	batchCmd.Flags().StringVarP(&batchFormatFlag, "format", "f", "vctm", "Output format(s): vctm, mddl, w3c, anoncreds, jsonschema, all (comma-separated)")
 	batchCmd.Flags().BoolVar(&batchNormalize, "normalize", false, "Apply normalization rules to fix legacy field names and add defaults")
 	batchCmd.Flags().StringVar(&batchDisableRules, "disable-rules", "", "Comma-separated list of normalization rules to disable")
 	batchCmd.Flags().BoolVar(&batchVerboseRules, "verbose-rules", false, "Show which normalization rules were applied")
+	batchCmd.Flags().BoolVar(&batchIssuerMetadata, "emit-issuer-metadata", false, "Generate a .well-known/openid-credential-issuer skeleton")
+	batchCmd.Flags().StringVar(&batchIssuer, "issuer", "", "Credential issuer identifier for issuer metadata (default: base URL)")
+	batchCmd.Flags().BoolVar(&batchCoverage, "coverage", false, "Continue past per-format failures and print a credential × format coverage report")
+	batchCmd.Flags().StringVar(&batchCoverageJSON, "coverage-json", "", "Write the coverage report as JSON to this path (implies --coverage)")
+	batchCmd.Flags().StringVar(&batchProfile, "profile", "", "Only process credentials tagged with this profile in their front matter")
+	batchCmd.Flags().BoolVar(&batchRegistryPretty, "registry-pretty", true, "Indent vctm-registry.json (use --registry-pretty=false for compact output)")
+	batchCmd.Flags().BoolVar(&batchNoRegistry, "no-registry", false, "Skip generating vctm-registry.json and the GitHub Action commit")
+	batchCmd.Flags().StringVar(&batchRegistryFile, "registry-filename", "", "Registry file relative to .well-known (default: registry_filename from the input directory's .mtcvctm.yaml, else vctm-registry.json)")
+	batchCmd.Flags().StringVar(&batchGeneratedAt, "generated-at", "", "RFC 3339 timestamp for the registry's generated field (default: SOURCE_DATE_EPOCH if set, else now)")
+	batchCmd.Flags().StringVar(&batchRegistryVer, "registry-version", "", "Version field of the registry (default: registry_version from the input directory's .mtcvctm.yaml, else 1.0)")
+	batchCmd.Flags().BoolVar(&batchEmitValueType, "emit-value-type", false, "Add an x-value_type hint to vctm claim entries")
+	batchCmd.Flags().BoolVar(&batchHashImages, "hash-image-names", false, "Copy images referenced by URL as <name>.<crc32>.<ext> and reference them under that name, for cache busting")
+	batchCmd.Flags().BoolVar(&batchMDDLIncludeSD, "mddl-include-sd", false, "Add a non-standard sd hint with the claim's selective disclosure setting to mddl claims")
+	batchCmd.Flags().IntVar(&batchVCTMDraft, "vctm-draft", 0, "SD-JWT VC draft of the vctm claims shape: 11 (object keyed by name) or 12 (path array) (default 12)")
+	batchCmd.Flags().BoolVar(&batchStrict, "strict", false, "Exit non-zero on warnings such as duplicate claims or multiple extends parents")
+	batchCmd.Flags().StringVar(&batchIndent, "indent", "", "JSON indentation: number of spaces or \"tab\" (default: 2 spaces)")
+	batchCmd.Flags().BoolVar(&batchEmitReadme, "emit-readme", false, "Generate a <id>.README.md documentation page per credential")
+	batchCmd.Flags().StringVar(&batchLanguage, "language", "", "Default language for display properties (default: the language front matter key, then en-US)")
+	batchCmd.Flags().StringVar(&batchLocaleFallback, "locale-fallback-order", "", "Comma-separated locales; the first one a credential provides becomes its default display")
+	batchCmd.Flags().BoolVar(&batchResolveExtends, "resolve-extends", false, "Fetch extends parents to compute missing extends#integrity values")
+	batchCmd.Flags().Int64Var(&batchMaxInline, "max-inline-bytes", 0, "Largest image to inline; larger ones are referenced by URL when a base URL is set (default: max_inline_bytes from config, else 64 KiB; negative: no limit)")
+	batchCmd.Flags().BoolVar(&batchNoSVGMinify, "no-svg-minify", false, "Inline SVG images without stripping comments, whitespace and editor metadata")
+	batchCmd.Flags().BoolVar(&batchRemoteImages, "resolve-remote-images", false, "Download images referenced by http(s) URL to compute their uri#integrity")
+	batchCmd.Flags().Int64Var(&batchRemoteMaxBytes, "remote-image-max-bytes", 0, "Size limit of downloaded remote images (default: remote_image_max_bytes from config, else 5 MiB)")
+	batchCmd.Flags().DurationVar(&batchRemoteTimeout, "remote-image-timeout", 0, "Timeout of each remote image download (default: remote_image_timeout from config, else 30s)")
+	batchCmd.Flags().BoolVar(&batchInheritClaims, "inherit-claims", false, "Merge the claims of each credential's extends parent")
+	batchCmd.Flags().BoolVar(&batchSiteFiles, "emit-site-files", false, "Write robots.txt and recommended response headers (_headers, headers.json) for static hosting")
+	batchCmd.Flags().StringVar(&batchRobotsFile, "robots-txt", "", "File with custom robots.txt content for --emit-site-files (default: allow all)")
+	batchCmd.Flags().BoolVar(&batchWatch, "watch", false, "Keep running and regenerate when markdown files or their images change")
+	batchCmd.Flags().StringVar(&batchLayout, "layout", "", "Output layout: flat (default) or by-format (one subdirectory per format)")
+	batchCmd.Flags().StringVar(&batchMDDLEncoding, "mddl-encoding", "", "Encoding of mddl output: json (default, .mdoc.json) or cbor (.mdoc.cbor)")
+	batchCmd.Flags().BoolVar(&batchHTML, "html", false, "Write an index.html listing every credential with links to its files")
+	batchCmd.Flags().BoolVar(&batchVCTMIntegrity, "vctm-integrity", false, "Record the SRI integrity hash of each generated VCTM as vctm_integrity in the registry")
+	batchCmd.Flags().StringVar(&batchSignKey, "sign-key", "", "PEM private key (EC P-256 or RSA) to sign the registry with a detached JWS")
+	batchCmd.Flags().BoolVar(&batchCheck, "check", false, "Compare regenerated outputs with the files in --output without writing, and fail if any differ")
+	batchCmd.Flags().BoolVar(&batchDryRun, "dry-run", false, "Generate everything but only print the paths and sizes of the files that would be written")
 }
 
 func runBatch(cmd *cobra.Command, args []string) error {
+	if batchWatch {
+		if batchGitHubMode {
+			return fmt.Errorf("--watch cannot be combined with --github-action")
+		}
+		if batchCheck {
+			return fmt.Errorf("--watch cannot be combined with --check")
+		}
+		if batchDryRun {
+			return fmt.Errorf("--watch cannot be combined with --dry-run")
+		}
+	}
+	if batchCheck && batchDryRun {
+		return fmt.Errorf("--check cannot be combined with --dry-run")
+	}
+	if batchWatch {
+		return runWatch(os.Stdout, func() []string {
+			return batchWatchTargets(batchInputDir)
+		}, batchProcess)
+	}
+	return batchProcess()
+}
+
+// batchLanguageFor returns the default language of a credential from the --language
+// flag, then the language or locale front matter key. It returns "" when neither is
+// set, leaving the language of the directory config or the en-US default
+func batchLanguageFor(mdFile string) string {
+	if batchLanguage != "" {
+		return batchLanguage
+	}
+	// Read errors are reported when the file is parsed
+	if content, err := os.ReadFile(mdFile); err == nil {
+		return parser.FrontMatterLanguage(content)
+	}
+	return ""
+}
+
+// batchProcess processes all markdown files in the input directory once
+func batchProcess() error {
 	// Parse formats
 	formatNames, err := formats.ParseFormats(batchFormatFlag)
 	if err != nil {
 		return err
 	}
 
+	if _, err := config.ParseIndent(batchIndent); err != nil {
+		return err
+	}
+
+	if err := config.ValidateVCTMDraft(batchVCTMDraft); err != nil {
+		return err
+	}
+
+	if err := config.ValidateLayout(batchLayout); err != nil {
+		return err
+	}
+
+	if err := config.ValidateMDDLEncoding(batchMDDLEncoding); err != nil {
+		return err
+	}
+
+	registryOpts, err := batchRegistryOptions()
+	if err != nil {
+		return err
+	}
+
+	// Load the signing key up front so a bad key fails before any output is written
+	var signKey crypto.Signer
+	if batchSignKey != "" {
+		if batchNoRegistry {
+			return fmt.Errorf("--sign-key cannot be combined with --no-registry")
+		}
+		signKey, err = action.LoadSigningKey(batchSignKey)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Initialize rules engine if normalization is enabled
 	var rulesEngine *rules.Engine
 	if batchNormalize {
@@ -102,23 +254,73 @@ func runBatch(cmd *cobra.Command, args []string) error {
 	}
 
 	// Ensure output directory exists
-	if err := os.MkdirAll(batchOutputDir, 0755); err != nil {
+	if batchDryRun {
+		fmt.Printf("Dry run: nothing is written to %s\n", batchOutputDir)
+	} else if err := os.MkdirAll(batchOutputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	var credentials []action.CredentialEntry
+	drifted := 0
+
+	// Coverage reporting records per-format failures instead of aborting the run
+	var coverage *coverageReport
+	if batchCoverage || batchCoverageJSON != "" {
+		coverage = newCoverageReport(formatNames)
+	}
+
+	var issuerMetadata *action.IssuerMetadata
+	if batchIssuerMetadata {
+		issuer := batchIssuer
+		if issuer == "" {
+			issuer = batchBaseURL
+		}
+		issuerMetadata = action.NewIssuerMetadata(issuer)
+	}
+
+	// Images shared between credentials are hashed, and remote images downloaded,
+	// once per run for each distinct set of download limits: directory configs
+	// can set resolve_remote_images and its limits per subtree
+	integrityCache := parser.NewIntegrityCache()
+	remoteCaches := make(map[remoteImageLimits]*parser.IntegrityCache)
 
 	// Process each markdown file
 	for _, mdFile := range mdFiles {
 		fmt.Printf("Processing: %s\n", mdFile)
 
-		// Create config for this file
-		cfg := &config.Config{
-			InputFile:    mdFile,
-			BaseURL:      batchBaseURL,
-			Language:     "en-US",
-			InlineImages: !batchNoInlineImages,
-			Formats:      batchFormatFlag,
+		// Build the config for this file from the defaults, the .mtcvctm.yaml files
+		// of its directory and the parent directories, and the flags (they take
+		// priority). The base URLs of a subtree override --base-url and
+		// --asset-base-url, which only apply where no directory config sets them.
+		cfg, err := directoryConfig(mdFile, batchInputDir, &config.Config{BaseURL: batchBaseURL, AssetBaseURL: batchAssetBaseURL})
+		if err != nil {
+			return err
+		}
+		cfg.Merge(&config.Config{
+			InputFile:           mdFile,
+			Language:            batchLanguageFor(mdFile),
+			LocaleFallbackOrder: config.ParseLocaleList(batchLocaleFallback),
+			InlineImages:        !batchNoInlineImages,
+			InlineImagesSet:     batchNoInlineImages, // only --no-inline-images overrides the config
+			Formats:             batchFormatFlag,
+			Indent:              batchIndent,
+			Strict:              batchStrict,
+			EmitValueType:       batchEmitValueType,
+			MDDLIncludeSD:       batchMDDLIncludeSD,
+			HashImageNames:      batchHashImages,
+			VCTMDraft:           batchVCTMDraft,
+			Layout:              batchLayout,
+			MDDLEncoding:        batchMDDLEncoding,
+			ResolveExtends:      batchResolveExtends,
+			MaxInlineBytes:      batchMaxInline,
+			NoSVGMinify:         batchNoSVGMinify,
+			ResolveRemoteImages: batchRemoteImages,
+			RemoteImageMaxBytes: batchRemoteMaxBytes,
+			RemoteImageTimeout:  batchRemoteTimeout,
+			InheritClaims:       batchInheritClaims,
+		})
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid directory config for %s: %w", mdFile, err)
 		}
 
 		// Determine relative path for output
@@ -127,23 +329,60 @@ func runBatch(cmd *cobra.Command, args []string) error {
 
 		// Parse markdown
 		p := parser.NewParser(cfg)
+		if cfg.ResolveRemoteImages {
+			limits := remoteImageLimits{timeout: cfg.RemoteImageTimeout, maxBytes: cfg.RemoteImageMaxBytes}
+			if remoteCaches[limits] == nil {
+				remoteCaches[limits] = parser.NewIntegrityCache()
+				remoteCaches[limits].EnableRemote(limits.timeout, limits.maxBytes)
+			}
+			p.SetIntegrityCache(remoteCaches[limits])
+		} else {
+			p.SetIntegrityCache(integrityCache)
+		}
 		cred, err := p.ParseToCredential(mdFile)
 		if err != nil {
 			return fmt.Errorf("failed to parse %s: %w", mdFile, err)
 		}
+		// Profile filtering: skip credentials not tagged with the requested profile
+		if batchProfile != "" && !cred.HasProfile(batchProfile) {
+			fmt.Printf("  Skipped: not in profile %s\n", batchProfile)
+			continue
+		}
+
+		for _, warning := range cred.Warnings {
+			fmt.Fprintf(os.Stderr, "  WARNING: %s: %s\n", mdFile, warning)
+		}
+		if cfg.Strict && len(cred.Warnings) > 0 {
+			return fmt.Errorf("%d warning(s) in %s (--strict)", len(cred.Warnings), mdFile)
+		}
 
 		// Generate all requested formats
-		outputs, err := p.Generate(cred, formatNames)
-		if err != nil {
-			return fmt.Errorf("failed to generate output for %s: %w", mdFile, err)
+		results := p.GenerateEach(cred, formatNames)
+		if coverage != nil {
+			coverage.Record(relPath, results)
+		}
+		outputs := make(map[string][]byte, len(results))
+		for _, formatName := range formatNames {
+			result := results[formatName]
+			if result.Err != nil {
+				if coverage == nil {
+					return fmt.Errorf("failed to generate output for %s: %w", mdFile, result.Err)
+				}
+				fmt.Printf("  ERROR: failed to generate %s: %v\n", formatName, result.Err)
+				continue
+			}
+			if !result.Skipped {
+				outputs[formatName] = result.Output
+			}
 		}
 
 		// Track generated files for this credential
 		var generatedFiles []string
+		vctmIntegrity := ""
 
 		// Write each format output
 		for formatName, data := range outputs {
-			outputPath := filepath.Join(batchOutputDir, parser.OutputFileName(baseName, formatName))
+			outputPath := filepath.Join(batchOutputDir, parser.OutputPath(baseName, formatName, cfg))
 
 			// Apply normalization rules to VCTM format if enabled
 			if rulesEngine != nil && formatName == "vctm" {
@@ -157,36 +396,97 @@ func runBatch(cmd *cobra.Command, args []string) error {
 							fmt.Printf("  Normalized: %s\n", result.String())
 						}
 						// Re-serialize with proper formatting
-						data, _ = json.MarshalIndent(dataMap, "", "  ")
+						data, _ = json.MarshalIndent(dataMap, "", cfg.GetIndent())
 					}
 				}
 			}
 
+			data, err := postProcessOutput(cfg.PostProcess, formatName, outputPath, data)
+			if err != nil {
+				return fmt.Errorf("failed to post-process output for %s: %w", mdFile, err)
+			}
+
+			if batchCheck {
+				changed, err := checkOutput(os.Stdout, outputPath, data)
+				if err != nil {
+					return err
+				}
+				if changed {
+					drifted++
+				}
+				continue
+			}
+
+			// Hash the exact bytes that would be written so vct#integrity matches what is served
+			if batchVCTMIntegrity && formatName == "vctm" {
+				vctmIntegrity = parser.CalculateIntegrityBytes(data)
+			}
+			generatedFiles = append(generatedFiles, filepath.Base(outputPath))
+
+			if batchDryRun {
+				fmt.Printf("  -> Would write %s: %s (%d bytes)\n", formatName, outputPath, len(data))
+				continue
+			}
+
 			// Ensure output subdirectory exists
 			if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 				return fmt.Errorf("failed to create output directory for %s: %w", mdFile, err)
 			}
 
-			if err := os.WriteFile(outputPath, data, 0644); err != nil {
+			if err := atomicfile.WriteFile(outputPath, data, 0644); err != nil {
 				return fmt.Errorf("failed to write %s: %w", outputPath, err)
 			}
 
-			generatedFiles = append(generatedFiles, filepath.Base(outputPath))
 			fmt.Printf("  -> Generated %s: %s\n", formatName, outputPath)
 		}
 
-		// Copy images referenced in the markdown to output directory
+		// A check only compares the format outputs
+		if batchCheck {
+			continue
+		}
+
+		// Write standalone JSON Schemas referenced by the outputs
+		if batchDryRun {
+			schemas, err := schemaDocuments(batchOutputDir, baseName, cred, cfg, outputs)
+			if err != nil {
+				return fmt.Errorf("failed to generate schema for %s: %w", mdFile, err)
+			}
+			for _, schema := range schemas {
+				generatedFiles = append(generatedFiles, filepath.Base(schema.path))
+				fmt.Printf("  -> Would write schema: %s (%d bytes)\n", schema.path, len(schema.data))
+			}
+		} else {
+			schemaFiles, err := writeSchemaDocuments(batchOutputDir, baseName, cred, cfg, outputs)
+			if err != nil {
+				return fmt.Errorf("failed to write schema for %s: %w", mdFile, err)
+			}
+			for _, schemaPath := range schemaFiles {
+				generatedFiles = append(generatedFiles, filepath.Base(schemaPath))
+				fmt.Printf("  -> Generated schema: %s\n", schemaPath)
+			}
+		}
+
+		// Copy local images referenced in the markdown to output directory; remote
+		// images keep their URL
 		parsed, _ := p.Parse(mdFile) // Re-parse to get images (cred doesn't have AbsolutePath)
 		for _, img := range parsed.Images {
-			if img.AbsolutePath != "" && img.Path != "" {
-				destPath := filepath.Join(batchOutputDir, img.Path)
+			if img.AbsolutePath != "" && img.Path != "" && !formats.IsRemoteURI(img.Path) {
+				// Hashed names match the URLs the generators emitted
+				assetPath := formats.AssetPath(img.Path, img.AbsolutePath, cfg)
+				destPath := filepath.Join(batchOutputDir, assetPath)
+				if batchDryRun {
+					if info, err := os.Stat(img.AbsolutePath); err == nil {
+						fmt.Printf("     Would copy image: %s (%d bytes)\n", assetPath, info.Size())
+					}
+					continue
+				}
 				if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
 					return fmt.Errorf("failed to create image directory for %s: %w", img.Path, err)
 				}
 				if err := copyFile(img.AbsolutePath, destPath); err != nil {
 					return fmt.Errorf("failed to copy image %s: %w", img.Path, err)
 				}
-				fmt.Printf("     Copied image: %s\n", img.Path)
+				fmt.Printf("     Copied image: %s\n", assetPath)
 			}
 		}
 
@@ -199,11 +499,22 @@ func runBatch(cmd *cobra.Command, args []string) error {
 
 		// Add to registry
 		entry := action.CredentialEntry{
-			VCT:          vctID,
-			Name:         cred.Name,
-			SourceFile:   relPath,
-			VCTMFile:     baseName + ".vctm", // Primary VCTM file for backward compat
-			LastModified: action.GetFileLastModified(mdFile),
+			VCT:           vctID,
+			Name:          cred.Name,
+			SourceFile:    relPath,
+			VCTMFile:      registryVCTMFile(baseName, cfg.Layout),
+			VCTMIntegrity: vctmIntegrity,
+			LastModified:  action.GetFileLastModified(mdFile),
+			Files:         make(map[string]string, len(outputs)),
+			Identifiers:   make(map[string]string, len(outputs)),
+		}
+		for formatName := range outputs {
+			entry.Files[formatName] = filepath.ToSlash(parser.OutputPath(baseName, formatName, cfg))
+			if gen, ok := formats.Get(formatName); ok {
+				if id := gen.DeriveIdentifier(cred, cfg); id != "" {
+					entry.Identifiers[formatName] = id
+				}
+			}
 		}
 
 		// Get commit history if available
@@ -211,6 +522,45 @@ func runBatch(cmd *cobra.Command, args []string) error {
 
 		credentials = append(credentials, entry)
 
+		// Generate per-credential documentation
+		if batchEmitReadme {
+			readmePath := filepath.Join(batchOutputDir, baseName+".README.md")
+			readme := generateCredentialReadme(cred, cfg, vctID)
+			if batchDryRun {
+				fmt.Printf("  -> Would write README: %s (%d bytes)\n", readmePath, len(readme))
+			} else {
+				if err := os.MkdirAll(filepath.Dir(readmePath), 0755); err != nil {
+					return fmt.Errorf("failed to create directory for README: %w", err)
+				}
+				if err := atomicfile.WriteFile(readmePath, []byte(readme), 0644); err != nil {
+					return fmt.Errorf("failed to write README: %w", err)
+				}
+				fmt.Printf("  -> Generated README: %s\n", readmePath)
+			}
+		}
+
+		// Collect OpenID4VCI credential configurations for the issuer metadata
+		if issuerMetadata != nil {
+			for _, formatName := range formatNames {
+				gen, ok := formats.Get(formatName)
+				if !ok {
+					continue
+				}
+				provider, ok := gen.(formats.CredentialConfigurationProvider)
+				if !ok {
+					continue
+				}
+				if _, ok := outputs[formatName]; !ok {
+					continue
+				}
+				configuration, err := provider.CredentialConfiguration(cred, cfg)
+				if err != nil {
+					return fmt.Errorf("failed to build issuer metadata for %s: %w", mdFile, err)
+				}
+				issuerMetadata.AddConfiguration(action.IssuerConfigurationID(baseName, formatName), configuration)
+			}
+		}
+
 		// Generate schema-meta scaffold if it doesn't already exist
 		schemaMetaPath := filepath.Join(batchOutputDir, baseName+".schema-meta.yaml")
 		if _, err := os.Stat(schemaMetaPath); os.IsNotExist(err) {
@@ -219,15 +569,23 @@ func runBatch(cmd *cobra.Command, args []string) error {
 			if _, err := os.Stat(srcSchemaMetaPath); os.IsNotExist(err) {
 				// Generate a scaffold
 				scaffold := generateSchemaMetaScaffold(cred.Name, generatedFiles)
+				if batchDryRun {
+					fmt.Printf("  -> Would scaffold: %s (%d bytes)\n", schemaMetaPath, len(scaffold))
+					continue
+				}
 				if err := os.MkdirAll(filepath.Dir(schemaMetaPath), 0755); err != nil {
 					return fmt.Errorf("failed to create directory for schema-meta: %w", err)
 				}
-				if err := os.WriteFile(schemaMetaPath, []byte(scaffold), 0644); err != nil {
+				if err := atomicfile.WriteFile(schemaMetaPath, []byte(scaffold), 0644); err != nil {
 					return fmt.Errorf("failed to write schema-meta scaffold: %w", err)
 				}
 				fmt.Printf("  -> Scaffolded: %s\n", schemaMetaPath)
 			} else {
 				// Copy existing schema-meta from source
+				if batchDryRun {
+					fmt.Printf("  -> Would copy schema-meta: %s\n", schemaMetaPath)
+					continue
+				}
 				if err := copyFile(srcSchemaMetaPath, schemaMetaPath); err != nil {
 					return fmt.Errorf("failed to copy schema-meta: %w", err)
 				}
@@ -236,16 +594,41 @@ func runBatch(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Generate registry
-	if err := action.GenerateRegistry(batchOutputDir, credentials); err != nil {
-		return fmt.Errorf("failed to generate registry: %w", err)
+	if batchCheck {
+		if drifted > 0 {
+			return fmt.Errorf("%d output file(s) in %s are out of date", drifted, batchOutputDir)
+		}
+		fmt.Printf("\nAll outputs in %s are up to date\n", batchOutputDir)
+		return nil
+	}
+
+	// A dry run reports the registry and site files instead of writing them
+	if batchDryRun {
+		reportRunFiles(os.Stdout, credentials, registryOpts, signKey != nil, issuerMetadata != nil)
+	} else if err := writeRunFiles(credentials, registryOpts, signKey, issuerMetadata); err != nil {
+		return err
 	}
 
-	fmt.Printf("\nGenerated registry with %d credential(s)\n", len(credentials))
-	fmt.Printf("Registry: %s/.well-known/vctm-registry.json\n", batchOutputDir)
+	if coverage != nil {
+		fmt.Println("\nFormat coverage:")
+		coverage.Print(os.Stdout)
+		if batchCoverageJSON != "" && batchDryRun {
+			fmt.Printf("Would write coverage report: %s\n", batchCoverageJSON)
+		} else if batchCoverageJSON != "" {
+			if err := coverage.WriteJSON(batchCoverageJSON); err != nil {
+				return err
+			}
+			fmt.Printf("Coverage report: %s\n", batchCoverageJSON)
+		}
+		if failures := coverage.Failures(); failures > 0 {
+			return fmt.Errorf("%d format output(s) failed to generate", failures)
+		}
+	}
 
 	// GitHub Action mode: commit and push
-	if batchGitHubMode {
+	if batchGitHubMode && !batchNoRegistry && batchDryRun {
+		fmt.Printf("\nGitHub Action mode: dry run, not committing to branch %s\n", batchVCTMBranch)
+	} else if batchGitHubMode && !batchNoRegistry {
 		fmt.Println("\nGitHub Action mode: committing changes...")
 		if err := action.SetupVCTMBranch(batchVCTMBranch, batchOutputDir); err != nil {
 			return fmt.Errorf("failed to setup VCTM branch: %w", err)
@@ -259,15 +642,170 @@ func runBatch(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// findMarkdownFiles finds all markdown files in a directory recursively
+// writeRunFiles writes the files that cover the whole run: the registry and its
+// signature, index.html, the issuer metadata and the static hosting files
+func writeRunFiles(credentials []action.CredentialEntry, registryOpts action.RegistryOptions, signKey crypto.Signer, issuerMetadata *action.IssuerMetadata) error {
+	// Generate registry
+	if batchNoRegistry {
+		fmt.Printf("\nProcessed %d credential(s), registry skipped\n", len(credentials))
+	} else {
+		if err := action.GenerateRegistryWithOptions(batchOutputDir, credentials, registryOpts); err != nil {
+			return fmt.Errorf("failed to generate registry: %w", err)
+		}
+
+		registryPath := action.RegistryPath(batchOutputDir, registryOpts.Filename)
+		fmt.Printf("\nGenerated registry with %d credential(s)\n", len(credentials))
+		fmt.Printf("Registry: %s\n", registryPath)
+
+		if signKey != nil {
+			jwsPath, err := action.SignRegistry(registryPath, signKey)
+			if err != nil {
+				return fmt.Errorf("failed to sign registry: %w", err)
+			}
+			fmt.Printf("Signature: %s\n", jwsPath)
+		}
+	}
+
+	if batchHTML {
+		if err := action.GenerateIndexHTML(batchOutputDir, credentials, action.GetRepositoryInfo()); err != nil {
+			return err
+		}
+		fmt.Printf("Index: %s/index.html\n", batchOutputDir)
+	}
+
+	if issuerMetadata != nil {
+		if err := action.GenerateIssuerMetadata(batchOutputDir, issuerMetadata); err != nil {
+			return fmt.Errorf("failed to generate issuer metadata: %w", err)
+		}
+		fmt.Printf("Issuer metadata: %s/.well-known/openid-credential-issuer\n", batchOutputDir)
+	}
+
+	if batchSiteFiles {
+		var robots string
+		if batchRobotsFile != "" {
+			data, err := os.ReadFile(batchRobotsFile)
+			if err != nil {
+				return fmt.Errorf("failed to read robots.txt: %w", err)
+			}
+			robots = string(data)
+		}
+		if err := action.GenerateSiteFiles(batchOutputDir, robots); err != nil {
+			return fmt.Errorf("failed to generate site files: %w", err)
+		}
+		fmt.Printf("Site files: %s/robots.txt, %s/_headers, %s/headers.json\n", batchOutputDir, batchOutputDir, batchOutputDir)
+	}
+
+	return nil
+}
+
+// reportRunFiles prints the files writeRunFiles would write, for --dry-run
+func reportRunFiles(w io.Writer, credentials []action.CredentialEntry, registryOpts action.RegistryOptions, signed, issuer bool) {
+	if batchNoRegistry {
+		fmt.Fprintf(w, "\nProcessed %d credential(s), registry skipped\n", len(credentials))
+	} else {
+		registryPath := action.RegistryPath(batchOutputDir, registryOpts.Filename)
+		fmt.Fprintf(w, "\nWould generate registry with %d credential(s)\n", len(credentials))
+		fmt.Fprintf(w, "Would write registry: %s\n", registryPath)
+		if signed {
+			fmt.Fprintf(w, "Would write signature: %s.jws\n", registryPath)
+		}
+	}
+	if batchHTML {
+		fmt.Fprintf(w, "Would write index: %s\n", filepath.Join(batchOutputDir, "index.html"))
+	}
+	if issuer {
+		fmt.Fprintf(w, "Would write issuer metadata: %s\n", filepath.Join(batchOutputDir, ".well-known", "openid-credential-issuer"))
+	}
+	if batchSiteFiles {
+		for _, name := range []string{"robots.txt", "_headers", "headers.json"} {
+			fmt.Fprintf(w, "Would write site file: %s\n", filepath.Join(batchOutputDir, name))
+		}
+	}
+}
+
+// batchRegistryOptions returns the registry options: --registry-filename and
+// --registry-version, then the .mtcvctm.yaml in the input directory, then the
+// defaults, and the --generated-at timestamp
+func batchRegistryOptions() (action.RegistryOptions, error) {
+	opts := action.RegistryOptions{
+		Compact:  !batchRegistryPretty,
+		Filename: batchRegistryFile,
+		Version:  batchRegistryVer,
+	}
+	if batchGeneratedAt != "" {
+		generatedAt, err := time.Parse(time.RFC3339, batchGeneratedAt)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --generated-at %q (want an RFC 3339 timestamp): %w", batchGeneratedAt, err)
+		}
+		opts.GeneratedAt = generatedAt
+	}
+
+	rootPath := filepath.Join(batchInputDir, config.DirConfigFileName)
+	if _, err := os.Stat(rootPath); err == nil {
+		rootCfg, err := config.LoadFromFile(rootPath)
+		if err != nil {
+			return opts, err
+		}
+		if opts.Filename == "" {
+			opts.Filename = rootCfg.RegistryFilename
+		}
+		if opts.Version == "" {
+			opts.Version = rootCfg.RegistryVersion
+		}
+	}
+
+	if err := config.ValidateRegistryFilename(opts.Filename); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// remoteImageLimits are the download limits of a run's remote image cache
+type remoteImageLimits struct {
+	timeout  time.Duration
+	maxBytes int64
+}
+
+// directoryConfig returns the config of a markdown file below root: the defaults,
+// then fallback (when not nil), then the .mtcvctm.yaml files from root down to the
+// file's directory, nearer files overriding farther ones
+func directoryConfig(mdFile, root string, fallback *config.Config) (*config.Config, error) {
+	cfg := config.DefaultConfig()
+	if fallback != nil {
+		cfg.Merge(fallback)
+	}
+	dirCfg, err := config.DiscoverForFile(mdFile, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load directory config for %s: %w", mdFile, err)
+	}
+	if dirCfg != nil {
+		cfg.Merge(dirCfg)
+	}
+	return cfg, nil
+}
+
+// findMarkdownFiles finds all markdown files in a directory recursively,
+// honouring a .mtcvctmignore file in the directory
 func findMarkdownFiles(dir string) ([]string, error) {
 	var files []string
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	ignore, err := loadIgnoreFile(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
+		if relPath, relErr := filepath.Rel(dir, path); relErr == nil && relPath != "." && ignore.Match(relPath, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		if info.IsDir() {
 			// Skip hidden directories and common non-content directories
 			name := info.Name()
@@ -294,22 +832,19 @@ func findMarkdownFiles(dir string) ([]string, error) {
 	return files, err
 }
 
-// copyFile copies a file from src to dst
-func copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
-	if err != nil {
-		return err
+// registryVCTMFile returns the registry path of a credential's primary VCTM file,
+// which keeps the .vctm name for backward compatibility and follows the output layout
+func registryVCTMFile(baseName, layout string) string {
+	file := baseName + ".vctm"
+	if layout == config.LayoutByFormat {
+		file = filepath.Join("vctm", file)
 	}
-	defer sourceFile.Close()
-
-	destFile, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer destFile.Close()
+	return filepath.ToSlash(file)
+}
 
-	_, err = io.Copy(destFile, sourceFile)
-	return err
+// copyFile copies a file from src to dst
+func copyFile(src, dst string) error {
+	return atomicfile.CopyFile(src, dst, 0644)
 }
 
 // generateSchemaMetaScaffold creates a starter schema-meta.yaml for a credential.
@@ -1,8 +1,27 @@
 package cmd
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+	"github.com/spf13/pflag"
 )
 
 func TestGenerateSchemaMetaScaffold(t *testing.T) {
@@ -44,3 +63,877 @@ func TestGenerateSchemaMetaScaffold(t *testing.T) {
 		})
 	}
 }
+
+// runBatchWithArgs resets all batch flags to their defaults, parses args and runs the batch command
+func runBatchWithArgs(t *testing.T, args ...string) error {
+	t.Helper()
+	batchCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	})
+	if err := batchCmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	return runBatch(batchCmd, nil)
+}
+
+// captureStdout returns what fn prints to os.Stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = original }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	fn()
+	w.Close()
+	return string(<-done)
+}
+
+// writeTestMarkdown writes a markdown file into dir, creating parent directories
+func writeTestMarkdown(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBatch_EmitIssuerMetadata(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity Credential\n\nAn identity credential.\n\n## Claims\n\n- `given_name` (string): Given name [mandatory]\n")
+	writeTestMarkdown(t, inputDir, "diploma.md", "# Diploma\n\nA diploma credential.\n\n## Claims\n\n- `degree` (string): Degree\n")
+
+	err := runBatchWithArgs(t,
+		"--input", inputDir,
+		"--output", outputDir,
+		"--base-url", "https://registry.example.com",
+		"--format", "vctm,mddl",
+		"--emit-issuer-metadata",
+	)
+	if err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, ".well-known", "openid-credential-issuer"))
+	if err != nil {
+		t.Fatalf("issuer metadata not written: %v", err)
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		t.Fatalf("issuer metadata is not valid JSON: %v", err)
+	}
+
+	if metadata["credential_issuer"] != "https://registry.example.com" {
+		t.Errorf("credential_issuer = %v", metadata["credential_issuer"])
+	}
+
+	configs, ok := metadata["credential_configurations_supported"].(map[string]interface{})
+	if !ok {
+		t.Fatal("credential_configurations_supported should be an object")
+	}
+
+	wantFormats := map[string]string{
+		"identity_vctm": "dc+sd-jwt",
+		"identity_mddl": "mso_mdoc",
+		"diploma_vctm":  "dc+sd-jwt",
+		"diploma_mddl":  "mso_mdoc",
+	}
+	if len(configs) != len(wantFormats) {
+		t.Errorf("len(credential_configurations_supported) = %d, want %d", len(configs), len(wantFormats))
+	}
+	for id, wantFormat := range wantFormats {
+		entry, ok := configs[id].(map[string]interface{})
+		if !ok {
+			t.Errorf("missing configuration %q", id)
+			continue
+		}
+		if entry["format"] != wantFormat {
+			t.Errorf("%s format = %v, want %s", id, entry["format"], wantFormat)
+		}
+	}
+
+	diploma := configs["diploma_mddl"].(map[string]interface{})
+	if diploma["doctype"] != "com.example.registry.credentials.diploma" {
+		t.Errorf("diploma doctype = %v", diploma["doctype"])
+	}
+}
+
+func TestBatch_DirectoryBaseURL(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	writeTestMarkdown(t, inputDir, "reg-a/.mtcvctm.yaml", "base_url: https://a.example.com\n")
+	writeTestMarkdown(t, inputDir, "reg-b/.mtcvctm.yaml", "base_url: https://b.example.com/\n")
+	writeTestMarkdown(t, inputDir, "reg-a/identity.md", "# Identity\n\n## Claims\n\n- `given_name` (string): Given name\n")
+	writeTestMarkdown(t, inputDir, "reg-b/nested/diploma.md", "# Diploma\n\n## Claims\n\n- `degree` (string): Degree\n")
+	writeTestMarkdown(t, inputDir, "other.md", "# Other\n\n## Claims\n\n- `value` (string): Value\n")
+
+	err := runBatchWithArgs(t,
+		"--input", inputDir,
+		"--output", outputDir,
+		"--base-url", "https://default.example.com",
+		"--format", "vctm",
+	)
+	if err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	wantVCT := map[string]string{
+		"reg-a/identity.vctm.json":       "https://a.example.com/identity",
+		"reg-b/nested/diploma.vctm.json": "https://b.example.com/diploma",
+		"other.vctm.json":                "https://default.example.com/other",
+	}
+	for file, want := range wantVCT {
+		data, err := os.ReadFile(filepath.Join(outputDir, file))
+		if err != nil {
+			t.Errorf("missing output %s: %v", file, err)
+			continue
+		}
+		var vctm map[string]interface{}
+		if err := json.Unmarshal(data, &vctm); err != nil {
+			t.Fatalf("%s is not valid JSON: %v", file, err)
+		}
+		if vctm["vct"] != want {
+			t.Errorf("%s vct = %v, want %s", file, vctm["vct"], want)
+		}
+	}
+}
+
+func TestBatch_DirectoryConfigPrecedence(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "de/.mtcvctm.yaml", "language: de-DE\nvctm_draft: 11\n")
+	writeTestMarkdown(t, inputDir, "de/nested/ausweis.md", "# Personalausweis\n\n## Claims\n\n- `given_name` \"Vorname\" (string): Vorname\n")
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n## Claims\n\n- `given_name` \"Given Name\" (string): Given name\n")
+
+	tests := []struct {
+		name string
+		args []string
+		want map[string]string
+	}{
+		{"inherited", nil, map[string]string{"de/nested/ausweis": "de-DE", "identity": "en-US"}},
+		{"flag", []string{"--language", "sv"}, map[string]string{"de/nested/ausweis": "sv", "identity": "sv"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			args := append([]string{"--input", inputDir, "--output", outputDir}, tt.args...)
+			if err := runBatchWithArgs(t, args...); err != nil {
+				t.Fatalf("runBatch() error = %v", err)
+			}
+
+			for name, want := range tt.want {
+				var doc struct {
+					Display []struct {
+						Locale string `json:"locale"`
+					} `json:"display"`
+					Claims json.RawMessage `json:"claims"`
+				}
+				readJSONFile(t, filepath.Join(outputDir, name+".vctm.json"), &doc)
+				if len(doc.Display) != 1 || doc.Display[0].Locale != want {
+					t.Errorf("%s display = %+v, want locale %s", name, doc.Display, want)
+				}
+				// vctm_draft: 11 from the directory config keys claims by name
+				wantObject := name == "de/nested/ausweis"
+				if isObject := strings.HasPrefix(string(doc.Claims), "{"); isObject != wantObject {
+					t.Errorf("%s claims = %s, want the object shape %v", name, doc.Claims, wantObject)
+				}
+			}
+		})
+	}
+}
+
+func TestBatch_DirectoryClaimsSection(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	legacy := "# Legacy\n\nA credential without a claims heading.\n\n- `given_name` (string): Given name\n"
+	writeTestMarkdown(t, inputDir, "strict/.mtcvctm.yaml", "claims_section: strict\n")
+	writeTestMarkdown(t, inputDir, "strict/legacy.md", legacy)
+	writeTestMarkdown(t, inputDir, "legacy.md", legacy)
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	for file, want := range map[string]int{"legacy.vctm.json": 1, "strict/legacy.vctm.json": 0} {
+		var doc struct {
+			Claims []json.RawMessage `json:"claims"`
+		}
+		readJSONFile(t, filepath.Join(outputDir, file), &doc)
+		if len(doc.Claims) != want {
+			t.Errorf("%s has %d claim(s), want %d", file, len(doc.Claims), want)
+		}
+	}
+}
+
+func TestBatch_Indent(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n## Claims\n\n- `given_name` (string): Given name\n")
+
+	for indent, prefix := range map[string]string{"4": "{\n    \"", "tab": "{\n\t\""} {
+		outputDir := t.TempDir()
+		if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--format", "vctm,w3c", "--indent", indent); err != nil {
+			t.Fatalf("runBatch() error = %v", err)
+		}
+		for _, name := range []string{"identity.vctm.json", "identity.vc.json"} {
+			data, err := os.ReadFile(filepath.Join(outputDir, name))
+			if err != nil {
+				t.Fatalf("missing output %s: %v", name, err)
+			}
+			if !strings.HasPrefix(string(data), prefix) {
+				t.Errorf("%s with --indent %s starts with %q", name, indent, string(data)[:8])
+			}
+		}
+	}
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", t.TempDir(), "--indent", "wide"); err == nil {
+		t.Error("expected error for invalid --indent")
+	}
+}
+
+func TestBatch_W3CSchemaDocument(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n## Claims\n\n- `given_name` (string): Given name [mandatory]\n")
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--format", "w3c", "--base-url", "https://registry.example.com"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	var vc struct {
+		CredentialSchema map[string]interface{} `json:"credentialSchema"`
+	}
+	readJSONFile(t, filepath.Join(outputDir, "identity.vc.json"), &vc)
+	if vc.CredentialSchema["id"] != "https://registry.example.com/identity.schema.json" || vc.CredentialSchema["type"] != "JsonSchema" {
+		t.Errorf("credentialSchema = %v", vc.CredentialSchema)
+	}
+
+	var schema map[string]interface{}
+	readJSONFile(t, filepath.Join(outputDir, "identity.schema.json"), &schema)
+	if schema["$id"] != vc.CredentialSchema["id"] {
+		t.Errorf("schema $id = %v, want %v", schema["$id"], vc.CredentialSchema["id"])
+	}
+	subject := schema["properties"].(map[string]interface{})["credentialSubject"].(map[string]interface{})
+	if _, ok := subject["properties"].(map[string]interface{})["given_name"]; !ok {
+		t.Errorf("schema credentialSubject = %v, want given_name", subject)
+	}
+}
+
+// readJSONFile unmarshals the JSON file at path into v
+func readJSONFile(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing output %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("%s is not valid JSON: %v", path, err)
+	}
+}
+
+func TestBatch_NoRegistry(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n## Claims\n\n- `given_name` (string): Given name\n")
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--no-registry"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "identity.vctm.json")); err != nil {
+		t.Errorf("credential output not written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, ".well-known", "vctm-registry.json")); !os.IsNotExist(err) {
+		t.Errorf("registry should not be written with --no-registry, stat error = %v", err)
+	}
+}
+
+func TestBatch_RegistryFilenameAndVersion(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n## Claims\n\n- `given_name` (string): Given name\n")
+	if err := os.WriteFile(filepath.Join(inputDir, ".mtcvctm.yaml"), []byte("registry_filename: types/registry.json\nregistry_version: \"1.1\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var registry struct {
+		Version string `json:"version"`
+	}
+
+	// The input directory config applies when the flags are not given
+	outputDir := t.TempDir()
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+	readJSONFile(t, filepath.Join(outputDir, ".well-known", "types", "registry.json"), &registry)
+	if registry.Version != "1.1" {
+		t.Errorf("version = %q, want 1.1 from .mtcvctm.yaml", registry.Version)
+	}
+
+	// Flags take precedence
+	outputDir = t.TempDir()
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--registry-filename", "credentials.json", "--registry-version", "2.0"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+	readJSONFile(t, filepath.Join(outputDir, ".well-known", "credentials.json"), &registry)
+	if registry.Version != "2.0" {
+		t.Errorf("version = %q, want 2.0 from --registry-version", registry.Version)
+	}
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", t.TempDir(), "--registry-filename", "../registry.json"); err == nil {
+		t.Error("--registry-filename outside .well-known expected error")
+	}
+}
+
+func TestBatch_RegistryFormatFiles(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n## Claims\n\n- `given_name` (string): Given name\n")
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--format", "all", "--base-url", "https://registry.example.com"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	var registry struct {
+		Credentials []struct {
+			VCT         string            `json:"vct"`
+			VCTMFile    string            `json:"vctm_file"`
+			Files       map[string]string `json:"files"`
+			Identifiers map[string]string `json:"identifiers"`
+		} `json:"credentials"`
+	}
+	readJSONFile(t, filepath.Join(outputDir, ".well-known", "vctm-registry.json"), &registry)
+	if len(registry.Credentials) != 1 {
+		t.Fatalf("len(credentials) = %d, want 1", len(registry.Credentials))
+	}
+	entry := registry.Credentials[0]
+
+	wantFiles := map[string]string{
+		"vctm": "identity.vctm.json",
+		"mddl": "identity.mdoc.json",
+		"w3c":  "identity.vc.json",
+	}
+	wantIDs := map[string]string{
+		"vctm": "https://registry.example.com/identity",
+		"mddl": "com.example.registry.credentials.identity",
+		"w3c":  "Identity",
+	}
+	for format, file := range wantFiles {
+		if entry.Files[format] != file {
+			t.Errorf("files[%s] = %q, want %q", format, entry.Files[format], file)
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, entry.Files[format])); err != nil {
+			t.Errorf("files[%s] does not name a generated file: %v", format, err)
+		}
+		if entry.Identifiers[format] != wantIDs[format] {
+			t.Errorf("identifiers[%s] = %q, want %q", format, entry.Identifiers[format], wantIDs[format])
+		}
+	}
+	if entry.VCTMFile == "" || entry.VCT != entry.Identifiers["vctm"] {
+		t.Errorf("vctm_file = %q, vct = %q: backward compatible fields not kept", entry.VCTMFile, entry.VCT)
+	}
+}
+
+func TestBatch_DryRun(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n![Logo](logo.png)\n\n## Claims\n\n- `given_name` (string): Given name\n")
+	if err := os.WriteFile(filepath.Join(inputDir, "logo.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := filepath.Join(t.TempDir(), "dist")
+
+	var runErr error
+	out := captureStdout(t, func() {
+		runErr = runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--format", "vctm,w3c", "--html", "--github-action", "--dry-run")
+	})
+	if runErr != nil {
+		t.Fatalf("runBatch() error = %v", runErr)
+	}
+	for _, want := range []string{
+		"Would write vctm: " + filepath.Join(outputDir, "identity.vctm.json") + " (",
+		"Would write w3c: " + filepath.Join(outputDir, "identity.vc.json") + " (",
+		"Would copy image: logo.png (3 bytes)",
+		"Would generate registry with 1 credential(s)",
+		"Would write registry: " + filepath.Join(outputDir, ".well-known", "vctm-registry.json"),
+		"Would write index: " + filepath.Join(outputDir, "index.html"),
+		"dry run, not committing to branch vctm",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Errorf("--dry-run created the output directory, stat error = %v", err)
+	}
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--dry-run", "--check"); err == nil {
+		t.Error("--dry-run with --check expected error")
+	}
+}
+
+func TestBatch_ProfileOutputDirs(t *testing.T) {
+	inputDir := t.TempDir()
+	distDir := t.TempDir()
+
+	writeTestMarkdown(t, inputDir, "pid.md", "---\nprofiles: [pid]\n---\n\n# PID\n\n## Claims\n\n- `given_name` (string): Given name\n")
+	writeTestMarkdown(t, inputDir, "ehic.md", "---\nprofiles: ehic\n---\n\n# EHIC\n\n## Claims\n\n- `card_number` (string): Card number\n")
+	writeTestMarkdown(t, inputDir, "shared.md", "---\nprofiles: [pid, ehic]\n---\n\n# Shared\n\n## Claims\n\n- `value` (string): Value\n")
+	writeTestMarkdown(t, inputDir, "untagged.md", "# Untagged\n\n## Claims\n\n- `value` (string): Value\n")
+
+	wantSources := map[string][]string{
+		"pid":  {"pid.md", "shared.md"},
+		"ehic": {"ehic.md", "shared.md"},
+	}
+	for profile, want := range wantSources {
+		outputDir := filepath.Join(distDir, profile)
+		if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--profile", profile); err != nil {
+			t.Fatalf("runBatch(--profile %s) error = %v", profile, err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(outputDir, ".well-known", "vctm-registry.json"))
+		if err != nil {
+			t.Fatalf("registry for %s not written: %v", profile, err)
+		}
+		var registry struct {
+			Credentials []struct {
+				SourceFile string `json:"source_file"`
+			} `json:"credentials"`
+		}
+		if err := json.Unmarshal(data, &registry); err != nil {
+			t.Fatalf("registry for %s is not valid JSON: %v", profile, err)
+		}
+
+		got := make(map[string]bool)
+		for _, cred := range registry.Credentials {
+			got[cred.SourceFile] = true
+		}
+		if len(got) != len(want) {
+			t.Errorf("%s registry sources = %v, want %v", profile, got, want)
+		}
+		for _, source := range want {
+			if !got[source] {
+				t.Errorf("%s registry missing %s", profile, source)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, "untagged.vctm.json")); !os.IsNotExist(err) {
+			t.Errorf("untagged credential should not be written for profile %s", profile)
+		}
+	}
+}
+
+func TestBatch_EmitSiteFiles(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity Credential\n\nAn identity credential.\n")
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--emit-site-files"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	robots, err := os.ReadFile(filepath.Join(outputDir, "robots.txt"))
+	if err != nil {
+		t.Fatalf("robots.txt not written: %v", err)
+	}
+	if string(robots) != "User-agent: *\nAllow: /\n" {
+		t.Errorf("robots.txt = %q, want allow all", robots)
+	}
+
+	headers, err := os.ReadFile(filepath.Join(outputDir, "_headers"))
+	if err != nil {
+		t.Fatalf("_headers not written: %v", err)
+	}
+	if !strings.Contains(string(headers), "/.well-known/vctm-registry.json\n  Access-Control-Allow-Origin: *\n  Cache-Control: public, max-age=60\n") {
+		t.Errorf("_headers missing registry rule:\n%s", headers)
+	}
+
+	var rules []map[string]interface{}
+	readJSONFile(t, filepath.Join(outputDir, "headers.json"), &rules)
+	if len(rules) == 0 {
+		t.Error("headers.json has no rules")
+	}
+}
+
+func TestBatch_StrictWarnings(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n## Claims\n\n- `given_name` (string): Given name\n- `given_name` (string): First name\n")
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", t.TempDir()); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", t.TempDir(), "--strict"); err == nil {
+		t.Error("runBatch() with --strict should fail on a duplicate claim")
+	}
+}
+
+func TestBatch_Layout(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n## Claims\n\n- `given_name` (string): Given name\n")
+
+	tests := []struct {
+		layout       string
+		wantFiles    []string
+		wantVCTMFile string
+	}{
+		{"flat", []string{"identity.vctm.json", "identity.vc.json"}, "identity.vctm"},
+		{"by-format", []string{filepath.Join("vctm", "identity.vctm.json"), filepath.Join("w3c", "identity.vc.json")}, "vctm/identity.vctm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			outputDir := t.TempDir()
+			if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--format", "vctm,w3c", "--layout", tt.layout); err != nil {
+				t.Fatalf("runBatch() error = %v", err)
+			}
+			for _, file := range tt.wantFiles {
+				if _, err := os.Stat(filepath.Join(outputDir, file)); err != nil {
+					t.Errorf("%s not written: %v", file, err)
+				}
+			}
+
+			var registry struct {
+				Credentials []struct {
+					VCTMFile string `json:"vctm_file"`
+				} `json:"credentials"`
+			}
+			readJSONFile(t, filepath.Join(outputDir, ".well-known", "vctm-registry.json"), &registry)
+			if len(registry.Credentials) != 1 || registry.Credentials[0].VCTMFile != tt.wantVCTMFile {
+				t.Errorf("registry credentials = %+v, want vctm_file %s", registry.Credentials, tt.wantVCTMFile)
+			}
+		})
+	}
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", t.TempDir(), "--layout", "nested"); err == nil {
+		t.Error("runBatch() should reject an unknown layout")
+	}
+}
+
+func TestBatch_HTMLIndex(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity Credential\n\n## Claims\n\n- `given_name` (string): Given name\n")
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--html", "--layout", "by-format"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("index.html not written: %v", err)
+	}
+	if !strings.Contains(string(data), "Identity Credential") || !strings.Contains(string(data), `href="vctm/identity.vctm.json"`) {
+		t.Errorf("index.html missing the credential:\n%s", data)
+	}
+}
+
+func TestBatch_SignKey(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity Credential\n\n## Claims\n\n- `given_name` (string): Given name\n")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "registry-key.pem")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--sign-key", keyPath); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+	jws, err := os.ReadFile(filepath.Join(outputDir, ".well-known", "vctm-registry.json.jws"))
+	if err != nil {
+		t.Fatalf("registry signature not written: %v", err)
+	}
+	if parts := strings.Split(string(jws), "."); len(parts) != 3 || parts[1] != "" {
+		t.Errorf("signature is not a compact detached JWS: %q", jws)
+	}
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--sign-key", keyPath, "--no-registry"); err == nil {
+		t.Error("--sign-key with --no-registry expected error")
+	}
+}
+
+func TestBatch_Language(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "ausweis.md", "---\nlanguage: de-DE\n---\n\n# Personalausweis\n\n## Claims\n\n- `given_name` \"Vorname\" (string): Vorname\n")
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity Credential\n\n## Claims\n\n- `given_name` \"Given Name\" (string): Given name\n")
+
+	tests := []struct {
+		name string
+		args []string
+		want map[string]string
+	}{
+		{"front matter", nil, map[string]string{"ausweis": "de-DE", "identity": "en-US"}},
+		{"flag", []string{"--language", "sv"}, map[string]string{"ausweis": "sv", "identity": "sv"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			args := append([]string{"--input", inputDir, "--output", outputDir}, tt.args...)
+			if err := runBatchWithArgs(t, args...); err != nil {
+				t.Fatalf("runBatch() error = %v", err)
+			}
+
+			for name, want := range tt.want {
+				var doc struct {
+					Display []struct {
+						Locale string `json:"locale"`
+					} `json:"display"`
+					Claims []struct {
+						Display []struct {
+							Locale string `json:"locale"`
+						} `json:"display"`
+					} `json:"claims"`
+				}
+				readJSONFile(t, filepath.Join(outputDir, name+".vctm.json"), &doc)
+				if len(doc.Display) != 1 || doc.Display[0].Locale != want {
+					t.Errorf("%s display = %+v, want locale %s", name, doc.Display, want)
+				}
+				if len(doc.Claims) != 1 || len(doc.Claims[0].Display) != 1 || doc.Claims[0].Display[0].Locale != want {
+					t.Errorf("%s claim display = %+v, want locale %s", name, doc.Claims, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBatch_MaxInlineBytes(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity Credential\n\n![Logo](logo.png)\n\n## Claims\n\n- `given_name` (string): Given name\n")
+	if err := os.WriteFile(filepath.Join(inputDir, "logo.png"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--base-url", "https://registry.example.com", "--max-inline-bytes", "50"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+	var doc struct {
+		Display []struct {
+			Rendering struct {
+				Simple struct {
+					Logo map[string]string `json:"logo"`
+				} `json:"simple"`
+			} `json:"rendering"`
+		} `json:"display"`
+	}
+	readJSONFile(t, filepath.Join(outputDir, "identity.vctm.json"), &doc)
+	if uri := doc.Display[0].Rendering.Simple.Logo["uri"]; uri != "https://registry.example.com/logo.png" {
+		t.Errorf("logo uri = %.40q, want the URL of the oversized logo", uri)
+	}
+
+	err := runBatchWithArgs(t, "--input", inputDir, "--output", t.TempDir(), "--base-url", "https://registry.example.com", "--max-inline-bytes", "50", "--strict")
+	if err == nil || !strings.Contains(err.Error(), "--strict") {
+		t.Errorf("runBatch(--strict) error = %v, want a strict warning error", err)
+	}
+
+	// max_inline_bytes from a directory config applies when the flag is not given
+	if err := os.WriteFile(filepath.Join(inputDir, ".mtcvctm.yaml"), []byte("max_inline_bytes: 50\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outputDir = t.TempDir()
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--base-url", "https://registry.example.com"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+	readJSONFile(t, filepath.Join(outputDir, "identity.vctm.json"), &doc)
+	if uri := doc.Display[0].Rendering.Simple.Logo["uri"]; uri != "https://registry.example.com/logo.png" {
+		t.Errorf("logo uri = %.40q, want the URL of the logo above the directory max_inline_bytes", uri)
+	}
+}
+
+func TestBatch_DirectoryRemoteImages(t *testing.T) {
+	logo := []byte("remote logo bytes")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(logo)
+	}))
+	defer server.Close()
+
+	inputDir := t.TempDir()
+	content := "# Identity Credential\n\n![Logo](" + server.URL + "/logo.png)\n\n## Claims\n\n- `given_name` (string): Given name\n"
+	writeTestMarkdown(t, inputDir, "plain.md", content)
+	writeTestMarkdown(t, inputDir, "remote/resolved.md", content)
+	writeTestMarkdown(t, inputDir, "remote/small/limited.md", content)
+	writeTestMarkdown(t, inputDir, "remote/.mtcvctm.yaml", "resolve_remote_images: true\n")
+	writeTestMarkdown(t, inputDir, "remote/small/.mtcvctm.yaml", "remote_image_max_bytes: 4\n")
+
+	outputDir := t.TempDir()
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--no-registry"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	sum := sha256.Sum256(logo)
+	wantIntegrity := "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+	for name, want := range map[string]string{
+		"plain":                "",
+		"remote/resolved":      wantIntegrity,
+		"remote/small/limited": "",
+	} {
+		var doc struct {
+			Display []struct {
+				Rendering struct {
+					Simple struct {
+						Logo map[string]string `json:"logo"`
+					} `json:"simple"`
+				} `json:"rendering"`
+			} `json:"display"`
+		}
+		readJSONFile(t, filepath.Join(outputDir, name+".vctm.json"), &doc)
+		logoEntry := doc.Display[0].Rendering.Simple.Logo
+		if logoEntry["uri"] != server.URL+"/logo.png" || logoEntry["uri#integrity"] != want {
+			t.Errorf("%s logo = %v, want uri#integrity %q", name, logoEntry, want)
+		}
+	}
+}
+
+func TestBatch_HashImageNames(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "---\nlogo: images/logo.png\n---\n# Identity Credential\n\n![Logo](images/logo.png)\n\n## Claims\n\n- `given_name` (string): Given name\n")
+	logo := []byte("not really a png")
+	if err := os.MkdirAll(filepath.Join(inputDir, "images"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(inputDir, "images", "logo.png"), logo, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--base-url", "https://registry.example.com",
+		"--no-inline-images", "--hash-image-names", "--format", "vctm,mddl"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	hashed := fmt.Sprintf("images/logo.%08x.png", crc32.ChecksumIEEE(logo))
+	want := "https://registry.example.com/" + hashed
+	var doc struct {
+		Display []struct {
+			Rendering struct {
+				Simple struct {
+					Logo map[string]string `json:"logo"`
+				} `json:"simple"`
+			} `json:"rendering"`
+		} `json:"display"`
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "identity.vctm.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("vctm output is not valid JSON: %v", err)
+	}
+	logoEntry := doc.Display[0].Rendering.Simple.Logo
+	if logoEntry["uri"] != want {
+		t.Errorf("vctm logo uri = %q, want %q", logoEntry["uri"], want)
+	}
+	if integrity, _ := formats.FileIntegrity(filepath.Join(inputDir, "images", "logo.png")); logoEntry["uri#integrity"] != integrity {
+		t.Errorf("vctm logo integrity = %q, want %q", logoEntry["uri#integrity"], integrity)
+	}
+
+	var mdoc struct {
+		Display []struct {
+			Logo struct {
+				URI string `json:"uri"`
+			} `json:"logo"`
+		} `json:"display"`
+	}
+	data, err = os.ReadFile(filepath.Join(outputDir, "identity.mdoc.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &mdoc); err != nil {
+		t.Fatalf("mddl output is not valid JSON: %v", err)
+	}
+	if len(mdoc.Display) == 0 || mdoc.Display[0].Logo.URI != want {
+		t.Errorf("mddl display = %+v, want logo uri %q", mdoc.Display, want)
+	}
+
+	copied, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(hashed)))
+	if err != nil {
+		t.Fatalf("hashed image not written: %v", err)
+	}
+	if !bytes.Equal(copied, logo) {
+		t.Error("hashed image content differs from the source")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "images", "logo.png")); !os.IsNotExist(err) {
+		t.Errorf("unhashed image should not be written, stat error = %v", err)
+	}
+}
+
+func TestBatch_GeneratedAt(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity Credential\n\n## Claims\n\n- `given_name` (string): Given name\n")
+
+	outputDir := t.TempDir()
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--generated-at", "2024-05-01T12:00:00+02:00"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, ".well-known", "vctm-registry.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var registry struct {
+		Generated string `json:"generated"`
+	}
+	if err := json.Unmarshal(data, &registry); err != nil {
+		t.Fatalf("registry is not valid JSON: %v", err)
+	}
+	if registry.Generated != "2024-05-01T10:00:00Z" {
+		t.Errorf("generated = %q, want the --generated-at time in UTC", registry.Generated)
+	}
+
+	err = runBatchWithArgs(t, "--input", inputDir, "--output", t.TempDir(), "--generated-at", "2024-05-01")
+	if err == nil || !strings.Contains(err.Error(), "--generated-at") {
+		t.Errorf("runBatch() with a date only error = %v", err)
+	}
+}
+
+func TestBatch_VCTMIntegrity(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity Credential\n\n## Claims\n\n- `given_name` (string): Given name\n")
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--vctm-integrity"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	var registry struct {
+		Credentials []struct {
+			VCTMFile      string `json:"vctm_file"`
+			VCTMIntegrity string `json:"vctm_integrity"`
+		} `json:"credentials"`
+	}
+	readJSONFile(t, filepath.Join(outputDir, ".well-known", "vctm-registry.json"), &registry)
+	if len(registry.Credentials) != 1 {
+		t.Fatalf("registry has %d credentials, want 1", len(registry.Credentials))
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "identity.vctm.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	want := "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+	if got := registry.Credentials[0].VCTMIntegrity; got != want {
+		t.Errorf("vctm_integrity = %q, want %q", got, want)
+	}
+}
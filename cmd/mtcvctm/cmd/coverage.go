@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
+	"github.com/sirosfoundation/mtcvctm/pkg/parser"
+)
+
+// Coverage status values for a credential/format pair
+const (
+	coverageOK    = "ok"
+	coverageSkip  = "skip"
+	coverageError = "error"
+)
+
+// coverageReport is a credential × format matrix recording which outputs a batch run produced
+type coverageReport struct {
+	// Formats lists the requested formats in column order
+	Formats []string `json:"formats"`
+
+	// Credentials contains one row per processed credential
+	Credentials []coverageRow `json:"credentials"`
+}
+
+// coverageRow contains the per-format outcomes for a single credential
+type coverageRow struct {
+	// Credential is the source file relative to the input directory
+	Credential string `json:"credential"`
+
+	// Formats maps format name to its outcome
+	Formats map[string]coverageCell `json:"formats"`
+}
+
+// coverageCell is the outcome of generating one format for one credential
+type coverageCell struct {
+	// Status is one of ok, skip or error
+	Status string `json:"status"`
+
+	// Error is the generation error message when Status is error
+	Error string `json:"error,omitempty"`
+}
+
+// newCoverageReport creates an empty coverage report for the given formats
+func newCoverageReport(formatNames []string) *coverageReport {
+	return &coverageReport{
+		Formats:     formatNames,
+		Credentials: make([]coverageRow, 0),
+	}
+}
+
+// Record adds a row for a credential from the per-format generation results
+func (r *coverageReport) Record(credential string, results map[string]parser.FormatResult) {
+	row := coverageRow{
+		Credential: credential,
+		Formats:    make(map[string]coverageCell, len(r.Formats)),
+	}
+
+	for _, formatName := range r.Formats {
+		result, ok := results[formatName]
+		switch {
+		case !ok || result.Skipped:
+			row.Formats[formatName] = coverageCell{Status: coverageSkip}
+		case result.Err != nil:
+			row.Formats[formatName] = coverageCell{Status: coverageError, Error: result.Err.Error()}
+		default:
+			row.Formats[formatName] = coverageCell{Status: coverageOK}
+		}
+	}
+
+	r.Credentials = append(r.Credentials, row)
+}
+
+// Failures returns the number of credential/format pairs that failed
+func (r *coverageReport) Failures() int {
+	failures := 0
+	for _, row := range r.Credentials {
+		for _, cell := range row.Formats {
+			if cell.Status == coverageError {
+				failures++
+			}
+		}
+	}
+	return failures
+}
+
+// Print writes the coverage matrix as an aligned table
+func (r *coverageReport) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "CREDENTIAL\t%s\n", strings.ToUpper(strings.Join(r.Formats, "\t")))
+	for _, row := range r.Credentials {
+		statuses := make([]string, 0, len(r.Formats))
+		for _, formatName := range r.Formats {
+			statuses = append(statuses, row.Formats[formatName].Status)
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", row.Credential, strings.Join(statuses, "\t"))
+	}
+	tw.Flush()
+}
+
+// WriteJSON writes the coverage matrix as JSON to the given path
+func (r *coverageReport) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize coverage report: %w", err)
+	}
+	return atomicfile.WriteFile(path, data, 0644)
+}
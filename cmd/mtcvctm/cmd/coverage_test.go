@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/parser"
+)
+
+func TestCoverageReport_Record(t *testing.T) {
+	report := newCoverageReport([]string{"vctm", "mddl", "w3c"})
+	report.Record("identity.md", map[string]parser.FormatResult{
+		"vctm": {Output: []byte("{}")},
+		"mddl": {Err: errors.New("mddl: doctype is required")},
+		"w3c":  {Skipped: true},
+	})
+
+	row := report.Credentials[0]
+	if row.Formats["vctm"].Status != coverageOK {
+		t.Errorf("vctm status = %q, want ok", row.Formats["vctm"].Status)
+	}
+	if row.Formats["mddl"].Status != coverageError {
+		t.Errorf("mddl status = %q, want error", row.Formats["mddl"].Status)
+	}
+	if row.Formats["mddl"].Error != "mddl: doctype is required" {
+		t.Errorf("mddl error = %q", row.Formats["mddl"].Error)
+	}
+	if row.Formats["w3c"].Status != coverageSkip {
+		t.Errorf("w3c status = %q, want skip", row.Formats["w3c"].Status)
+	}
+	if report.Failures() != 1 {
+		t.Errorf("Failures() = %d, want 1", report.Failures())
+	}
+
+	var buf bytes.Buffer
+	report.Print(&buf)
+	if !strings.Contains(buf.String(), "identity.md") || !strings.Contains(buf.String(), "error") {
+		t.Errorf("Print() output missing row:\n%s", buf.String())
+	}
+}
+
+func TestBatch_CoverageReportsFormatFailure(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	reportPath := filepath.Join(t.TempDir(), "coverage.json")
+
+	// Only identity declares a doctype; without a base URL mddl fails for diploma
+	writeTestMarkdown(t, inputDir, "identity.md", "---\ndoctype: org.example.identity\n---\n\n# Identity Credential\n\nAn identity credential.\n")
+	writeTestMarkdown(t, inputDir, "diploma.md", "# Diploma\n\nA diploma credential.\n")
+
+	err := runBatchWithArgs(t,
+		"--input", inputDir,
+		"--output", outputDir,
+		"--format", "vctm,mddl",
+		"--coverage-json", reportPath,
+	)
+	if err == nil {
+		t.Fatal("runBatch() should report the failed format")
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("coverage report not written: %v", err)
+	}
+	var report coverageReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("coverage report is not valid JSON: %v", err)
+	}
+
+	want := map[string]map[string]string{
+		"identity.md": {"vctm": coverageOK, "mddl": coverageOK},
+		"diploma.md":  {"vctm": coverageOK, "mddl": coverageError},
+	}
+	if len(report.Credentials) != len(want) {
+		t.Fatalf("len(Credentials) = %d, want %d", len(report.Credentials), len(want))
+	}
+	for _, row := range report.Credentials {
+		for formatName, status := range want[row.Credential] {
+			if row.Formats[formatName].Status != status {
+				t.Errorf("%s/%s status = %q, want %q", row.Credential, formatName, row.Formats[formatName].Status, status)
+			}
+		}
+	}
+
+	// The successful outputs are still written
+	if _, err := os.Stat(filepath.Join(outputDir, "diploma.vctm.json")); err != nil {
+		t.Errorf("diploma vctm output should be written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "diploma.mdoc.json")); !os.IsNotExist(err) {
+		t.Error("diploma mddl output should not be written")
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/internal/jsondiff"
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+	"github.com/sirosfoundation/mtcvctm/pkg/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffOutputDir      string
+	diffBaseURL        string
+	diffAssetBaseURL   string
+	diffConfigFile     string
+	diffFormatFlag     string
+	diffNoInlineImages bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <input.md>",
+	Short: "Check generated output files against their markdown source",
+	Long: `Regenerate the outputs of a markdown file in memory and compare them with
+the existing output files without writing anything.
+
+The comparison is semantic: both sides are parsed as JSON, so differences in
+key order or formatting are ignored. Each drifted file is printed as a
+unified diff from the file on disk to the regenerated output, and the command
+exits non-zero if any output differs or is missing. Use the same options as
+when generating the files.
+
+Example:
+  mtcvctm diff identity.md
+  mtcvctm diff identity.md --format all --output-dir ./dist --base-url https://registry.example.com`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringVar(&diffOutputDir, "output-dir", "", "Directory of the output files (default: input directory)")
+	diffCmd.Flags().StringVar(&diffBaseURL, "base-url", "", "Base URL for generating image URLs with integrity")
+	diffCmd.Flags().StringVar(&diffAssetBaseURL, "asset-base-url", "", "Base URL for image and template URIs (default: base URL)")
+	diffCmd.Flags().StringVarP(&diffConfigFile, "config", "c", "", "Configuration file path")
+	diffCmd.Flags().StringVarP(&diffFormatFlag, "format", "f", "vctm", "Output format(s) to check: vctm, mddl, w3c, anoncreds, jsonschema, all (comma-separated)")
+	diffCmd.Flags().BoolVar(&diffNoInlineImages, "no-inline-images", false, "Use URLs instead of embedding images as data URLs")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	return diffFile(os.Stdout, args[0])
+}
+
+// diffFile regenerates the outputs of inputFile and reports those that differ
+// from the files on disk
+func diffFile(w io.Writer, inputFile string) error {
+	cfg := config.DefaultConfig()
+	if diffConfigFile != "" {
+		fileCfg, err := config.LoadFromFile(diffConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to load config file: %w", err)
+		}
+		cfg.Merge(fileCfg)
+	}
+	cfg.Merge(&config.Config{
+		InputFile:       inputFile,
+		OutputDir:       diffOutputDir,
+		BaseURL:         diffBaseURL,
+		AssetBaseURL:    diffAssetBaseURL,
+		InlineImages:    !diffNoInlineImages,
+		InlineImagesSet: diffNoInlineImages,
+		Formats:         diffFormatFlag,
+	})
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	formatNames, err := formats.ParseFormats(cfg.Formats)
+	if err != nil {
+		return err
+	}
+
+	p := parser.NewParser(cfg)
+	cred, err := p.ParseToCredential(cfg.InputFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse markdown: %w", err)
+	}
+	outputs, err := p.Generate(cred, formatNames)
+	if err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	base := filepath.Base(cfg.InputFile)
+	baseName := strings.TrimSuffix(base, filepath.Ext(base))
+	outDir := cfg.OutputDir
+	if outDir == "" {
+		outDir = filepath.Dir(cfg.InputFile)
+	}
+
+	drifted := 0
+	for _, formatName := range formatNames {
+		data, ok := outputs[formatName]
+		if !ok {
+			continue
+		}
+		outputPath := filepath.Join(outDir, parser.OutputPath(baseName, formatName, cfg))
+		data, err := postProcessOutput(cfg.PostProcess, formatName, outputPath, data)
+		if err != nil {
+			return err
+		}
+		changed, err := checkOutput(w, outputPath, data)
+		if err != nil {
+			return err
+		}
+		if changed {
+			drifted++
+		}
+	}
+
+	if drifted > 0 {
+		return fmt.Errorf("%d output file(s) of %s are out of date", drifted, inputFile)
+	}
+	fmt.Fprintf(w, "%s: outputs are up to date\n", inputFile)
+	return nil
+}
+
+// checkOutput compares generated data with the file at outputPath, printing a
+// unified diff to w, and reports whether the file is missing or differs. Outputs
+// that are not JSON, such as CBOR, are compared byte for byte.
+func checkOutput(w io.Writer, outputPath string, data []byte) (bool, error) {
+	existing, err := os.ReadFile(outputPath)
+	if os.IsNotExist(err) {
+		fmt.Fprintf(w, "%s: missing\n", outputPath)
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", outputPath, err)
+	}
+
+	if !json.Valid(data) {
+		if bytes.Equal(existing, data) {
+			return false, nil
+		}
+		fmt.Fprintf(w, "%s: differs\n", outputPath)
+		return true, nil
+	}
+
+	diff, err := jsondiff.Diff(outputPath, outputPath+" (generated)", existing, data)
+	if err != nil {
+		return false, err
+	}
+	if diff == "" {
+		return false, nil
+	}
+	fmt.Fprint(w, diff)
+	return true, nil
+}
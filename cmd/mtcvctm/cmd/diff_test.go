@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffFile_DetectsDrift(t *testing.T) {
+	dir := t.TempDir()
+	mdFile := writeTestMarkdown(t, dir, "identity.md", "---\nvct: urn:test:identity\n---\n\n# Identity\n\n## Claims\n\n- `given_name` (string): Given name [mandatory]\n")
+
+	resetFlags(t, generateCmd.Flags(), "--format", "vctm")
+	if err := generateFile(mdFile); err != nil {
+		t.Fatalf("generateFile() error = %v", err)
+	}
+	outputPath := filepath.Join(dir, "identity.vctm.json")
+
+	resetFlags(t, diffCmd.Flags())
+	var out bytes.Buffer
+	if err := diffFile(&out, mdFile); err != nil {
+		t.Fatalf("diffFile() error = %v\n%s", err, out.String())
+	}
+
+	// Key order and formatting do not count as drift
+	var doc map[string]interface{}
+	readJSONFile(t, outputPath, &doc)
+	compact, _ := json.Marshal(doc)
+	if err := os.WriteFile(outputPath, compact, 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := diffFile(&out, mdFile); err != nil {
+		t.Fatalf("diffFile() error = %v for reformatted output\n%s", err, out.String())
+	}
+
+	// An edited output is reported with a diff
+	doc["name"] = "Edited"
+	edited, _ := json.Marshal(doc)
+	if err := os.WriteFile(outputPath, edited, 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := diffFile(&out, mdFile); err == nil {
+		t.Fatal("diffFile() should fail for an edited output")
+	}
+	if !strings.Contains(out.String(), `-  "name": "Edited",`) || !strings.Contains(out.String(), `+  "name": "Identity",`) {
+		t.Errorf("diff output missing the change:\n%s", out.String())
+	}
+}
+
+func TestBatch_Check(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n## Claims\n\n- `given_name` (string): Given name\n")
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--check"); err != nil {
+		t.Fatalf("runBatch() --check error = %v", err)
+	}
+
+	outputPath := filepath.Join(outputDir, "identity.vctm.json")
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(data), `"Identity"`, `"Stale"`, 1)
+	if err := os.WriteFile(outputPath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--check"); err == nil {
+		t.Error("runBatch() --check should fail for an edited output")
+	}
+	after, _ := os.ReadFile(outputPath)
+	if string(after) != edited {
+		t.Error("runBatch() --check should not rewrite outputs")
+	}
+}
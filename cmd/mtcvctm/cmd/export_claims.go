@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+	"github.com/sirosfoundation/mtcvctm/pkg/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportClaimsOut      string
+	exportClaimsLanguage string
+)
+
+var exportClaimsCmd = &cobra.Command{
+	Use:   "export-claims <file|dir>",
+	Short: "Export the claims of markdown credentials as CSV",
+	Long: `Export the claims of markdown credential definitions as a flat CSV for
+spreadsheets and governance reviews.
+
+Each row is one claim with the columns credential (the markdown file relative
+to the input), claim (the claim path, e.g. address.street), type, mandatory, sd
+and the label and description in the default locale.
+
+A directory is searched recursively for markdown files like batch. Without
+--out the CSV is written to stdout.
+
+Example:
+  mtcvctm export-claims ./credentials --out claims.csv
+  mtcvctm export-claims identity.md`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExportClaims,
+}
+
+func init() {
+	rootCmd.AddCommand(exportClaimsCmd)
+
+	exportClaimsCmd.Flags().StringVarP(&exportClaimsOut, "out", "o", "", "CSV file to write (default: stdout)")
+	exportClaimsCmd.Flags().StringVar(&exportClaimsLanguage, "language", "en-US", "Default language for display properties")
+}
+
+func runExportClaims(cmd *cobra.Command, args []string) error {
+	var buf bytes.Buffer
+	rows, err := exportClaims(&buf, args[0], exportClaimsLanguage)
+	if err != nil {
+		return err
+	}
+
+	if exportClaimsOut == "" || exportClaimsOut == config.StdioPath {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := atomicfile.WriteFile(exportClaimsOut, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", exportClaimsOut, err)
+	}
+	fmt.Printf("Exported %d claim(s): %s\n", rows, exportClaimsOut)
+	return nil
+}
+
+// exportClaimsHeader is the header row of the claims CSV
+var exportClaimsHeader = []string{"credential", "claim", "type", "mandatory", "sd", "label", "description"}
+
+// exportClaims writes the claims of a markdown file, or of all markdown files
+// below a directory, as CSV and returns the number of claim rows
+func exportClaims(w io.Writer, target, language string) (int, error) {
+	info, err := os.Stat(target)
+	if err != nil {
+		return 0, fmt.Errorf("failed to access %s: %w", target, err)
+	}
+
+	files := []string{target}
+	root := filepath.Dir(target)
+	if info.IsDir() {
+		root = target
+		files, err = findMarkdownFiles(target)
+		if err != nil {
+			return 0, fmt.Errorf("failed to find markdown files: %w", err)
+		}
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write(exportClaimsHeader); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	for _, mdFile := range files {
+		cfg, err := directoryConfig(mdFile, root, nil)
+		if err != nil {
+			return rows, err
+		}
+		cfg.InputFile = mdFile
+		cfg.Language = language
+
+		cred, err := parser.NewParser(cfg).ParseToCredential(mdFile)
+		if err != nil {
+			return rows, fmt.Errorf("failed to parse %s: %w", mdFile, err)
+		}
+
+		relPath, err := filepath.Rel(root, mdFile)
+		if err != nil {
+			relPath = mdFile
+		}
+		locale := formats.DefaultLocale(cred, cfg)
+		for _, claim := range cred.Claims {
+			label, description := claim.DisplayName, claim.Description
+			if loc, ok := claim.Localizations[locale]; ok {
+				if label == "" {
+					label = loc.Label
+				}
+				if description == "" {
+					description = loc.Description
+				}
+			}
+			record := []string{
+				filepath.ToSlash(relPath),
+				claim.Name,
+				claim.Type,
+				strconv.FormatBool(claim.Mandatory),
+				claim.SD,
+				label,
+				description,
+			}
+			if err := out.Write(record); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+	}
+
+	out.Flush()
+	return rows, out.Error()
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+func TestExportClaims(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n## Claims\n\n"+
+		"- `given_name` \"Given Name\" (string): Given name [mandatory]\n"+
+		"- `birth_date` (date): Date of birth [sd=never]\n")
+	writeTestMarkdown(t, inputDir, "diploma.md", "# Diploma\n\n## Claims\n\n"+
+		"- `degree` (string): Degree\n"+
+		"  - en-US: \"Degree\" - Awarded degree\n")
+
+	var out bytes.Buffer
+	rows, err := exportClaims(&out, inputDir, "en-US")
+	if err != nil {
+		t.Fatalf("exportClaims() error = %v", err)
+	}
+	if rows != 3 {
+		t.Errorf("exportClaims() rows = %d, want 3", rows)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	want := [][]string{
+		exportClaimsHeader,
+		{"diploma.md", "degree", "string", "false", "", "Degree", "Degree"},
+		{"identity.md", "given_name", "string", "true", "", "Given Name", "Given name"},
+		{"identity.md", "birth_date", "date", "false", "never", "", "Date of birth"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("CSV records =\n%v\nwant\n%v", records, want)
+	}
+}
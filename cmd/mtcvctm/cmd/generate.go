@@ -2,12 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
 	"github.com/sirosfoundation/mtcvctm/pkg/config"
 	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/anoncreds"
+	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/jsonschema"
 	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/mddl"
 	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/vctmfmt"
 	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/w3c"
@@ -21,9 +26,37 @@ var (
 	baseURL        string
 	vct            string
 	language       string
+	localeFallback string
 	configFile     string
 	noInlineImages bool
 	formatFlag     string
+	indentFlag     string
+	canonicalFlag  bool
+	strictFlag     bool
+	assetBaseURL   string
+	emitValueType  bool
+	mddlIncludeSD  bool
+	vctmDraft      int
+	emitIRFile     string
+	watchFlag      bool
+	resolveExtends bool
+	inheritClaims  bool
+	layoutFlag     string
+	mddlEncoding   string
+
+	resolveRemoteImages bool
+	remoteImageMaxBytes int64
+	remoteImageTimeout  time.Duration
+	maxInlineBytes      int64
+	noSVGMinify         bool
+	dryRunFlag          bool
+	emitExample         bool
+)
+
+// generateStdin and generateStdout are used for the "-" input and output paths
+var (
+	generateStdin  io.Reader = os.Stdin
+	generateStdout io.Writer = os.Stdout
 )
 
 var generateCmd = &cobra.Command{
@@ -36,6 +69,7 @@ Supports multiple output formats:
   - vctm: SD-JWT VC Type Metadata (default)
   - mddl: mso_mdoc credential configuration (ISO 18013-5)
   - w3c:  W3C Verifiable Credential schema
+  - anoncreds: AnonCreds schema and credential definition stub
   - all:  Generate all formats
 
 The markdown file should contain:
@@ -45,13 +79,18 @@ The markdown file should contain:
 - Optional images which become logos/templates
 
 Claim format in markdown lists:
-  - ` + "`claim_name`" + ` (type): Description [mandatory] [sd=always|never]
+  - ` + "`claim_name`" + ` (type): Description [mandatory] [sd=always|allowed|never]
+
+An input of - reads the markdown from stdin, resolving images relative to the
+current directory, and -o - writes the output of a single format to stdout.
 
 Example:
   mtcvctm generate identity.md
   mtcvctm gen identity.md -o identity.vctm --base-url https://registry.example.com
   mtcvctm gen identity.md --format all --output-dir ./dist
-  mtcvctm gen identity.md --format vctm,mddl --base-url https://registry.example.com`,
+  mtcvctm gen identity.md --format vctm,mddl --base-url https://registry.example.com
+  mtcvctm gen identity.md --watch
+  cat identity.md | mtcvctm gen - --format vctm -o - > identity.vctm.json`,
 	Args: cobra.ExactArgs(1),
 	RunE: runGenerate,
 }
@@ -62,15 +101,54 @@ func init() {
 	generateCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file path (default: <input>.<format>)")
 	generateCmd.Flags().StringVar(&outputDir, "output-dir", "", "Output directory for multi-format output")
 	generateCmd.Flags().StringVar(&baseURL, "base-url", "", "Base URL for generating image URLs with integrity")
+	generateCmd.Flags().StringVar(&assetBaseURL, "asset-base-url", "", "Base URL for image and template URIs (default: base URL)")
 	generateCmd.Flags().StringVar(&vct, "vct", "", "Verifiable Credential Type identifier")
 	generateCmd.Flags().StringVar(&language, "language", "en-US", "Default language for display properties")
+	generateCmd.Flags().StringVar(&localeFallback, "locale-fallback-order", "", "Comma-separated locales; the first one the credential provides becomes the default display")
 	generateCmd.Flags().StringVarP(&configFile, "config", "c", "", "Configuration file path")
 	generateCmd.Flags().BoolVar(&noInlineImages, "no-inline-images", false, "Use URLs instead of embedding images as data URLs")
-	generateCmd.Flags().StringVarP(&formatFlag, "format", "f", "vctm", "Output format(s): vctm, mddl, w3c, all (comma-separated)")
+	generateCmd.Flags().StringVarP(&formatFlag, "format", "f", "vctm", "Output format(s): vctm, mddl, w3c, anoncreds, jsonschema, all (comma-separated)")
+	generateCmd.Flags().BoolVar(&emitValueType, "emit-value-type", false, "Add an x-value_type hint to vctm claim entries")
+	generateCmd.Flags().BoolVar(&mddlIncludeSD, "mddl-include-sd", false, "Add a non-standard sd hint with the claim's selective disclosure setting to mddl claims")
+	generateCmd.Flags().IntVar(&vctmDraft, "vctm-draft", 0, "SD-JWT VC draft of the vctm claims shape: 11 (object keyed by name) or 12 (path array) (default 12)")
+	generateCmd.Flags().BoolVar(&strictFlag, "strict", false, "Exit non-zero on warnings such as duplicate claims or multiple extends parents")
+	generateCmd.Flags().StringVar(&indentFlag, "indent", "", "JSON indentation: number of spaces or \"tab\" (default: 2 spaces)")
+	generateCmd.Flags().BoolVar(&canonicalFlag, "canonical", false, "Write compact canonical JSON (RFC 8785 style, sorted keys) for byte-stable output")
+	generateCmd.Flags().StringVar(&emitIRFile, "emit-ir", "", "Write the parsed intermediate representation as JSON to this file (debugging aid)")
+	generateCmd.Flags().BoolVar(&resolveExtends, "resolve-extends", false, "Fetch the extends parent to compute a missing extends#integrity")
+	generateCmd.Flags().Int64Var(&maxInlineBytes, "max-inline-bytes", 0, "Largest image to inline; larger ones are referenced by URL when a base URL is set (default: max_inline_bytes from config, else 64 KiB; negative: no limit)")
+	generateCmd.Flags().BoolVar(&emitExample, "emit-jsonld-example", false, "Also write a sample W3C credential populated with claim example values to <input>.example.json")
+	generateCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Generate everything but only print the paths and sizes of the files that would be written")
+	generateCmd.Flags().BoolVar(&noSVGMinify, "no-svg-minify", false, "Inline SVG images without stripping comments, whitespace and editor metadata")
+	generateCmd.Flags().BoolVar(&resolveRemoteImages, "resolve-remote-images", false, "Download images referenced by http(s) URL to compute their uri#integrity")
+	generateCmd.Flags().Int64Var(&remoteImageMaxBytes, "remote-image-max-bytes", 0, "Size limit of downloaded remote images (default: remote_image_max_bytes from config, else 5 MiB)")
+	generateCmd.Flags().DurationVar(&remoteImageTimeout, "remote-image-timeout", 0, "Timeout of each remote image download (default: remote_image_timeout from config, else 30s)")
+	generateCmd.Flags().BoolVar(&inheritClaims, "inherit-claims", false, "Merge the claims of the extends parent (local markdown or VCTM, or a VCTM URL)")
+	generateCmd.Flags().StringVar(&layoutFlag, "layout", "", "Output layout: flat (default) or by-format (one subdirectory per format)")
+	generateCmd.Flags().StringVar(&mddlEncoding, "mddl-encoding", "", "Encoding of mddl output: json (default, .mdoc.json) or cbor (.mdoc.cbor)")
+	generateCmd.Flags().BoolVar(&watchFlag, "watch", false, "Keep running and regenerate when the markdown or its images change")
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
 	inputFile := args[0]
+	if watchFlag && inputFile == config.StdioPath {
+		return fmt.Errorf("--watch cannot be used with stdin input")
+	}
+	if watchFlag && dryRunFlag {
+		return fmt.Errorf("--watch cannot be combined with --dry-run")
+	}
+	if watchFlag {
+		return runWatch(os.Stdout, func() []string {
+			return credentialWatchTargets(inputFile)
+		}, func() error {
+			return generateFile(inputFile)
+		})
+	}
+	return generateFile(inputFile)
+}
+
+// generateFile generates the requested formats for a single markdown file
+func generateFile(inputFile string) error {
 
 	// Build configuration from defaults, config file, and flags
 	cfg := config.DefaultConfig()
@@ -86,14 +164,32 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 
 	// Apply command line flags (they take priority)
 	flagCfg := &config.Config{
-		InputFile:    inputFile,
-		OutputFile:   outputFile,
-		OutputDir:    outputDir,
-		BaseURL:      baseURL,
-		VCT:          vct,
-		Language:     language,
-		InlineImages: !noInlineImages,
-		Formats:      formatFlag,
+		InputFile:           inputFile,
+		OutputFile:          outputFile,
+		OutputDir:           outputDir,
+		BaseURL:             baseURL,
+		AssetBaseURL:        assetBaseURL,
+		VCT:                 vct,
+		Language:            language,
+		LocaleFallbackOrder: config.ParseLocaleList(localeFallback),
+		InlineImages:        !noInlineImages,
+		InlineImagesSet:     noInlineImages, // only --no-inline-images overrides the config
+		Formats:             formatFlag,
+		Indent:              indentFlag,
+		Canonical:           canonicalFlag,
+		Strict:              strictFlag,
+		EmitValueType:       emitValueType,
+		MDDLIncludeSD:       mddlIncludeSD,
+		VCTMDraft:           vctmDraft,
+		ResolveExtends:      resolveExtends,
+		MaxInlineBytes:      maxInlineBytes,
+		NoSVGMinify:         noSVGMinify,
+		ResolveRemoteImages: resolveRemoteImages,
+		RemoteImageMaxBytes: remoteImageMaxBytes,
+		RemoteImageTimeout:  remoteImageTimeout,
+		InheritClaims:       inheritClaims,
+		Layout:              layoutFlag,
+		MDDLEncoding:        mddlEncoding,
 	}
 	cfg.Merge(flagCfg)
 
@@ -108,12 +204,71 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Only one document can be written to stdout, and status messages go to
+	// stderr so they do not mix with it
+	toStdout := cfg.OutputFile == config.StdioPath
+	if toStdout && len(formatNames) != 1 {
+		return fmt.Errorf("-o - writes a single document to stdout, but %d formats are selected", len(formatNames))
+	}
+	if toStdout && dryRunFlag {
+		return fmt.Errorf("--dry-run cannot be combined with -o -")
+	}
+	if emitExample && toStdout {
+		return fmt.Errorf("--emit-jsonld-example cannot be combined with -o -")
+	}
+	if emitExample && !contains(formatNames, "w3c") {
+		return fmt.Errorf("--emit-jsonld-example requires the w3c format")
+	}
+	status := io.Writer(os.Stdout)
+	if toStdout {
+		status = os.Stderr
+	}
+
+	// Markdown from stdin gets a synthetic path in the current directory, which
+	// images are resolved against
+	var content []byte
+	if cfg.InputFile == config.StdioPath {
+		content, err = io.ReadAll(generateStdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to determine working directory: %w", err)
+		}
+		cfg.InputFile = filepath.Join(cwd, "stdin.md")
+	}
+
 	// Parse markdown
 	p := parser.NewParser(cfg)
-	cred, err := p.ParseToCredential(cfg.InputFile)
+	var cred *formats.ParsedCredential
+	if content != nil {
+		cred, err = p.ParseContentToCredential(content, cfg.InputFile)
+	} else {
+		cred, err = p.ParseToCredential(cfg.InputFile)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to parse markdown: %w", err)
 	}
+	for _, warning := range cred.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if cfg.Strict && len(cred.Warnings) > 0 {
+		return fmt.Errorf("%d warning(s) in %s (--strict)", len(cred.Warnings), cfg.InputFile)
+	}
+
+	if emitIRFile != "" && dryRunFlag {
+		data, err := formats.FormatJSON(cred, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal IR: %w", err)
+		}
+		fmt.Fprintf(status, "Would write IR: %s (%d bytes)\n", emitIRFile, len(data))
+	} else if emitIRFile != "" {
+		if err := writeIR(emitIRFile, cred, cfg); err != nil {
+			return err
+		}
+		fmt.Fprintf(status, "Generated IR: %s\n", emitIRFile)
+	}
 
 	// Generate outputs
 	outputs, err := p.Generate(cred, formatNames)
@@ -141,19 +296,184 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 			outputPath = cfg.OutputFile
 		} else {
 			// Use format-specific extension
-			outputPath = filepath.Join(outDir, parser.OutputFileName(baseName, formatName))
+			outputPath = filepath.Join(outDir, parser.OutputPath(baseName, formatName, cfg))
+		}
+
+		data, err := postProcessOutput(cfg.PostProcess, formatName, outputPath, data)
+		if err != nil {
+			return err
+		}
+
+		if toStdout {
+			if _, err := generateStdout.Write(data); err != nil {
+				return fmt.Errorf("failed to write %s output: %w", formatName, err)
+			}
+			continue
+		}
+
+		if dryRunFlag {
+			fmt.Printf("Would write %s: %s (%d bytes)\n", formatName, outputPath, len(data))
+			continue
 		}
 
 		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 			return fmt.Errorf("failed to create output directory: %w", err)
 		}
 
-		if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		if err := atomicfile.WriteFile(outputPath, data, 0644); err != nil {
 			return fmt.Errorf("failed to write %s output: %w", formatName, err)
 		}
 
 		fmt.Printf("Generated %s: %s\n", formatName, outputPath)
 	}
 
+	// A standalone schema has nowhere to go next to stdout
+	if toStdout {
+		return nil
+	}
+
+	schemaDir := outDir
+	if len(formatNames) == 1 && cfg.OutputFile != "" && cfg.OutputDir == "" {
+		schemaDir = filepath.Dir(cfg.OutputFile)
+	}
+	if dryRunFlag {
+		schemas, err := schemaDocuments(schemaDir, baseName, cred, cfg, outputs)
+		if err != nil {
+			return err
+		}
+		for _, schema := range schemas {
+			fmt.Printf("Would write schema: %s (%d bytes)\n", schema.path, len(schema.data))
+		}
+	} else {
+		schemaFiles, err := writeSchemaDocuments(schemaDir, baseName, cred, cfg, outputs)
+		if err != nil {
+			return err
+		}
+		for _, schemaPath := range schemaFiles {
+			fmt.Printf("Generated schema: %s\n", schemaPath)
+		}
+	}
+
+	if !emitExample {
+		return nil
+	}
+	examples, err := exampleDocuments(schemaDir, baseName, cred, cfg, outputs)
+	if err != nil {
+		return err
+	}
+	for _, example := range examples {
+		if dryRunFlag {
+			fmt.Printf("Would write example: %s (%d bytes)\n", example.path, len(example.data))
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(example.path), 0755); err != nil {
+			return fmt.Errorf("failed to create example directory: %w", err)
+		}
+		if err := atomicfile.WriteFile(example.path, example.data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s example: %w", example.format, err)
+		}
+		fmt.Printf("Generated example: %s\n", example.path)
+	}
+
+	return nil
+}
+
+// schemaDocument is the standalone JSON Schema of a format and the path it is written to
+type schemaDocument struct {
+	format string
+	path   string
+	data   []byte
+}
+
+// schemaDocuments generates the standalone JSON Schema of every generated format
+// that provides one, to be written to <dir>/<baseName>.schema.json
+func schemaDocuments(dir, baseName string, cred *formats.ParsedCredential, cfg *config.Config, outputs map[string][]byte) ([]schemaDocument, error) {
+	var docs []schemaDocument
+	for formatName := range outputs {
+		gen, ok := formats.Get(formatName)
+		if !ok {
+			continue
+		}
+		provider, ok := gen.(formats.SchemaProvider)
+		if !ok {
+			continue
+		}
+		data, err := provider.Schema(cred, cfg)
+		if err != nil {
+			return docs, fmt.Errorf("failed to generate %s schema: %w", formatName, err)
+		}
+		if data == nil {
+			continue
+		}
+		docs = append(docs, schemaDocument{format: formatName, path: filepath.Join(dir, baseName+".schema.json"), data: data})
+	}
+	return docs, nil
+}
+
+// writeSchemaDocuments writes the standalone JSON Schema of every generated format
+// that provides one to <dir>/<baseName>.schema.json and returns the written paths
+func writeSchemaDocuments(dir, baseName string, cred *formats.ParsedCredential, cfg *config.Config, outputs map[string][]byte) ([]string, error) {
+	docs, err := schemaDocuments(dir, baseName, cred, cfg, outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, doc := range docs {
+		if err := os.MkdirAll(filepath.Dir(doc.path), 0755); err != nil {
+			return written, fmt.Errorf("failed to create schema directory: %w", err)
+		}
+		if err := atomicfile.WriteFile(doc.path, doc.data, 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s schema: %w", doc.format, err)
+		}
+		written = append(written, doc.path)
+	}
+	return written, nil
+}
+
+// exampleDocument is the sample instance of a format and the path it is written to
+type exampleDocument struct {
+	format string
+	path   string
+	data   []byte
+}
+
+// exampleDocuments generates the sample instance of every generated format that
+// provides one, to be written to <dir>/<baseName>.example.json
+func exampleDocuments(dir, baseName string, cred *formats.ParsedCredential, cfg *config.Config, outputs map[string][]byte) ([]exampleDocument, error) {
+	var docs []exampleDocument
+	for formatName := range outputs {
+		gen, ok := formats.Get(formatName)
+		if !ok {
+			continue
+		}
+		provider, ok := gen.(formats.ExampleProvider)
+		if !ok {
+			continue
+		}
+		data, err := provider.Example(cred, cfg)
+		if err != nil {
+			return docs, fmt.Errorf("failed to generate %s example: %w", formatName, err)
+		}
+		if data == nil {
+			continue
+		}
+		docs = append(docs, exampleDocument{format: formatName, path: filepath.Join(dir, baseName+".example.json"), data: data})
+	}
+	return docs, nil
+}
+
+// writeIR writes the parsed credential the generators receive as JSON
+func writeIR(path string, cred *formats.ParsedCredential, cfg *config.Config) error {
+	data, err := formats.FormatJSON(cred, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal IR: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create IR directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write IR: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/parser"
+)
+
+func TestWriteIR(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdPath := writeTestMarkdown(t, tmpDir, "identity.md", `---
+vct: https://example.com/identity
+background_color: "#003366"
+---
+# Identity Credential
+
+An identity credential.
+
+## Claims
+
+- `+"`given_name`"+` "Given Name" (string): Given name [mandatory, sd=always]
+  - de-DE: "Vorname" - Der Vorname
+`)
+
+	cfg := config.DefaultConfig()
+	cfg.InputFile = mdPath
+	cred, err := parser.NewParser(cfg).ParseToCredential(mdPath)
+	if err != nil {
+		t.Fatalf("ParseToCredential() error = %v", err)
+	}
+
+	irPath := filepath.Join(tmpDir, "debug", "ir.json")
+	if err := writeIR(irPath, cred, cfg); err != nil {
+		t.Fatalf("writeIR() error = %v", err)
+	}
+
+	data, err := os.ReadFile(irPath)
+	if err != nil {
+		t.Fatalf("IR file not written: %v", err)
+	}
+
+	var ir struct {
+		Name     string
+		VCT      string
+		Metadata map[string]interface{}
+		Claims   []struct {
+			Name          string
+			Mandatory     bool
+			SD            string
+			Localizations map[string]struct{ Label string }
+		}
+	}
+	if err := json.Unmarshal(data, &ir); err != nil {
+		t.Fatalf("IR is not valid JSON: %v", err)
+	}
+
+	if ir.Name != "Identity Credential" || ir.VCT != "https://example.com/identity" {
+		t.Errorf("IR name/vct = %q/%q", ir.Name, ir.VCT)
+	}
+	if ir.Metadata["background_color"] != "#003366" {
+		t.Errorf("IR metadata = %v, want background_color", ir.Metadata)
+	}
+	if len(ir.Claims) != 1 {
+		t.Fatalf("IR claims = %+v, want 1 claim", ir.Claims)
+	}
+	claim := ir.Claims[0]
+	if claim.Name != "given_name" || !claim.Mandatory || claim.SD != "always" {
+		t.Errorf("IR claim = %+v", claim)
+	}
+	if claim.Localizations["de-DE"].Label != "Vorname" {
+		t.Errorf("IR claim localizations = %v", claim.Localizations)
+	}
+}
+
+func TestGenerate_StdinToStdout(t *testing.T) {
+	var out bytes.Buffer
+	generateStdin = strings.NewReader("---\nvct: urn:test:identity\n---\n\n# Identity\n\n## Claims\n\n- `given_name` (string): Given name [mandatory]\n")
+	generateStdout = &out
+	t.Cleanup(func() {
+		generateStdin = os.Stdin
+		generateStdout = os.Stdout
+	})
+
+	resetFlags(t, generateCmd.Flags(), "--format", "vctm", "-o", "-")
+	if err := generateFile("-"); err != nil {
+		t.Fatalf("generateFile() error = %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("stdout is not a single JSON document: %v\n%s", err, out.String())
+	}
+	if doc["vct"] != "urn:test:identity" || doc["name"] != "Identity" {
+		t.Errorf("vct/name = %v/%v", doc["vct"], doc["name"])
+	}
+
+	// Several formats cannot share stdout
+	generateStdin = strings.NewReader("# Identity\n")
+	resetFlags(t, generateCmd.Flags(), "--format", "vctm,w3c", "-o", "-")
+	if err := generateFile("-"); err == nil {
+		t.Error("generateFile() should reject several formats written to stdout")
+	}
+}
+
+func TestGenerate_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestMarkdown(t, dir, "identity.md", "# Identity\n\n## Claims\n\n- `given_name` (string): Given name\n")
+	irPath := filepath.Join(dir, "identity.ir.json")
+
+	resetFlags(t, generateCmd.Flags(), "--format", "vctm,w3c", "--emit-ir", irPath, "--dry-run")
+	var runErr error
+	out := captureStdout(t, func() { runErr = generateFile(input) })
+	if runErr != nil {
+		t.Fatalf("generateFile() error = %v", runErr)
+	}
+
+	for _, want := range []string{
+		"Would write IR: " + irPath + " (",
+		"Would write vctm: " + filepath.Join(dir, "identity.vctm.json") + " (",
+		"Would write w3c: " + filepath.Join(dir, "identity.vc.json") + " (",
+		"Would write schema: " + filepath.Join(dir, "identity.schema.json") + " (",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("--dry-run wrote files: %v", entries)
+	}
+}
+
+func TestGenerate_EmitJSONLDExample(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestMarkdown(t, dir, "identity.md", "# Identity\n\n## Claims\n\n- `given_name` (string): Given name [mandatory, example=Alice]\n")
+
+	resetFlags(t, generateCmd.Flags(), "--format", "w3c", "--emit-jsonld-example")
+	captureStdout(t, func() {
+		if err := generateFile(input); err != nil {
+			t.Fatalf("generateFile() error = %v", err)
+		}
+	})
+
+	var example struct {
+		CredentialSubject map[string]interface{} `json:"credentialSubject"`
+	}
+	readJSONFile(t, filepath.Join(dir, "identity.example.json"), &example)
+	if example.CredentialSubject["given_name"] != "Alice" {
+		t.Errorf("credentialSubject = %v, want given_name Alice", example.CredentialSubject)
+	}
+
+	resetFlags(t, generateCmd.Flags(), "--format", "vctm", "--emit-jsonld-example")
+	if err := generateFile(input); err == nil || !strings.Contains(err.Error(), "requires the w3c format") {
+		t.Errorf("generateFile() without w3c error = %v", err)
+	}
+}
+
+func TestGenerate_MaxInlineBytesFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestMarkdown(t, dir, "identity.md", "# Identity\n\n![Logo](logo.png)\n\n## Claims\n\n- `given_name` (string): Given name\n")
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("base_url: https://registry.example.com\nmax_inline_bytes: 50\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resetFlags(t, generateCmd.Flags(), "--format", "vctm", "-c", configPath)
+	captureStdout(t, func() {
+		if err := generateFile(input); err != nil {
+			t.Fatalf("generateFile() error = %v", err)
+		}
+	})
+
+	var doc struct {
+		Display []struct {
+			Rendering struct {
+				Simple struct {
+					Logo map[string]string `json:"logo"`
+				} `json:"simple"`
+			} `json:"rendering"`
+		} `json:"display"`
+	}
+	readJSONFile(t, filepath.Join(dir, "identity.vctm.json"), &doc)
+	if uri := doc.Display[0].Rendering.Simple.Logo["uri"]; uri != "https://registry.example.com/logo.png" {
+		t.Errorf("logo uri = %.40q, want the URL of the logo above max_inline_bytes", uri)
+	}
+}
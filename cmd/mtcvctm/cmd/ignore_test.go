@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreMatcher_Match(t *testing.T) {
+	m := &ignoreMatcher{}
+	for _, line := range []string{
+		"# comment",
+		"",
+		"drafts/",
+		"/archive/**/*.md",
+		"*.wip.md",
+		"!keep.wip.md",
+	} {
+		m.add(line)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"drafts", true, true},
+		{"nested/drafts", true, true},
+		{"drafts", false, false},
+		{"archive/old.md", false, true},
+		{"archive/2020/old.md", false, true},
+		{"nested/archive/old.md", false, false},
+		{"identity.wip.md", false, true},
+		{"nested/keep.wip.md", false, false},
+		{"identity.md", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := m.Match(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestFindMarkdownFiles_IgnoreFile(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n")
+	writeTestMarkdown(t, inputDir, "drafts/wip.md", "# WIP\n")
+	writeTestMarkdown(t, inputDir, "drafts/nested/deep.md", "# Deep\n")
+	writeTestMarkdown(t, inputDir, "published/diploma.md", "# Diploma\n")
+	if err := os.WriteFile(filepath.Join(inputDir, ignoreFileName), []byte("drafts/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := findMarkdownFiles(inputDir)
+	if err != nil {
+		t.Fatalf("findMarkdownFiles() error = %v", err)
+	}
+
+	want := map[string]bool{"identity.md": true, filepath.Join("published", "diploma.md"): true}
+	if len(files) != len(want) {
+		t.Errorf("findMarkdownFiles() = %v, want %d files", files, len(want))
+	}
+	for _, f := range files {
+		rel, _ := filepath.Rel(inputDir, f)
+		if !want[rel] {
+			t.Errorf("unexpected file %s", rel)
+		}
+	}
+
+	// Batch skips the ignored subtree
+	outputDir := t.TempDir()
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--no-registry"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "drafts")); !os.IsNotExist(err) {
+		t.Error("ignored subtree should not be processed")
+	}
+}
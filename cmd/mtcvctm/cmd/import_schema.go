@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
+	"github.com/spf13/cobra"
+)
+
+var importSchemaOutput string
+
+var importSchemaCmd = &cobra.Command{
+	Use:   "import-schema <schema.json>",
+	Short: "Bootstrap a markdown credential from a JSON Schema",
+	Long: `Convert a JSON Schema into a markdown credential definition.
+
+The schema title and description become the credential name and description,
+and its properties become the claims list with types, descriptions and
+mandatory flags from required. Nested object properties become nested claims.
+For W3C credential schemas the properties of credentialSubject are used.
+
+The generated markdown is a starting point for editing; display labels,
+localizations and images are not part of a JSON Schema.
+
+Example:
+  mtcvctm import-schema identity.schema.json
+  mtcvctm import-schema identity.schema.json --out identity.md`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportSchema,
+}
+
+func init() {
+	rootCmd.AddCommand(importSchemaCmd)
+	importSchemaCmd.Flags().StringVarP(&importSchemaOutput, "out", "o", "", "Output markdown file (default: input with .md extension)")
+}
+
+// importedSchema is the subset of JSON Schema used to derive claims
+type importedSchema struct {
+	ID              string                     `json:"$id"`
+	Title           string                     `json:"title"`
+	Description     string                     `json:"description"`
+	Type            interface{}                `json:"type"`
+	Format          string                     `json:"format"`
+	ContentEncoding string                     `json:"contentEncoding"`
+	Properties      map[string]*importedSchema `json:"properties"`
+	Required        []string                   `json:"required"`
+}
+
+func runImportSchema(cmd *cobra.Command, args []string) error {
+	inputFile := args[0]
+
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	markdown, err := schemaToMarkdown(data)
+	if err != nil {
+		return err
+	}
+
+	outputPath := importSchemaOutput
+	if outputPath == "" {
+		base := filepath.Base(inputFile)
+		baseName := strings.TrimSuffix(strings.TrimSuffix(base, filepath.Ext(base)), ".schema")
+		outputPath = filepath.Join(filepath.Dir(inputFile), baseName+".md")
+	}
+
+	if err := atomicfile.WriteFile(outputPath, []byte(markdown), 0644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	fmt.Printf("Generated markdown: %s\n", outputPath)
+	return nil
+}
+
+// schemaToMarkdown converts a JSON Schema document into markdown with a claims list
+func schemaToMarkdown(data []byte) (string, error) {
+	var schema importedSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return "", fmt.Errorf("failed to parse JSON Schema: %w", err)
+	}
+
+	// W3C credential schemas describe the claims under credentialSubject
+	claims := &schema
+	if subject, ok := schema.Properties["credentialSubject"]; ok && len(subject.Properties) > 0 {
+		claims = subject
+	}
+
+	var sb strings.Builder
+
+	if schema.ID != "" {
+		sb.WriteString("---\n")
+		sb.WriteString(fmt.Sprintf("schema_uri: %s\n", schema.ID))
+		sb.WriteString("---\n\n")
+	}
+
+	title := schema.Title
+	if title == "" {
+		title = "Credential"
+	}
+	sb.WriteString(fmt.Sprintf("# %s\n\n", title))
+	if schema.Description != "" {
+		sb.WriteString(schema.Description + "\n\n")
+	}
+
+	if len(claims.Properties) > 0 {
+		sb.WriteString("## Claims\n\n")
+		writeSchemaClaims(&sb, claims, "")
+	}
+
+	return sb.String(), nil
+}
+
+// writeSchemaClaims writes one claim list item per property, sorted by name,
+// with object properties as nested claims
+func writeSchemaClaims(sb *strings.Builder, schema *importedSchema, indent string) {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := schema.Properties[name]
+		if prop == nil {
+			continue
+		}
+
+		line := fmt.Sprintf("%s- `%s`", indent, name)
+		if prop.Title != "" && prop.Title != name {
+			line += fmt.Sprintf(" %q", prop.Title)
+		}
+		line += fmt.Sprintf(" (%s)", schemaClaimType(prop))
+		if prop.Description != "" {
+			line += ": " + strings.Join(strings.Fields(prop.Description), " ")
+		}
+		if contains(schema.Required, name) {
+			line += " [mandatory]"
+		}
+		sb.WriteString(line + "\n")
+
+		if len(prop.Properties) > 0 {
+			writeSchemaClaims(sb, prop, indent+"  ")
+		}
+	}
+}
+
+// schemaClaimType maps a JSON Schema type to the markdown claim type
+func schemaClaimType(prop *importedSchema) string {
+	schemaType, _ := prop.Type.(string)
+	if types, ok := prop.Type.([]interface{}); ok {
+		// Nullable types such as ["string", "null"] use the first non-null type
+		for _, t := range types {
+			if s, ok := t.(string); ok && s != "null" {
+				schemaType = s
+				break
+			}
+		}
+	}
+
+	switch schemaType {
+	case "string":
+		switch {
+		case prop.Format == "date":
+			return "date"
+		case prop.Format == "date-time":
+			return "datetime"
+		case prop.ContentEncoding == "base64":
+			return "image"
+		}
+		return "string"
+	case "number", "integer", "boolean", "array", "object":
+		return schemaType
+	}
+	if len(prop.Properties) > 0 {
+		return "object"
+	}
+	return "string"
+}
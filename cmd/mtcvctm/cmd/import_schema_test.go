@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/parser"
+)
+
+const testImportSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://registry.example.com/identity.schema.json",
+  "title": "Identity Credential",
+  "description": "A credential for identity verification",
+  "type": "object",
+  "properties": {
+    "credentialSubject": {
+      "type": "object",
+      "properties": {
+        "given_name": {"type": "string", "title": "Given Name", "description": "The given name"},
+        "birth_date": {"type": "string", "format": "date", "description": "Date of birth"},
+        "age": {"type": ["integer", "null"]},
+        "address": {
+          "type": "object",
+          "description": "Postal address",
+          "properties": {
+            "street": {"type": "string", "description": "Street name"},
+            "postal_code": {"type": "string"}
+          },
+          "required": ["street"]
+        }
+      },
+      "required": ["given_name", "birth_date"]
+    }
+  },
+  "required": ["credentialSubject"]
+}`
+
+func TestSchemaToMarkdown_RoundTrip(t *testing.T) {
+	markdown, err := schemaToMarkdown([]byte(testImportSchema))
+	if err != nil {
+		t.Fatalf("schemaToMarkdown() error = %v", err)
+	}
+
+	parsed, err := parser.NewParser(config.DefaultConfig()).ParseContent([]byte(markdown), "/test/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v\n%s", err, markdown)
+	}
+
+	if parsed.Title != "Identity Credential" || parsed.Description != "A credential for identity verification" {
+		t.Errorf("title/description = %q/%q", parsed.Title, parsed.Description)
+	}
+	if parsed.Metadata["schema_uri"] != "https://registry.example.com/identity.schema.json" {
+		t.Errorf("schema_uri = %q", parsed.Metadata["schema_uri"])
+	}
+
+	want := map[string]struct {
+		typ         string
+		label       string
+		description string
+		mandatory   bool
+	}{
+		"given_name":          {"string", "Given Name", "The given name", true},
+		"birth_date":          {"date", "", "Date of birth", true},
+		"age":                 {"integer", "", "", false},
+		"address":             {"object", "", "Postal address", false},
+		"address.street":      {"string", "", "Street name", true},
+		"address.postal_code": {"string", "", "", false},
+	}
+	if len(parsed.Claims) != len(want) {
+		t.Errorf("claims = %v, want %d claims\n%s", parsed.ClaimOrder, len(want), markdown)
+	}
+	for name, w := range want {
+		claim, ok := parsed.Claims[name]
+		if !ok {
+			t.Errorf("claim %s missing\n%s", name, markdown)
+			continue
+		}
+		if claim.Type != w.typ || claim.DisplayName != w.label || claim.Description != w.description || claim.Mandatory != w.mandatory {
+			t.Errorf("claim %s = %+v, want %+v", name, claim, w)
+		}
+	}
+}
+
+func TestRunImportSchema(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "identity.schema.json")
+	if err := os.WriteFile(input, []byte(testImportSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	importSchemaOutput = ""
+	if err := runImportSchema(importSchemaCmd, []string{input}); err != nil {
+		t.Fatalf("runImportSchema() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "identity.md"))
+	if err != nil {
+		t.Fatalf("default output not written: %v", err)
+	}
+	if !strings.Contains(string(data), "- `given_name` \"Given Name\" (string): The given name [mandatory]") {
+		t.Errorf("unexpected markdown:\n%s", data)
+	}
+
+	if _, err := schemaToMarkdown([]byte("{not json")); err == nil {
+		t.Error("schemaToMarkdown() should reject invalid JSON")
+	}
+}
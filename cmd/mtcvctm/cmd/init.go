@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	initFormat string
+	initDir    string
+	initForce  bool
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init [name]",
+	Short: "Scaffold a credential markdown template",
+	Long: `Write a starter credential markdown file to edit.
+
+The template has front matter with a placeholder vct, colors and a German
+display localization, and a Claims section showing the list syntax with a
+localized claim and the mandatory and sd flags. With --format mddl or w3c
+(or all) the front matter also gets a formats block with the doctype and
+namespace or the type and context to fill in.
+
+The file is named <name>.md (default: credential.md). An existing file is
+only overwritten with --force.
+
+Example:
+  mtcvctm init identity
+  mtcvctm init diploma --format w3c --dir credentials`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runInit,
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringVar(&initFormat, "format", "vctm", "Target formats whose front matter hints to include (vctm, mddl, w3c, all)")
+	initCmd.Flags().StringVarP(&initDir, "dir", "d", ".", "Directory to write the markdown file to")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing file")
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	name := "credential"
+	if len(args) > 0 {
+		name = strings.TrimSuffix(args[0], ".md")
+	}
+
+	formatNames, err := formats.ParseFormats(initFormat)
+	if err != nil {
+		return err
+	}
+
+	outputPath := filepath.Join(initDir, name+".md")
+	if !initForce {
+		if _, err := os.Stat(outputPath); err == nil {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", outputPath)
+		}
+	}
+
+	if err := os.MkdirAll(initDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(outputPath, []byte(scaffoldMarkdown(name, formatNames)), 0644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	fmt.Printf("Created %s\n", outputPath)
+	return nil
+}
+
+// scaffoldMarkdown returns a starter credential definition with front matter hints
+// for the given formats
+func scaffoldMarkdown(name string, formatNames []string) string {
+	id := strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(name))
+	title := scaffoldTitle(id)
+
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.WriteString(fmt.Sprintf("vct: https://registry.example.com/%s\n", id))
+	sb.WriteString("background_color: \"#1a365d\"\n")
+	sb.WriteString("text_color: \"#ffffff\"\n")
+	sb.WriteString("display:\n")
+	sb.WriteString("  de-DE:\n")
+	sb.WriteString(fmt.Sprintf("    name: %s\n", title))
+	sb.WriteString("    description: Beschreibung des Nachweises\n")
+
+	var mddlHints, w3cHints bool
+	for _, format := range formatNames {
+		mddlHints = mddlHints || format == "mddl"
+		w3cHints = w3cHints || format == "w3c"
+	}
+	if mddlHints || w3cHints {
+		sb.WriteString("formats:\n")
+	}
+	if mddlHints {
+		sb.WriteString("  mddl:\n")
+		sb.WriteString(fmt.Sprintf("    doctype: com.example.%s.1\n", id))
+		sb.WriteString(fmt.Sprintf("    namespace: com.example.%s.1\n", id))
+	}
+	if w3cHints {
+		sb.WriteString("  w3c:\n")
+		sb.WriteString(fmt.Sprintf("    type: %sCredential\n", strings.ReplaceAll(title, " ", "")))
+		sb.WriteString("    context:\n")
+		sb.WriteString(fmt.Sprintf("      - https://registry.example.com/contexts/%s/v1\n", id))
+	}
+	sb.WriteString("---\n\n")
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", title))
+	sb.WriteString("Describe what this credential attests and who issues it.\n\n")
+
+	sb.WriteString("## Claims\n\n")
+	sb.WriteString("- `given_name` \"Given Name\" (string): The given name of the holder [mandatory, sd=always]\n")
+	sb.WriteString("  - de-DE: \"Vorname\" - Der Vorname des Inhabers\n")
+	sb.WriteString("- `family_name` \"Family Name\" (string): The family name of the holder [mandatory]\n")
+	sb.WriteString("- `birth_date` \"Date of Birth\" (date): The date of birth of the holder [sd=always]\n")
+	sb.WriteString("- `address` (object): The postal address of the holder [sd=allowed]\n")
+	sb.WriteString("  - `street` (string): Street and house number\n")
+	sb.WriteString("  - `locality` (string): City or town\n")
+
+	return sb.String()
+}
+
+// scaffoldTitle turns a credential id such as student_card into "Student Card"
+func scaffoldTitle(id string) string {
+	words := strings.FieldsFunc(id, func(r rune) bool { return r == '_' || r == '.' })
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	if len(words) == 0 {
+		return "Credential"
+	}
+	return strings.Join(words, " ")
+}
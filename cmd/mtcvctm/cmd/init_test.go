@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// resetFlags restores the default values of a command's flags and parses args
+func resetFlags(t *testing.T, flags *pflag.FlagSet, args ...string) {
+	t.Helper()
+	flags.VisitAll(func(f *pflag.Flag) {
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	})
+	if err := flags.Parse(args); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+}
+
+func TestInit_ScaffoldGenerates(t *testing.T) {
+	dir := t.TempDir()
+
+	resetFlags(t, initCmd.Flags(), "--dir", dir, "--format", "all")
+	if err := runInit(initCmd, []string{"student_card"}); err != nil {
+		t.Fatalf("runInit() error = %v", err)
+	}
+	path := filepath.Join(dir, "student_card.md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("scaffold not written: %v", err)
+	}
+	for _, want := range []string{"# Student Card", "doctype: com.example.student_card.1", "type: StudentCardCredential"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("scaffold missing %q:\n%s", want, data)
+		}
+	}
+
+	outDir := t.TempDir()
+	resetFlags(t, generateCmd.Flags(), "--format", "all", "--output-dir", outDir, "--strict")
+	if err := generateFile(path); err != nil {
+		t.Fatalf("generateFile() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "student_card.vctm.json")); err != nil {
+		t.Errorf("vctm output not written: %v", err)
+	}
+
+	// An existing file is only replaced with --force
+	resetFlags(t, initCmd.Flags(), "--dir", dir)
+	if err := runInit(initCmd, []string{"student_card"}); err == nil {
+		t.Error("runInit() should refuse to overwrite an existing file")
+	}
+	resetFlags(t, initCmd.Flags(), "--dir", dir, "--force")
+	if err := runInit(initCmd, []string{"student_card"}); err != nil {
+		t.Errorf("runInit() with --force error = %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+	"github.com/sirosfoundation/mtcvctm/pkg/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintStrict       bool
+	lintLanguage     string
+	lintOrphanImages bool
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint <file|dir>",
+	Short: "Check markdown credentials for authoring problems",
+	Long: `Check markdown credential definitions for problems that do not prevent
+generation but lead to unpolished output.
+
+Reported are the parser warnings (such as unknown claim flags) and claims
+without a display label in the default locale, which fall back to the raw
+claim name (except [hidden] claims), and, in credentials that group claims
+with [group=...], claims without a group. With --orphan-images, image files
+below the credential directory that no markdown file references are reported
+as cleanup candidates. With --strict any finding makes the command exit non-zero.
+
+A directory is searched recursively for markdown files like batch.
+
+Example:
+  mtcvctm lint identity.md
+  mtcvctm lint ./credentials --strict
+  mtcvctm lint ./credentials --orphan-images`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLint,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().BoolVar(&lintStrict, "strict", false, "Exit non-zero if any warning is found")
+	lintCmd.Flags().StringVar(&lintLanguage, "language", "en-US", "Default language for display properties")
+	lintCmd.Flags().BoolVar(&lintOrphanImages, "orphan-images", false, "Report image files not referenced by any markdown file")
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	return lintPath(os.Stdout, args[0], lintLanguage, lintStrict, lintOrphanImages)
+}
+
+// lintPath lints a markdown file or all markdown files below a directory,
+// writing one line per finding. With orphanImages, unreferenced image files
+// below the credential directory are reported as well.
+func lintPath(w io.Writer, target, language string, strict, orphanImages bool) error {
+	info, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("failed to access %s: %w", target, err)
+	}
+
+	files := []string{target}
+	root := filepath.Dir(target)
+	if info.IsDir() {
+		root = target
+		files, err = findMarkdownFiles(target)
+		if err != nil {
+			return fmt.Errorf("failed to find markdown files: %w", err)
+		}
+	}
+
+	total := 0
+	referenced := make(map[string]bool)
+	for _, mdFile := range files {
+		cfg, err := directoryConfig(mdFile, root, nil)
+		if err != nil {
+			return err
+		}
+		cfg.InputFile = mdFile
+		cfg.Language = language
+
+		cred, err := parser.NewParser(cfg).ParseToCredential(mdFile)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", mdFile, err)
+		}
+
+		relPath, err := filepath.Rel(root, mdFile)
+		if err != nil {
+			relPath = mdFile
+		}
+		for _, finding := range lintCredential(cred, cfg) {
+			fmt.Fprintf(w, "%s: %s\n", relPath, finding)
+			total++
+		}
+		for _, image := range referencedImages(cred) {
+			referenced[image] = true
+		}
+	}
+
+	if orphanImages {
+		orphans, err := findOrphanImages(root, referenced)
+		if err != nil {
+			return fmt.Errorf("failed to find images: %w", err)
+		}
+		for _, orphan := range orphans {
+			fmt.Fprintf(w, "%s: image is not referenced by any markdown file\n", orphan)
+			total++
+		}
+	}
+
+	fmt.Fprintf(w, "%d file(s), %d warning(s)\n", len(files), total)
+	if strict && total > 0 {
+		return fmt.Errorf("lint failed: %d warning(s)", total)
+	}
+	return nil
+}
+
+// lintCredential returns the lint findings for a parsed credential
+func lintCredential(cred *formats.ParsedCredential, cfg *config.Config) []string {
+	findings := append([]string(nil), cred.Warnings...)
+
+	locale := formats.DefaultLocale(cred, cfg)
+	grouped := false
+	for _, claim := range cred.Claims {
+		if !claim.Hidden && claim.DisplayName == "" && claim.Localizations[locale].Label == "" {
+			findings = append(findings, fmt.Sprintf("claim %s has no display label in %s", claim.Name, locale))
+		}
+		grouped = grouped || claim.Group != ""
+	}
+
+	// Once a credential groups its claims, ungrouped claims are likely oversights
+	if grouped {
+		for _, claim := range cred.Claims {
+			if claim.Group == "" {
+				findings = append(findings, fmt.Sprintf("claim %s has no group", claim.Name))
+			}
+		}
+	}
+
+	return findings
+}
+
+// lintImageExts are the file extensions considered images by --orphan-images
+var lintImageExts = map[string]bool{
+	".svg": true, ".png": true, ".jpg": true, ".jpeg": true,
+	".gif": true, ".webp": true, ".ico": true,
+}
+
+// referencedImages returns the absolute paths of the local images a credential uses
+func referencedImages(cred *formats.ParsedCredential) []string {
+	var paths []string
+	for _, img := range cred.Images {
+		if img.AbsolutePath != "" {
+			paths = append(paths, img.AbsolutePath)
+		}
+	}
+	if cred.LogoAbsPath != "" {
+		paths = append(paths, cred.LogoAbsPath)
+	}
+	if cred.SVGTemplatePath != "" {
+		paths = append(paths, filepath.Join(cred.SourceDir, cred.SVGTemplatePath))
+	}
+
+	for i, path := range paths {
+		if abs, err := filepath.Abs(path); err == nil {
+			paths[i] = abs
+		}
+	}
+	return paths
+}
+
+// findOrphanImages returns the image files below root, relative to root, whose
+// absolute paths are not in referenced. Hidden directories are skipped.
+func findOrphanImages(root string, referenced map[string]bool) ([]string, error) {
+	var orphans []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !lintImageExts[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		if referenced[absPath] {
+			return nil
+		}
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			relPath = path
+		}
+		orphans = append(orphans, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orphans, nil
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintPath_MissingDisplayLabel(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n## Claims\n\n"+
+		"- `given_name` \"Given Name\" (string): Given name\n"+
+		"- `birth_date` (date): Date of birth\n"+
+		"- `nickname` (string): Nickname\n"+
+		"  - en-US: \"Nickname\" - Preferred name\n")
+
+	var out bytes.Buffer
+	if err := lintPath(&out, inputDir, "en-US", false, false); err != nil {
+		t.Fatalf("lintPath() error = %v", err)
+	}
+	report := out.String()
+
+	if !strings.Contains(report, "identity.md: claim birth_date has no display label in en-US") {
+		t.Errorf("report missing birth_date finding:\n%s", report)
+	}
+	for _, labelled := range []string{"given_name", "nickname"} {
+		if strings.Contains(report, "claim "+labelled+" ") {
+			t.Errorf("claim %s with a display label should not be flagged:\n%s", labelled, report)
+		}
+	}
+	if !strings.Contains(report, "1 file(s), 1 warning(s)") {
+		t.Errorf("report summary missing:\n%s", report)
+	}
+
+	out.Reset()
+	if err := lintPath(&out, inputDir, "en-US", true, false); err == nil {
+		t.Error("lintPath() in strict mode should fail")
+	}
+}
+
+func TestLintPath_Clean(t *testing.T) {
+	path := writeTestMarkdown(t, t.TempDir(), "identity.md", "# Identity\n\n## Claims\n\n"+
+		"- `given_name` \"Given Name\" (string): Given name [mandatory]\n")
+
+	var out bytes.Buffer
+	if err := lintPath(&out, path, "en-US", true, false); err != nil {
+		t.Fatalf("lintPath() error = %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "1 file(s), 0 warning(s)") {
+		t.Errorf("unexpected report:\n%s", out.String())
+	}
+}
+
+func TestLintPath_DirectoryClaimsSection(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, ".mtcvctm.yaml", "claims_section: strict\n")
+	writeTestMarkdown(t, inputDir, "legacy.md", "# Legacy\n\n- `given_name` (string): Given name\n")
+
+	var out bytes.Buffer
+	if err := lintPath(&out, inputDir, "en-US", true, false); err != nil {
+		t.Fatalf("lintPath() error = %v\n%s", err, out.String())
+	}
+	if strings.Contains(out.String(), "given_name") {
+		t.Errorf("claims_section: strict should ignore the list outside a claims section:\n%s", out.String())
+	}
+}
+
+func TestLintPath_OrphanImages(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n![Logo](images/logo.png)\n\n## Claims\n\n"+
+		"- `given_name` \"Given Name\" (string): Given name\n")
+	imagesDir := filepath.Join(inputDir, "images")
+	if err := os.MkdirAll(imagesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"logo.png", "unused.png", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(imagesDir, name), []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := lintPath(&out, inputDir, "en-US", false, false); err != nil {
+		t.Fatalf("lintPath() error = %v", err)
+	}
+	if strings.Contains(out.String(), "unused.png") {
+		t.Errorf("orphan images should only be reported when requested:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := lintPath(&out, inputDir, "en-US", false, true); err != nil {
+		t.Fatalf("lintPath() error = %v", err)
+	}
+	report := out.String()
+
+	if !strings.Contains(report, filepath.Join("images", "unused.png")+": image is not referenced by any markdown file") {
+		t.Errorf("report missing orphan image:\n%s", report)
+	}
+	for _, name := range []string{"logo.png", "notes.txt"} {
+		if strings.Contains(report, name) {
+			t.Errorf("%s should not be flagged:\n%s", name, report)
+		}
+	}
+	if !strings.Contains(report, "1 file(s), 1 warning(s)") {
+		t.Errorf("report summary missing:\n%s", report)
+	}
+}
+
+func TestLintPath_UngroupedClaims(t *testing.T) {
+	path := writeTestMarkdown(t, t.TempDir(), "identity.md", "# Identity\n\n## Claims\n\n"+
+		"- `given_name` \"Given Name\" (string): Given name [group=Personal]\n"+
+		"- `document_number` \"Number\" (string): Document number\n")
+
+	var out bytes.Buffer
+	if err := lintPath(&out, path, "en-US", false, false); err != nil {
+		t.Fatalf("lintPath() error = %v", err)
+	}
+	report := out.String()
+	if !strings.Contains(report, "identity.md: claim document_number has no group") {
+		t.Errorf("report missing ungrouped claim:\n%s", report)
+	}
+	if strings.Contains(report, "claim given_name has no group") {
+		t.Errorf("grouped claim should not be flagged:\n%s", report)
+	}
+}
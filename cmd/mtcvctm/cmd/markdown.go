@@ -11,6 +11,9 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/parser"
 	"github.com/sirosfoundation/mtcvctm/pkg/vctm"
 	"github.com/spf13/cobra"
 )
@@ -21,13 +24,15 @@ var (
 )
 
 var markdownCmd = &cobra.Command{
-	Use:   "markdown <input.vctm.json>",
-	Short: "Convert VCTM JSON to editable markdown",
+	Use:     "markdown <input.vctm.json>",
+	Aliases: []string{"import"},
+	Short:   "Convert VCTM JSON to editable markdown",
 	Long: `Convert an existing VCTM JSON file to markdown format for easier editing.
 
 This command is a one-shot tool for migrating existing VCTM files to the
 markdown-based workflow. The generated markdown preserves all metadata
-including claims, localizations, and display properties.
+including claims, localizations, and display properties, so that generating
+VCTM from it again reproduces the original document.
 
 By default, images (logos and SVG templates) are extracted from data URLs
 or downloaded from remote URLs and saved to an 'images/' directory. Use
@@ -35,6 +40,7 @@ or downloaded from remote URLs and saved to an 'images/' directory. Use
 
 Example:
   mtcvctm markdown credential.vctm.json
+  mtcvctm import credential.vctm.json
   mtcvctm markdown credential.vctm.json -o credential.md
   mtcvctm markdown credential.vctm.json --no-extract-images`,
 	Args: cobra.ExactArgs(1),
@@ -90,7 +96,7 @@ func runMarkdown(cmd *cobra.Command, args []string) error {
 	}
 
 	// Write output
-	if err := os.WriteFile(outputPath, []byte(markdown), 0644); err != nil {
+	if err := atomicfile.WriteFile(outputPath, []byte(markdown), 0644); err != nil {
 		return fmt.Errorf("failed to write output: %w", err)
 	}
 
@@ -127,6 +133,12 @@ func VCTMToMarkdownWithOptions(v *vctm.VCTM, opts *MarkdownOptions) (string, err
 	if v.ExtendsIntegrity != "" {
 		sb.WriteString(fmt.Sprintf("extends#integrity: %s\n", v.ExtendsIntegrity))
 	}
+	if v.SchemaURI != "" {
+		sb.WriteString(fmt.Sprintf("schema_uri: %s\n", v.SchemaURI))
+	}
+	if v.SchemaURIIntegrity != "" {
+		sb.WriteString(fmt.Sprintf("schema_uri#integrity: %s\n", v.SchemaURIIntegrity))
+	}
 
 	// Extract display properties for front matter
 	if len(v.Display) > 0 {
@@ -238,7 +250,7 @@ func VCTMToMarkdownWithOptions(v *vctm.VCTM, opts *MarkdownOptions) (string, err
 							return "", fmt.Errorf("failed to process template: %w", err)
 						}
 						if imgPath != "" {
-							sb.WriteString(fmt.Sprintf("![Template](%s)\n", imgPath))
+							sb.WriteString(fmt.Sprintf("![Template](%s)%s\n", imgPath, svgTemplateAttributes(tmpl.Properties)))
 						}
 					}
 				}
@@ -250,6 +262,28 @@ func VCTMToMarkdownWithOptions(v *vctm.VCTM, opts *MarkdownOptions) (string, err
 	return sb.String(), nil
 }
 
+// svgTemplateAttributes formats SVG template properties as the attribute block
+// written after a template image, e.g. {orientation=portrait color_scheme=dark}
+func svgTemplateAttributes(props *vctm.SVGTemplateProperties) string {
+	if props == nil {
+		return ""
+	}
+	var attrs []string
+	if props.Orientation != "" {
+		attrs = append(attrs, "orientation="+props.Orientation)
+	}
+	if props.ColorScheme != "" {
+		attrs = append(attrs, "color_scheme="+props.ColorScheme)
+	}
+	if props.Contrast != "" {
+		attrs = append(attrs, "contrast="+props.Contrast)
+	}
+	if len(attrs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(attrs, " ") + "}"
+}
+
 // processImage handles image extraction or returns the original URI
 func processImage(uri, nameHint string, opts *MarkdownOptions, extractedImages *[]string) (string, error) {
 	if opts == nil || !opts.ExtractImages {
@@ -296,7 +330,7 @@ func processImage(uri, nameHint string, opts *MarkdownOptions, extractedImages *
 
 	// Write file
 	filePath := filepath.Join(opts.ImagesDir, filename)
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	if err := atomicfile.WriteFile(filePath, data, 0644); err != nil {
 		return "", fmt.Errorf("failed to write image: %w", err)
 	}
 
@@ -480,6 +514,9 @@ func claimToMarkdown(claim vctm.ClaimMetadataEntry) string {
 	if claim.SvgId != "" {
 		flags = append(flags, fmt.Sprintf("svg_id=%s", claim.SvgId))
 	}
+	if claim.Group != "" {
+		flags = append(flags, fmt.Sprintf("group=%s", claim.Group))
+	}
 	if len(flags) > 0 {
 		sb.WriteString(fmt.Sprintf(" [%s]", strings.Join(flags, ", ")))
 	}
@@ -530,11 +567,15 @@ func pathToClaimName(path []interface{}) string {
 	return strings.Join(parts, ".")
 }
 
-// VCTMFromMarkdownContent parses markdown content and returns VCTM for testing
+// VCTMFromMarkdownContent parses markdown content and returns its VCTM using the
+// default configuration, the reverse of VCTMToMarkdown
 func VCTMFromMarkdownContent(content []byte) (*vctm.VCTM, error) {
-	// This is a wrapper for testing round-trip conversion
-	// Uses internal parser functionality
-	return nil, fmt.Errorf("not implemented - use parser package directly")
+	p := parser.NewParser(config.DefaultConfig())
+	parsed, err := p.ParseContent(content, "credential.md")
+	if err != nil {
+		return nil, err
+	}
+	return p.ToVCTM(parsed)
 }
 
 // MarshalVCTM marshals VCTM to JSON for comparison
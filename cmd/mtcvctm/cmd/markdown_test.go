@@ -392,6 +392,68 @@ func TestVCTMJSONRoundTrip(t *testing.T) {
 	}
 }
 
+// TestImportRoundTripIdempotent tests that generate -> import -> generate
+// reproduces the generated VCTM
+func TestImportRoundTripIdempotent(t *testing.T) {
+	source := `---
+vct: https://example.com/credentials/identity
+extends: https://example.com/credentials/base
+background_color: "#003366"
+text_color: "#ffffff"
+display:
+  de-DE:
+    name: "Identität"
+    description: "Ein Identitätsnachweis"
+---
+
+# Identity Credential
+
+An identity credential.
+
+## Claims
+
+- ` + "`given_name`" + ` "Given Name" (string): The given name [mandatory]
+  - de-DE: "Vorname" - Der Vorname
+- ` + "`birth_date`" + ` "Birth Date" (date): Date of birth [sd=always]
+- ` + "`address`" + ` "Address" (object): Postal address
+  - ` + "`street`" + ` "Street" (string): Street name
+- ` + "`nationalities.[]`" + ` "Nationality" (string): Nationalities [sd=never]
+`
+
+	generated, err := VCTMFromMarkdownContent([]byte(source))
+	if err != nil {
+		t.Fatalf("VCTMFromMarkdownContent() error = %v", err)
+	}
+	original, err := MarshalVCTM(generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Import from the JSON as the import command does
+	loaded, err := vctm.FromJSON(original)
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	markdown := VCTMToMarkdown(loaded)
+
+	regenerated, err := VCTMFromMarkdownContent([]byte(markdown))
+	if err != nil {
+		t.Fatalf("VCTMFromMarkdownContent() error = %v\n%s", err, markdown)
+	}
+	roundTrip, err := MarshalVCTM(regenerated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(roundTrip) != string(original) {
+		t.Errorf("regenerated VCTM differs from original\noriginal:\n%s\nregenerated:\n%s\nmarkdown:\n%s", original, roundTrip, markdown)
+	}
+
+	if cmd, _, err := rootCmd.Find([]string{"import"}); err != nil || cmd != markdownCmd {
+		t.Error("import should be an alias of the markdown command")
+	}
+}
+
 // TestDecodeDataURL tests decoding of data URLs
 func TestDecodeDataURL(t *testing.T) {
 	tests := []struct {
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
 	"github.com/sirosfoundation/mtcvctm/pkg/rules"
 	"github.com/spf13/cobra"
 )
@@ -153,7 +154,7 @@ func runNormalize(cmd *cobra.Command, args []string) error {
 	}
 
 	// Write output
-	if err := os.WriteFile(outputPath, output, 0644); err != nil {
+	if err := atomicfile.WriteFile(outputPath, output, 0644); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// postProcessOutput runs the configured post_process command over a generated
+// file. The command receives the output on stdin and the format name and output
+// path as arguments (also as MTCVCTM_FORMAT and MTCVCTM_OUTPUT), and its stdout
+// replaces the output. Without a command the data is returned unchanged.
+func postProcessOutput(command, formatName, outputPath string, data []byte) ([]byte, error) {
+	if command == "" {
+		return data, nil
+	}
+
+	cmd := exec.Command(command, formatName, outputPath)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(), "MTCVCTM_FORMAT="+formatName, "MTCVCTM_OUTPUT="+outputPath)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("post-processor %s failed for %s: %w: %s", command, outputPath, err, msg)
+		}
+		return nil, fmt.Errorf("post-processor %s failed for %s: %w", command, outputPath, err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writePostProcessor writes an executable shell script used as post_process command
+func writePostProcessor(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("post-processor test scripts require a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "postprocess.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBatch_PostProcess(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	// Uppercase the credential name and record the arguments the script received
+	script := writePostProcessor(t, `echo "$1 $MTCVCTM_FORMAT" > "$(dirname "$0")/args"
+sed 's/"Identity Credential"/"IDENTITY CREDENTIAL"/'
+`)
+	writeTestMarkdown(t, inputDir, ".mtcvctm.yaml", "post_process: "+script+"\n")
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity Credential\n\nAn identity credential.\n\n## Claims\n\n- `given_name` (string): Given name\n")
+
+	if err := runBatchWithArgs(t, "--input", inputDir, "--output", outputDir, "--no-registry"); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	var doc map[string]interface{}
+	readJSONFile(t, filepath.Join(outputDir, "identity.vctm.json"), &doc)
+	if doc["name"] != "IDENTITY CREDENTIAL" {
+		t.Errorf("name = %v, want post-processed IDENTITY CREDENTIAL", doc["name"])
+	}
+
+	args, err := os.ReadFile(filepath.Join(filepath.Dir(script), "args"))
+	if err != nil {
+		t.Fatalf("post-processor did not run: %v", err)
+	}
+	if strings.TrimSpace(string(args)) != "vctm vctm" {
+		t.Errorf("post-processor args = %q, want format name as argument and in the environment", args)
+	}
+}
+
+func TestPostProcessOutput_Failure(t *testing.T) {
+	script := writePostProcessor(t, "echo 'redaction failed' >&2\nexit 3\n")
+
+	_, err := postProcessOutput(script, "vctm", "identity.vctm", []byte("{}"))
+	if err == nil {
+		t.Fatal("postProcessOutput() should fail on non-zero exit")
+	}
+	if !strings.Contains(err.Error(), "redaction failed") {
+		t.Errorf("error should include the command's stderr: %v", err)
+	}
+
+	data, err := postProcessOutput("", "vctm", "identity.vctm", []byte("{}"))
+	if err != nil || string(data) != "{}" {
+		t.Errorf("postProcessOutput() without command = %q, %v; want data unchanged", data, err)
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+	"github.com/sirosfoundation/mtcvctm/pkg/parser"
+	"github.com/spf13/cobra"
+)
+
+// Privacy findings flagged in the report
+const (
+	privacyMandatoryNeverSD = "mandatory-never-sd"
+	privacyMissingSD        = "missing-sd"
+)
+
+var privacyCmd = &cobra.Command{
+	Use:   "privacy <dir>",
+	Short: "Report selective disclosure settings of all claims",
+	Long: `Print a per-credential table of every claim with its mandatory flag and
+selective disclosure (sd) setting, for privacy review.
+
+Claims are flagged when they are mandatory but never selectively disclosable
+(mandatory-never-sd) or when they have no explicit sd setting (missing-sd).
+
+Example:
+  mtcvctm privacy ./credentials`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPrivacy,
+}
+
+func init() {
+	rootCmd.AddCommand(privacyCmd)
+}
+
+// privacyRow is the privacy classification of a single claim
+type privacyRow struct {
+	Claim     string
+	Mandatory bool
+	SD        string
+	Flags     []string
+}
+
+func runPrivacy(cmd *cobra.Command, args []string) error {
+	return writePrivacyReport(os.Stdout, args[0])
+}
+
+// writePrivacyReport writes the privacy report for all markdown files in dir
+func writePrivacyReport(w io.Writer, dir string) error {
+	mdFiles, err := findMarkdownFiles(dir)
+	if err != nil {
+		return fmt.Errorf("failed to find markdown files: %w", err)
+	}
+
+	flagged := 0
+	for _, mdFile := range mdFiles {
+		cfg, err := directoryConfig(mdFile, dir, nil)
+		if err != nil {
+			return err
+		}
+		cfg.InputFile = mdFile
+
+		cred, err := parser.NewParser(cfg).ParseToCredential(mdFile)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", mdFile, err)
+		}
+
+		relPath, _ := filepath.Rel(dir, mdFile)
+		fmt.Fprintf(w, "%s (%s)\n", cred.Name, relPath)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  CLAIM\tMANDATORY\tSD\tFLAGS")
+		for _, row := range classifyClaims(cred) {
+			sd := row.SD
+			if sd == "" {
+				sd = "-"
+			}
+			fmt.Fprintf(tw, "  %s\t%t\t%s\t%s\n", row.Claim, row.Mandatory, sd, strings.Join(row.Flags, ","))
+			if len(row.Flags) > 0 {
+				flagged++
+			}
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "%d credential(s), %d flagged claim(s)\n", len(mdFiles), flagged)
+	return nil
+}
+
+// classifyClaims returns the privacy classification of a credential's claims sorted by name
+func classifyClaims(cred *formats.ParsedCredential) []privacyRow {
+	rows := make([]privacyRow, 0, len(cred.Claims))
+	for _, claim := range cred.Claims {
+		row := privacyRow{
+			Claim:     claim.Name,
+			Mandatory: claim.Mandatory,
+			SD:        claim.SD,
+		}
+		switch {
+		case claim.SD == "":
+			row.Flags = append(row.Flags, privacyMissingSD)
+		case claim.Mandatory && claim.SD == "never":
+			row.Flags = append(row.Flags, privacyMandatoryNeverSD)
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Claim < rows[j].Claim })
+	return rows
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWritePrivacyReport(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity\n\n## Claims\n\n"+
+		"- `given_name` (string): Given name [mandatory, sd=always]\n"+
+		"- `document_number` (string): Document number [mandatory, sd=never]\n"+
+		"- `nickname` (string): Nickname [sd=allowed]\n"+
+		"- `email` (string): Email\n")
+
+	var out bytes.Buffer
+	if err := writePrivacyReport(&out, inputDir); err != nil {
+		t.Fatalf("writePrivacyReport() error = %v", err)
+	}
+	report := out.String()
+
+	// Each claim line: name, mandatory, sd, flags
+	want := map[string][]string{
+		"given_name":      {"true", "always"},
+		"document_number": {"true", "never", privacyMandatoryNeverSD},
+		"nickname":        {"false", "allowed"},
+		"email":           {"false", "-", privacyMissingSD},
+	}
+	for _, line := range strings.Split(report, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		wantFields, ok := want[fields[0]]
+		if !ok {
+			continue
+		}
+		if strings.Join(fields[1:], " ") != strings.Join(wantFields, " ") {
+			t.Errorf("%s row = %v, want %v", fields[0], fields[1:], wantFields)
+		}
+		delete(want, fields[0])
+	}
+	for claim := range want {
+		t.Errorf("report missing row for %s:\n%s", claim, report)
+	}
+
+	if !strings.Contains(report, "1 credential(s), 2 flagged claim(s)") {
+		t.Errorf("report summary missing, got:\n%s", report)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/sirosfoundation/mtcvctm/internal/action"
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
 	"github.com/sirosfoundation/mtcvctm/pkg/rules"
 	"github.com/sirosfoundation/mtcvctm/pkg/vctm"
 	"github.com/spf13/cobra"
@@ -210,7 +211,7 @@ func runPublishVCTM(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("failed to serialize VCTM %s: %w", vctmFile, err)
 		}
-		if err := os.WriteFile(outputPath, outputData, 0644); err != nil {
+		if err := atomicfile.WriteFile(outputPath, outputData, 0644); err != nil {
 			return fmt.Errorf("failed to write VCTM %s: %w", vctmFile, err)
 		}
 		fmt.Printf("  -> Published: %s\n", outputPath)
@@ -368,20 +369,7 @@ func findSchemaMetaFiles(dir string) ([]string, error) {
 
 // copyVCTMFile copies a VCTM file from src to dst
 func copyVCTMFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer sourceFile.Close()
-
-	destFile, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer destFile.Close()
-
-	_, err = io.Copy(destFile, sourceFile)
-	return err
+	return atomicfile.CopyFile(src, dst, 0644)
 }
 
 // processVCTMImages processes all images in a VCTM, downloading network resources
@@ -509,7 +497,7 @@ func processImageURL(url string, baseName string, imageType string, displayIndex
 	fileName = sanitizeFileName(fileName)
 	filePath := filepath.Join(imagesDir, fileName)
 
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	if err := atomicfile.WriteFile(filePath, data, 0644); err != nil {
 		return "", "", fmt.Errorf("failed to write image: %w", err)
 	}
 
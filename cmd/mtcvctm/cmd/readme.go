@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+)
+
+// generateCredentialReadme renders a markdown documentation page for a credential
+// summarizing its identifiers, display properties and claims
+func generateCredentialReadme(cred *formats.ParsedCredential, cfg *config.Config, vct string) string {
+	var sb strings.Builder
+
+	name := cred.Name
+	if name == "" {
+		name = cred.ID
+	}
+	sb.WriteString(fmt.Sprintf("# %s\n\n", name))
+	if cred.Description != "" {
+		sb.WriteString(cred.Description + "\n\n")
+	}
+
+	if vct != "" {
+		sb.WriteString(fmt.Sprintf("- **VCT:** `%s`\n", vct))
+	}
+	if cred.DocType != "" {
+		sb.WriteString(fmt.Sprintf("- **Doctype:** `%s`\n", cred.DocType))
+	}
+	if vct != "" || cred.DocType != "" {
+		sb.WriteString("\n")
+	}
+
+	// Display properties, one row per locale
+	sb.WriteString("## Display\n\n")
+	sb.WriteString("| Locale | Name | Description |\n")
+	sb.WriteString("|--------|------|-------------|\n")
+	for _, display := range formats.CredentialDisplay(cred, cfg) {
+		description, _ := display["description"].(string)
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n",
+			readmeCell(display["locale"].(string)),
+			readmeCell(display["name"].(string)),
+			readmeCell(description)))
+	}
+	sb.WriteString("\n")
+	if cred.BackgroundColor != "" {
+		sb.WriteString(fmt.Sprintf("- **Background color:** `%s`\n", cred.BackgroundColor))
+	}
+	if cred.TextColor != "" {
+		sb.WriteString(fmt.Sprintf("- **Text color:** `%s`\n", cred.TextColor))
+	}
+	if cred.BackgroundColor != "" || cred.TextColor != "" {
+		sb.WriteString("\n")
+	}
+
+	if len(cred.Claims) == 0 {
+		return sb.String()
+	}
+
+	claims := make([]formats.ClaimDefinition, len(cred.Claims))
+	copy(claims, cred.Claims)
+	sort.Slice(claims, func(i, j int) bool { return claims[i].Name < claims[j].Name })
+
+	sb.WriteString("## Claims\n\n")
+	sb.WriteString("| Claim | Label | Type | Mandatory | SD | Description | Localizations |\n")
+	sb.WriteString("|-------|-------|------|-----------|----|-------------|---------------|\n")
+	for _, claim := range claims {
+		mandatory := "no"
+		if claim.Mandatory {
+			mandatory = "yes"
+		}
+		sb.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s | %s | %s | %s |\n",
+			claim.Name,
+			readmeCell(claim.DisplayName),
+			readmeCell(claim.Type),
+			mandatory,
+			readmeCell(claim.SD),
+			readmeCell(claim.Description),
+			readmeCell(claimLocalizationSummary(claim))))
+	}
+
+	return sb.String()
+}
+
+// claimLocalizationSummary lists a claim's localized labels as "locale: label" pairs
+func claimLocalizationSummary(claim formats.ClaimDefinition) string {
+	locales := make([]string, 0, len(claim.Localizations))
+	for locale := range claim.Localizations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	parts := make([]string, 0, len(locales))
+	for _, locale := range locales {
+		parts = append(parts, fmt.Sprintf("%s: %s", locale, claim.Localizations[locale].Label))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// readmeCell escapes a value for use in a markdown table cell
+func readmeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
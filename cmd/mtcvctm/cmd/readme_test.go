@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+)
+
+func TestGenerateCredentialReadme(t *testing.T) {
+	cfg := &config.Config{Language: "en-US"}
+	cred := &formats.ParsedCredential{
+		ID:          "identity",
+		Name:        "Identity",
+		Description: "An identity credential.",
+		Localizations: map[string]formats.DisplayLocalization{
+			"de-DE": {Name: "Identität"},
+		},
+		Claims: []formats.ClaimDefinition{
+			{Name: "given_name", DisplayName: "Given Name", Type: "string", Mandatory: true, SD: "always",
+				Localizations: map[string]formats.ClaimLocalization{"de-DE": {Label: "Vorname"}}},
+			{Name: "note", Type: "string", Description: "A | separated note"},
+		},
+	}
+
+	readme := generateCredentialReadme(cred, cfg, "https://example.com/identity")
+
+	wantContains := []string{
+		"# Identity",
+		"- **VCT:** `https://example.com/identity`",
+		"| de-DE | Identität |  |",
+		"| `given_name` | Given Name | string | yes | always |  | de-DE: Vorname |",
+		"| `note` |  | string | no |  | A \\| separated note |  |",
+	}
+	for _, want := range wantContains {
+		if !strings.Contains(readme, want) {
+			t.Errorf("README missing %q, got:\n%s", want, readme)
+		}
+	}
+}
+
+func TestBatch_EmitReadme(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	writeTestMarkdown(t, inputDir, "identity.md", "# Identity Credential\n\nAn identity credential.\n\n## Claims\n\n"+
+		"- `given_name` \"Given Name\" (string): Given name [mandatory]\n"+
+		"- `birth_date` (date): Date of birth [sd=always]\n")
+
+	err := runBatchWithArgs(t,
+		"--input", inputDir,
+		"--output", outputDir,
+		"--emit-readme",
+	)
+	if err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "identity.README.md"))
+	if err != nil {
+		t.Fatalf("README not written: %v", err)
+	}
+	readme := string(data)
+
+	for _, row := range []string{
+		"| `given_name` | Given Name | string | yes |",
+		"| `birth_date` |  | date | no | always |",
+	} {
+		if !strings.Contains(readme, row) {
+			t.Errorf("README missing claim row %q, got:\n%s", row, readme)
+		}
+	}
+}
@@ -2,8 +2,11 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"runtime"
 
 	"github.com/spf13/cobra"
 )
@@ -34,16 +37,49 @@ func Execute() error {
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().BoolVar(&versionJSONFlag, "json", false, "Print build metadata as JSON")
 }
 
+var versionJSONFlag bool
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("mtcvctm %s (commit: %s)\n", Version, Commit)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return writeVersion(os.Stdout, versionJSONFlag)
 	},
 }
 
+// versionInfo is the build metadata printed by version --json
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// writeVersion writes the version as a human-readable line or as JSON build metadata
+func writeVersion(w io.Writer, asJSON bool) error {
+	if !asJSON {
+		_, err := fmt.Fprintf(w, "mtcvctm %s (commit: %s)\n", Version, Commit)
+		return err
+	}
+
+	data, err := json.MarshalIndent(versionInfo{
+		Version:   Version,
+		Commit:    Commit,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal version: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
 // exitWithError prints an error and exits
 func exitWithError(err error) {
 	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
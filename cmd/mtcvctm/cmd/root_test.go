@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWriteVersion(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeVersion(&buf, false); err != nil {
+		t.Fatalf("writeVersion() error = %v", err)
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "mtcvctm "+Version) || !strings.Contains(got, "commit: "+Commit) {
+		t.Errorf("writeVersion() = %q, want human-readable version line", got)
+	}
+}
+
+func TestWriteVersion_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeVersion(&buf, true); err != nil {
+		t.Fatalf("writeVersion() error = %v", err)
+	}
+
+	var info map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &info); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if info["version"] != Version {
+		t.Errorf("version = %q, want %q", info["version"], Version)
+	}
+	if info["commit"] != Commit {
+		t.Errorf("commit = %q, want %q", info["commit"], Commit)
+	}
+	if info["go_version"] != runtime.Version() || info["os"] != runtime.GOOS || info["arch"] != runtime.GOARCH {
+		t.Errorf("runtime fields = %v", info)
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/vctm"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <file|dir>",
+	Short: "Validate existing VCTM JSON files",
+	Long: `Validate hand-written or generated VCTM JSON files without regenerating them.
+
+The checks are those of the vctm package: vct is required, integrity members
+need their URI, schema and schema_uri are exclusive, every display and claim
+display entry must have a valid BCP 47 locale, every claim path must be
+non-empty with string, non-negative integer or null elements and every sd
+value must be one of always, allowed or never. All violations are reported
+with JSON pointers into the file and the command exits non-zero if any are
+found.
+
+A directory is searched recursively for *.vctm.json, vctm_*.json and
+vctm-*.json files.
+
+Example:
+  mtcvctm validate identity.vctm.json
+  mtcvctm validate ./dist`,
+	Args: cobra.ExactArgs(1),
+	RunE: runValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+// validationViolation is a single problem found in a VCTM file
+type validationViolation struct {
+	Pointer string
+	Message string
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	return validateVCTMPath(os.Stdout, args[0])
+}
+
+// validateVCTMPath validates a VCTM file or all VCTM files below a directory,
+// writing one line per violation
+func validateVCTMPath(w io.Writer, target string) error {
+	info, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("failed to access %s: %w", target, err)
+	}
+
+	files := []string{target}
+	root := filepath.Dir(target)
+	if info.IsDir() {
+		root = target
+		files, err = findVCTMFiles(target)
+		if err != nil {
+			return fmt.Errorf("failed to find VCTM files: %w", err)
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no VCTM files found in %s", target)
+		}
+	}
+
+	total, invalid := 0, 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		relPath, err := filepath.Rel(root, file)
+		if err != nil {
+			relPath = file
+		}
+
+		violations := validateVCTMData(data)
+		for _, v := range violations {
+			fmt.Fprintf(w, "%s#%s: %s\n", relPath, v.Pointer, v.Message)
+		}
+		if len(violations) > 0 {
+			invalid++
+			total += len(violations)
+		}
+	}
+
+	if total > 0 {
+		return fmt.Errorf("validation failed: %d violation(s) in %d of %d file(s)", total, invalid, len(files))
+	}
+
+	fmt.Fprintf(w, "%d file(s) valid\n", len(files))
+	return nil
+}
+
+// validateVCTMData returns all violations in a VCTM JSON document
+func validateVCTMData(data []byte) []validationViolation {
+	v, err := vctm.FromJSON(data)
+	if err != nil {
+		// FromJSON also rejects documents failing VCTM.Validate; load those
+		// anyway so that every violation is reported, not just the first
+		v = &vctm.VCTM{}
+		if jsonErr := json.Unmarshal(data, v); jsonErr != nil {
+			return []validationViolation{{Pointer: "", Message: err.Error()}}
+		}
+	}
+
+	var violations []validationViolation
+	add := func(pointer, format string, args ...interface{}) {
+		violations = append(violations, validationViolation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, err := range v.ValidateMembers() {
+		add("/"+err.Member, "%v", err.Err)
+	}
+
+	for i, display := range v.Display {
+		if err := vctm.ValidateLocale(display.Locale); err != nil {
+			add(fmt.Sprintf("/display/%d/locale", i), "%v", err)
+		}
+	}
+
+	for i, claim := range v.Claims {
+		if len(claim.Path) == 0 {
+			add(fmt.Sprintf("/claims/%d/path", i), "path must not be empty")
+		}
+		for j, element := range claim.Path {
+			if !vctm.IsPathElement(element) {
+				add(fmt.Sprintf("/claims/%d/path/%d", i, j), "path element %v must be a string, a non-negative integer or null", element)
+			}
+		}
+		if err := vctm.ValidateSD(claim.SD); err != nil {
+			add(fmt.Sprintf("/claims/%d/sd", i), "%v", err)
+		}
+		for j, display := range claim.Display {
+			if err := vctm.ValidateLocale(display.Locale); err != nil {
+				add(fmt.Sprintf("/claims/%d/display/%d/locale", i, j), "%v", err)
+			}
+		}
+	}
+
+	return violations
+}
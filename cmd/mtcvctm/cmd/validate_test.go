@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateVCTMData(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		wantPointers []string
+	}{
+		{
+			name: "valid",
+			data: `{"vct":"https://example.com/pid","display":[{"locale":"en-US","name":"PID"}],"claims":[{"path":["given_name"],"sd":"always"}]}`,
+		},
+		{
+			name:         "invalid json",
+			data:         `{"vct":`,
+			wantPointers: []string{""},
+		},
+		{
+			name: "all violations reported",
+			data: `{"display":[{"name":"PID"},{"locale":"en_US"}],"claims":[{"path":[],"sd":"sometimes"},{"path":["age"],"display":[{"label":"Age"}]}]}`,
+			wantPointers: []string{
+				"/vct",
+				"/display/0/locale",
+				"/display/1/locale",
+				"/claims/0/path",
+				"/claims/0/sd",
+				"/claims/1/display/0/locale",
+			},
+		},
+		{
+			name: "bad path element with other violations",
+			data: `{"vct":"x","claims":[{"path":[true],"sd":"bogus"}]}`,
+			wantPointers: []string{
+				"/claims/0/path/0",
+				"/claims/0/sd",
+			},
+		},
+		{
+			name: "member and claim display rules",
+			data: `{"vct":"x","schema":{"type":"object"},"schema_uri":"https://example.com/s","extends#integrity":"sha256-x","claims":[{"path":["age"],"display":[{"locale":"en_US"}]}]}`,
+			wantPointers: []string{
+				"/extends#integrity",
+				"/schema_uri",
+				"/claims/0/display/0/locale",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := validateVCTMData([]byte(tt.data))
+			if len(violations) != len(tt.wantPointers) {
+				t.Fatalf("violations = %+v, want pointers %v", violations, tt.wantPointers)
+			}
+			for i, want := range tt.wantPointers {
+				if violations[i].Pointer != want {
+					t.Errorf("violations[%d].Pointer = %q, want %q", i, violations[i].Pointer, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateVCTMPath_Directory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"pid.vctm.json":          `{"vct":"https://example.com/pid","display":[{"locale":"en-US","name":"PID"}]}`,
+		"nested/bad.vctm.json":   `{"vct":"https://example.com/bad","claims":[{"path":["x"],"sd":"maybe"}]}`,
+		"nested/ignored.json":    `{"not":"a vctm"}`,
+		"nested/vctm-ok.json":    `{"vct":"https://example.com/ok"}`,
+		"nested/vctm_empty.json": `{}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	err := validateVCTMPath(&out, dir)
+	if err == nil {
+		t.Fatal("expected an error for invalid files")
+	}
+	if !strings.Contains(err.Error(), "2 violation(s) in 2 of 4 file(s)") {
+		t.Errorf("error = %v, want summary of 2 violations in 2 of 4 files", err)
+	}
+
+	report := out.String()
+	for _, want := range []string{
+		filepath.Join("nested", "bad.vctm.json") + "#/claims/0/sd: invalid sd \"maybe\"",
+		filepath.Join("nested", "vctm_empty.json") + "#/vct: vct field is required",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestValidateVCTMPath_File(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pid.vctm.json")
+	if err := os.WriteFile(path, []byte(`{"vct":"https://example.com/pid"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := validateVCTMPath(&out, path); err != nil {
+		t.Fatalf("validateVCTMPath() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "1 file(s) valid") {
+		t.Errorf("output = %q", out.String())
+	}
+}
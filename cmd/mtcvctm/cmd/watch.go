@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/parser"
+)
+
+// watchDebounce is how long to wait after the last change before regenerating
+const watchDebounce = 200 * time.Millisecond
+
+// fileStamp identifies the content of a watched file. Content is compared rather
+// than modification times so that rewriting identical outputs, e.g. images copied
+// onto themselves when the output directory is the input directory, does not
+// trigger another regeneration.
+type fileStamp struct {
+	exists bool
+	sum    [sha256.Size]byte
+}
+
+func statFile(path string) fileStamp {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, sum: sha256.Sum256(data)}
+}
+
+// runWatch runs regenerate once and then again whenever one of the files returned
+// by targets changes, until interrupted with Ctrl-C
+func runWatch(w io.Writer, targets func() []string, regenerate func() error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	reportRegeneration(w, regenerate())
+	return watchAndRegenerate(ctx, w, targets, regenerate)
+}
+
+// watchAndRegenerate watches the files returned by targets and calls regenerate,
+// debounced, whenever one of them changes. Directories in targets are watched for
+// new files. Regeneration errors are reported and do not stop the watcher; it
+// returns nil when ctx is cancelled.
+func watchAndRegenerate(ctx context.Context, w io.Writer, targets func() []string, regenerate func() error) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Files are compared with their content when the last run started, so that
+	// edits made while regenerating are not lost. Targets that appear during a
+	// run are recorded as they are after it.
+	stamps := make(map[string]fileStamp)
+	refresh := func(restamp bool) {
+		previous := stamps
+		stamps = make(map[string]fileStamp)
+		for _, path := range targets() {
+			dir := filepath.Dir(path)
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				dir = path
+			} else if stamp, ok := previous[path]; ok && !restamp {
+				stamps[path] = stamp
+			} else {
+				stamps[path] = statFile(path)
+			}
+			// Adding an already watched directory is a no-op
+			if err := watcher.Add(dir); err != nil {
+				fmt.Fprintf(w, "Warning: cannot watch %s: %v\n", dir, err)
+			}
+		}
+	}
+	refresh(true)
+	fmt.Fprintf(w, "Watching %d file(s) for changes, press Ctrl-C to stop\n", len(stamps))
+
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			name := filepath.Clean(event.Name)
+			stamp, watched := stamps[name]
+			if !watched {
+				// A new file may have become a target, e.g. a new markdown file
+				refresh(false)
+				stamp, watched = stamps[name]
+				if watched {
+					stamp = fileStamp{}
+				}
+			}
+			if watched && statFile(name) != stamp {
+				timer.Reset(watchDebounce)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(w, "Warning: file watcher: %v\n", err)
+
+		case <-timer.C:
+			refresh(true)
+			reportRegeneration(w, regenerate())
+			refresh(false)
+		}
+	}
+}
+
+// reportRegeneration prints a timestamped line for the outcome of a regeneration
+func reportRegeneration(w io.Writer, err error) {
+	now := time.Now().Format("15:04:05")
+	if err != nil {
+		fmt.Fprintf(w, "[%s] Error: %v\n", now, err)
+		return
+	}
+	fmt.Fprintf(w, "[%s] Output written\n", now)
+}
+
+// credentialWatchTargets returns the markdown file and the local images it references
+func credentialWatchTargets(inputFile string) []string {
+	files := []string{filepath.Clean(inputFile)}
+
+	cfg := config.DefaultConfig()
+	cfg.InputFile = inputFile
+	cred, err := parser.NewParser(cfg).ParseToCredential(inputFile)
+	if err != nil {
+		return files
+	}
+
+	for _, img := range cred.Images {
+		if img.AbsolutePath != "" && !strings.HasPrefix(img.Path, "http") {
+			files = append(files, filepath.Clean(img.AbsolutePath))
+		}
+	}
+	if cred.LogoAbsPath != "" {
+		files = append(files, filepath.Clean(cred.LogoAbsPath))
+	}
+	if cred.SVGTemplatePath != "" && !strings.HasPrefix(cred.SVGTemplatePath, "http") {
+		files = append(files, filepath.Clean(filepath.Join(cred.SourceDir, cred.SVGTemplatePath)))
+	}
+
+	return files
+}
+
+// batchWatchTargets returns the input directory, its markdown files and their images
+func batchWatchTargets(inputDir string) []string {
+	targets := []string{filepath.Clean(inputDir)}
+
+	mdFiles, err := findMarkdownFiles(inputDir)
+	if err != nil {
+		return targets
+	}
+	for _, mdFile := range mdFiles {
+		targets = append(targets, filepath.Dir(mdFile))
+		targets = append(targets, credentialWatchTargets(mdFile)...)
+	}
+
+	return targets
+}
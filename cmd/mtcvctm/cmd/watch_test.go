@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for use by the watcher goroutine and the test
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchAndRegenerate(t *testing.T) {
+	dir := t.TempDir()
+	mdFile := writeTestMarkdown(t, dir, "identity.md", "# Identity\n\n![Logo](logo.png)\n")
+	logo := filepath.Join(dir, "logo.png")
+	if err := os.WriteFile(logo, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runs := make(chan struct{}, 10)
+	calls := 0
+	regenerate := func() error {
+		calls++
+		runs <- struct{}{}
+		// Outputs written next to the input and inputs rewritten unchanged, as
+		// when images are copied onto themselves, must not trigger another run
+		if err := os.WriteFile(filepath.Join(dir, "identity.vctm"), []byte(time.Now().String()), 0644); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(logo)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(logo, data, 0644); err != nil {
+			return err
+		}
+		if calls == 1 {
+			return errors.New("broken claim")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- watchAndRegenerate(ctx, out, func() []string {
+			return credentialWatchTargets(mdFile)
+		}, regenerate)
+	}()
+
+	waitForRun := func(what string) {
+		t.Helper()
+		select {
+		case <-runs:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no regeneration after %s, output:\n%s", what, out.String())
+		}
+	}
+	waitForOutput := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.Contains(out.String(), want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("output missing %q:\n%s", want, out.String())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	waitForOutput("Watching 2 file(s)")
+
+	// Rapid writes are debounced into a single run; its error keeps the watcher alive
+	for i := 0; i < 3; i++ {
+		writeTestMarkdown(t, dir, "identity.md", "# Identity "+strings.Repeat("!", i+1)+"\n\n![Logo](logo.png)\n")
+	}
+	waitForRun("markdown change")
+	waitForOutput("Error: broken claim")
+
+	if err := os.WriteFile(logo, []byte("new png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForRun("image change")
+	waitForOutput("Output written")
+
+	select {
+	case <-runs:
+		t.Errorf("unexpected extra regeneration, output:\n%s", out.String())
+	case <-time.After(4 * watchDebounce):
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("watchAndRegenerate() error = %v, want nil after cancel", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher did not stop after cancel")
+	}
+}
+
+func TestBatchWatchTargets(t *testing.T) {
+	dir := t.TempDir()
+	writeTestMarkdown(t, dir, "a.md", "# A\n\n![Logo](img/a.png)\n")
+	writeTestMarkdown(t, dir, "sub/b.md", "# B\n")
+
+	targets := batchWatchTargets(dir)
+	want := []string{
+		dir,
+		filepath.Join(dir, "a.md"),
+		filepath.Join(dir, "img", "a.png"),
+		filepath.Join(dir, "sub"),
+		filepath.Join(dir, "sub", "b.md"),
+	}
+	for _, path := range want {
+		found := false
+		for _, target := range targets {
+			if target == path {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("batchWatchTargets() = %v, missing %s", targets, path)
+		}
+	}
+}
+
+func TestBatch_WatchRejectsGitHubAction(t *testing.T) {
+	err := runBatchWithArgs(t, "--watch", "--github-action")
+	if err == nil || !strings.Contains(err.Error(), "--github-action") {
+		t.Errorf("runBatch() error = %v, want --github-action conflict", err)
+	}
+}
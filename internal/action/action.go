@@ -2,13 +2,18 @@
 package action
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
 )
 
 // RegistryMetadata represents the .well-known/vctm-registry.json structure
@@ -58,6 +63,17 @@ type CredentialEntry struct {
 	// VCTMFile is the path to the generated VCTM file
 	VCTMFile string `json:"vctm_file"`
 
+	// VCTMIntegrity is the SRI integrity hash of the generated VCTM file, for
+	// pinning it with vct#integrity
+	VCTMIntegrity string `json:"vctm_integrity,omitempty"`
+
+	// Files maps each generated format to its file, relative to the output directory
+	Files map[string]string `json:"files,omitempty"`
+
+	// Identifiers maps each generated format to its derived identifier, such as
+	// the vct, mdoc doctype or W3C credential type
+	Identifiers map[string]string `json:"identifiers,omitempty"`
+
 	// LastModified is the timestamp of the last modification
 	LastModified string `json:"last_modified"`
 
@@ -80,131 +96,221 @@ type CommitInfo struct {
 	Date string `json:"date"`
 }
 
+// RegistryOptions controls how the registry file is written
+type RegistryOptions struct {
+	// Compact writes the registry without indentation
+	Compact bool
+
+	// Filename is the registry file relative to the .well-known directory,
+	// possibly nested (default: config.DefaultRegistryFilename)
+	Filename string
+
+	// Version is the version field of the registry (default:
+	// config.DefaultRegistryVersion)
+	Version string
+
+	// GeneratedAt is the generated timestamp of the registry (default: the
+	// SOURCE_DATE_EPOCH environment variable when set, else the current time)
+	GeneratedAt time.Time
+}
+
+// registryTimestamp returns the generated timestamp: GeneratedAt, the Unix time in
+// SOURCE_DATE_EPOCH for reproducible builds, or the current time
+func registryTimestamp(opts RegistryOptions, getenv func(string) string) (string, error) {
+	generated := opts.GeneratedAt
+	if generated.IsZero() {
+		generated = time.Now()
+		if epoch := strings.TrimSpace(getenv("SOURCE_DATE_EPOCH")); epoch != "" {
+			seconds, err := strconv.ParseInt(epoch, 10, 64)
+			if err != nil {
+				return "", fmt.Errorf("action: invalid SOURCE_DATE_EPOCH %q: %w", epoch, err)
+			}
+			generated = time.Unix(seconds, 0)
+		}
+	}
+	return generated.UTC().Format(time.RFC3339), nil
+}
+
+// RegistryPath returns the path of the registry file in outputDir, filename being
+// relative to the .well-known directory ("" for the default)
+func RegistryPath(outputDir, filename string) string {
+	if filename == "" {
+		filename = config.DefaultRegistryFilename
+	}
+	return filepath.Join(outputDir, ".well-known", filename)
+}
+
 // GenerateRegistry generates the vctm-registry.json file
 func GenerateRegistry(outputDir string, credentials []CredentialEntry) error {
+	return GenerateRegistryWithOptions(outputDir, credentials, RegistryOptions{})
+}
+
+// GenerateRegistryWithOptions generates the registry file at opts.Filename below
+// .well-known. The entries are encoded one at a time in the order given, without
+// marshaling the whole registry into a single buffer first
+func GenerateRegistryWithOptions(outputDir string, credentials []CredentialEntry, opts RegistryOptions) error {
+	if err := config.ValidateRegistryFilename(opts.Filename); err != nil {
+		return fmt.Errorf("action: %w", err)
+	}
+	version := opts.Version
+	if version == "" {
+		version = config.DefaultRegistryVersion
+	}
+	generated, err := registryTimestamp(opts, os.Getenv)
+	if err != nil {
+		return err
+	}
+
 	registry := &RegistryMetadata{
-		Version:     "1.0",
-		Generated:   time.Now().UTC().Format(time.RFC3339),
-		Repository:  getRepositoryInfo(),
+		Version:     version,
+		Generated:   generated,
+		Repository:  GetRepositoryInfo(),
 		Credentials: credentials,
 	}
 
-	// Create .well-known directory
-	wellKnownDir := filepath.Join(outputDir, ".well-known")
-	if err := os.MkdirAll(wellKnownDir, 0755); err != nil {
+	// Create .well-known directory, and the directories of nested filenames
+	registryPath := RegistryPath(outputDir, opts.Filename)
+	if err := os.MkdirAll(filepath.Dir(registryPath), 0755); err != nil {
 		return fmt.Errorf("action: failed to create .well-known directory: %w", err)
 	}
 
 	// Write registry file
-	registryPath := filepath.Join(wellKnownDir, "vctm-registry.json")
-	data, err := json.MarshalIndent(registry, "", "  ")
+	file, err := atomicfile.Create(registryPath, 0644)
 	if err != nil {
-		return fmt.Errorf("action: failed to serialize registry: %w", err)
+		return fmt.Errorf("action: failed to write registry file: %w", err)
 	}
+	defer file.Close()
 
-	if err := os.WriteFile(registryPath, data, 0644); err != nil {
+	w := bufio.NewWriter(file)
+	if err := writeRegistry(w, registry, opts); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
 		return fmt.Errorf("action: failed to write registry file: %w", err)
 	}
 
-	return nil
+	return file.Commit()
 }
 
-// getRepositoryInfo extracts repository information from git and environment
-func getRepositoryInfo() RepositoryInfo {
-	info := RepositoryInfo{}
-
-	// Try to get info from GitHub environment variables first
-	if repo := os.Getenv("GITHUB_REPOSITORY"); repo != "" {
-		parts := strings.SplitN(repo, "/", 2)
-		if len(parts) == 2 {
-			info.Owner = parts[0]
-			info.Name = parts[1]
+// writeRegistry encodes the registry incrementally. The indented form is byte-for-byte
+// identical to json.MarshalIndent with two-space indentation.
+func writeRegistry(w io.Writer, registry *RegistryMetadata, opts RegistryOptions) error {
+	marshal := func(v interface{}, prefix string) ([]byte, error) {
+		if opts.Compact {
+			return json.Marshal(v)
 		}
-		info.URL = "https://github.com/" + repo
+		return json.MarshalIndent(v, prefix, "  ")
 	}
 
-	if ref := os.Getenv("GITHUB_REF_NAME"); ref != "" {
-		info.Branch = ref
+	nl, indent, sep := "\n", "  ", " "
+	if opts.Compact {
+		nl, indent, sep = "", "", ""
 	}
 
-	if sha := os.Getenv("GITHUB_SHA"); sha != "" {
-		info.Commit = sha
+	fields := []struct {
+		key   string
+		value interface{}
+	}{
+		{"version", registry.Version},
+		{"generated", registry.Generated},
+		{"repository", registry.Repository},
 	}
 
-	// Fall back to git commands if environment variables are not set
-	if info.URL == "" {
-		if url, err := runGitCommand("config", "--get", "remote.origin.url"); err == nil {
-			info.URL = strings.TrimSpace(url)
+	var err error
+	write := func(s string) {
+		if err == nil {
+			_, err = io.WriteString(w, s)
 		}
 	}
 
-	if info.Branch == "" {
-		if branch, err := runGitCommand("rev-parse", "--abbrev-ref", "HEAD"); err == nil {
-			info.Branch = strings.TrimSpace(branch)
+	write("{" + nl)
+	for _, field := range fields {
+		data, marshalErr := marshal(field.value, indent)
+		if marshalErr != nil {
+			return fmt.Errorf("action: failed to serialize registry: %w", marshalErr)
 		}
+		write(indent + `"` + field.key + `":` + sep + string(data) + "," + nl)
 	}
 
-	if info.Commit == "" {
-		if commit, err := runGitCommand("rev-parse", "HEAD"); err == nil {
-			info.Commit = strings.TrimSpace(commit)
-		}
+	if len(registry.Credentials) == 0 {
+		write(indent + `"credentials":` + sep + "[]" + nl + "}")
+		return wrapRegistryWriteErr(err)
 	}
 
-	// Extract owner and name from URL if not set
-	if info.Owner == "" || info.Name == "" {
-		info.Owner, info.Name = parseRepoURL(info.URL)
+	write(indent + `"credentials":` + sep + "[" + nl)
+	for i, entry := range registry.Credentials {
+		data, marshalErr := marshal(entry, indent+indent)
+		if marshalErr != nil {
+			return fmt.Errorf("action: failed to serialize registry entry %s: %w", entry.SourceFile, marshalErr)
+		}
+		write(indent + indent + string(data))
+		if i < len(registry.Credentials)-1 {
+			write(",")
+		}
+		write(nl)
 	}
+	write(indent + "]" + nl + "}")
 
-	return info
+	return wrapRegistryWriteErr(err)
 }
 
-// GetFileCommitHistory returns the commit history for a file
-func GetFileCommitHistory(filePath string, limit int) []CommitInfo {
-	var commits []CommitInfo
-
-	// git log --format="%H|%s|%an|%aI" -n 5 -- filepath
-	format := "%H|%s|%an|%aI"
-	output, err := runGitCommand("log", fmt.Sprintf("--format=%s", format), fmt.Sprintf("-n%d", limit), "--", filePath)
+// wrapRegistryWriteErr wraps a registry write error, if any
+func wrapRegistryWriteErr(err error) error {
 	if err != nil {
-		return commits
+		return fmt.Errorf("action: failed to write registry file: %w", err)
+	}
+	return nil
+}
+
+// splitRepoPath splits an owner/name repository path. GitLab projects can sit in
+// nested groups, so the owner is everything before the last slash.
+func splitRepoPath(path string) (owner, name string) {
+	if i := strings.LastIndex(path, "/"); i > 0 {
+		return path[:i], path[i+1:]
 	}
+	return "", ""
+}
 
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, "|", 4)
-		if len(parts) == 4 {
-			commits = append(commits, CommitInfo{
-				SHA:     parts[0],
-				Message: parts[1],
-				Author:  parts[2],
-				Date:    parts[3],
-			})
+// ciRepositoryInfo reads repository information from the variables of the first
+// CI provider with any of them set, returning the provider name ("github",
+// "gitlab" or "bitbucket"), or "" when none is detected
+func ciRepositoryInfo(getenv func(string) string) (RepositoryInfo, string) {
+	var info RepositoryInfo
+
+	if repo, ref, sha := getenv("GITHUB_REPOSITORY"), getenv("GITHUB_REF_NAME"), getenv("GITHUB_SHA"); repo != "" || ref != "" || sha != "" {
+		if repo != "" {
+			info.Owner, info.Name = splitRepoPath(repo)
+			info.URL = "https://github.com/" + repo
 		}
+		info.Branch, info.Commit = ref, sha
+		return info, "github"
 	}
 
-	return commits
-}
-
-// GetFileLastModified returns the last modification time of a file from git
-func GetFileLastModified(filePath string) string {
-	output, err := runGitCommand("log", "-1", "--format=%aI", "--", filePath)
-	if err != nil {
-		return time.Now().UTC().Format(time.RFC3339)
+	if url, path, ref, sha := getenv("CI_PROJECT_URL"), getenv("CI_PROJECT_PATH"), getenv("CI_COMMIT_REF_NAME"), getenv("CI_COMMIT_SHA"); url != "" || path != "" || ref != "" || sha != "" {
+		info.URL = url
+		info.Owner, info.Name = splitRepoPath(path)
+		info.Branch, info.Commit = ref, sha
+		return info, "gitlab"
 	}
-	return strings.TrimSpace(output)
-}
 
-// runGitCommand runs a git command and returns the output
-func runGitCommand(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
+	if repo, origin, sha := getenv("BITBUCKET_REPO_FULL_NAME"), getenv("BITBUCKET_GIT_HTTP_ORIGIN"), getenv("BITBUCKET_COMMIT"); repo != "" || origin != "" || sha != "" {
+		info.URL = origin
+		if repo != "" {
+			info.Owner, info.Name = splitRepoPath(repo)
+			if info.URL == "" {
+				info.URL = "https://bitbucket.org/" + repo
+			}
+		}
+		// Tag pipelines have no branch
+		info.Branch = getenv("BITBUCKET_BRANCH")
+		if info.Branch == "" {
+			info.Branch = getenv("BITBUCKET_TAG")
+		}
+		info.Commit = sha
+		return info, "bitbucket"
 	}
-	return string(output), nil
+
+	return info, ""
 }
 
 // parseRepoURL extracts owner and name from a repository URL
@@ -229,36 +335,6 @@ func parseRepoURL(url string) (owner, name string) {
 	return
 }
 
-// SetupVCTMBranch sets up the vctm branch for GitHub Actions
-func SetupVCTMBranch(branchName string, outputDir string) error {
-	// Save output directory contents to temp location before switching branches
-	tempDir, err := os.MkdirTemp("", "vctm-output-*")
-	if err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	// Copy output directory to temp
-	if err := copyDir(outputDir, tempDir); err != nil {
-		return fmt.Errorf("failed to save output directory: %w", err)
-	}
-
-	// Configure git
-	runGitCommand("config", "--local", "user.email", "action@github.com")
-	runGitCommand("config", "--local", "user.name", "GitHub Action")
-
-	// Create orphan branch (or reset if exists)
-	runGitCommand("checkout", "--orphan", branchName)
-	runGitCommand("rm", "-rf", ".")
-
-	// Copy output contents to repo root (not in outputDir subdirectory)
-	if err := copyDir(tempDir, "."); err != nil {
-		return fmt.Errorf("failed to restore output directory: %w", err)
-	}
-
-	return nil
-}
-
 // copyDir copies a directory recursively
 func copyDir(src, dst string) error {
 	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
@@ -280,28 +356,6 @@ func copyDir(src, dst string) error {
 		if err != nil {
 			return err
 		}
-		return os.WriteFile(dstPath, data, info.Mode())
+		return atomicfile.WriteFile(dstPath, data, info.Mode())
 	})
 }
-
-// CommitAndPush commits changes and pushes to remote
-func CommitAndPush(message string, branchName string) error {
-	// Add all files
-	if _, err := runGitCommand("add", "-A"); err != nil {
-		return fmt.Errorf("failed to stage files: %w", err)
-	}
-
-	// Commit
-	if _, err := runGitCommand("commit", "-m", message); err != nil {
-		// May fail if no changes, which is OK
-		fmt.Println("No changes to commit")
-		return nil
-	}
-
-	// Force push (since we're using orphan branch)
-	if _, err := runGitCommand("push", "origin", branchName, "--force"); err != nil {
-		return fmt.Errorf("failed to push: %w", err)
-	}
-
-	return nil
-}
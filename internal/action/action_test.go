@@ -1,10 +1,14 @@
 package action
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseRepoURL(t *testing.T) {
@@ -100,6 +104,177 @@ func TestGenerateRegistry(t *testing.T) {
 	}
 }
 
+func TestGenerateRegistryWithOptions_FilenameAndVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	credentials := []CredentialEntry{{VCT: "https://example.com/credentials/identity", Name: "Identity", VCTMFile: "identity.vctm"}}
+
+	opts := RegistryOptions{Filename: "registries/vctm-v2.json", Version: "2.0"}
+	if err := GenerateRegistryWithOptions(tmpDir, credentials, opts); err != nil {
+		t.Fatalf("GenerateRegistryWithOptions() error = %v", err)
+	}
+
+	registryPath := RegistryPath(tmpDir, opts.Filename)
+	if want := filepath.Join(tmpDir, ".well-known", "registries", "vctm-v2.json"); registryPath != want {
+		t.Errorf("RegistryPath() = %q, want %q", registryPath, want)
+	}
+	data, err := os.ReadFile(registryPath)
+	if err != nil {
+		t.Fatalf("registry not written to the custom filename: %v", err)
+	}
+	var registry RegistryMetadata
+	if err := json.Unmarshal(data, &registry); err != nil {
+		t.Fatalf("registry is not valid JSON: %v", err)
+	}
+	if registry.Version != "2.0" {
+		t.Errorf("version = %q, want 2.0", registry.Version)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, ".well-known", "vctm-registry.json")); !os.IsNotExist(err) {
+		t.Errorf("default registry file should not be written, stat error = %v", err)
+	}
+
+	for _, filename := range []string{"../registry.json", "/etc/registry.json"} {
+		if err := GenerateRegistryWithOptions(tmpDir, credentials, RegistryOptions{Filename: filename}); err == nil {
+			t.Errorf("GenerateRegistryWithOptions(%q) expected error", filename)
+		}
+	}
+}
+
+func TestWriteRegistry_MatchesMarshalIndent(t *testing.T) {
+	registry := &RegistryMetadata{
+		Version:    "1.0",
+		Generated:  "2024-01-15T10:00:00Z",
+		Repository: RepositoryInfo{Owner: "owner", Name: "repo"},
+		Credentials: []CredentialEntry{
+			{VCT: "https://example.com/a", Name: "A & <B>", SourceFile: "a.md", VCTMFile: "a.vctm"},
+			{VCT: "https://example.com/b", Name: "B", SourceFile: "b.md", VCTMFile: "b.vctm",
+				CommitHistory: []CommitInfo{{SHA: "abc", Message: "Initial", Author: "dev", Date: "2024-01-01"}}},
+		},
+	}
+
+	for _, compact := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := writeRegistry(&buf, registry, RegistryOptions{Compact: compact}); err != nil {
+			t.Fatalf("writeRegistry() error = %v", err)
+		}
+
+		var want []byte
+		if compact {
+			want, _ = json.Marshal(registry)
+		} else {
+			want, _ = json.MarshalIndent(registry, "", "  ")
+		}
+		if buf.String() != string(want) {
+			t.Errorf("writeRegistry(compact=%v) =\n%s\nwant\n%s", compact, buf.String(), want)
+		}
+	}
+
+	// An empty registry still has a credentials array
+	var buf bytes.Buffer
+	empty := &RegistryMetadata{Version: "1.0"}
+	if err := writeRegistry(&buf, empty, RegistryOptions{}); err != nil {
+		t.Fatalf("writeRegistry() error = %v", err)
+	}
+	var parsed RegistryMetadata
+	if err := json.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("empty registry is not valid JSON: %v", err)
+	}
+	if parsed.Credentials == nil {
+		t.Error("empty registry should contain an empty credentials array")
+	}
+}
+
+func TestGenerateRegistryWithOptions_Large(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	const count = 5000
+	credentials := make([]CredentialEntry, count)
+	for i := range credentials {
+		credentials[i] = CredentialEntry{
+			VCT:        fmt.Sprintf("https://example.com/credentials/%d", i),
+			Name:       fmt.Sprintf("Credential %d", i),
+			SourceFile: fmt.Sprintf("cred-%d.md", i),
+			VCTMFile:   fmt.Sprintf("cred-%d.vctm", i),
+		}
+	}
+
+	for _, compact := range []bool{false, true} {
+		if err := GenerateRegistryWithOptions(tmpDir, credentials, RegistryOptions{Compact: compact}); err != nil {
+			t.Fatalf("GenerateRegistryWithOptions() error = %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(tmpDir, ".well-known", "vctm-registry.json"))
+		if err != nil {
+			t.Fatalf("Failed to read registry file: %v", err)
+		}
+		if compact && bytes.Contains(data, []byte("\n")) {
+			t.Error("compact registry should not contain newlines")
+		}
+
+		var parsed RegistryMetadata
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			t.Fatalf("registry (compact=%v) is not valid JSON: %v", compact, err)
+		}
+		if len(parsed.Credentials) != count {
+			t.Fatalf("len(Credentials) = %d, want %d", len(parsed.Credentials), count)
+		}
+		for i, entry := range parsed.Credentials {
+			if entry.VCT != credentials[i].VCT || entry.SourceFile != credentials[i].SourceFile {
+				t.Fatalf("Credentials[%d] = %+v, want %+v", i, entry, credentials[i])
+			}
+		}
+	}
+}
+
+func TestGenerateRegistryWithOptions_SourceDateEpoch(t *testing.T) {
+	credentials := []CredentialEntry{{VCT: "https://example.com/credentials/identity", Name: "Identity", VCTMFile: "identity.vctm"}}
+	registryPath := func(dir string) string {
+		return filepath.Join(dir, ".well-known", "vctm-registry.json")
+	}
+	readGenerated := func(dir string) string {
+		t.Helper()
+		data, err := os.ReadFile(registryPath(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var registry RegistryMetadata
+		if err := json.Unmarshal(data, &registry); err != nil {
+			t.Fatalf("registry is not valid JSON: %v", err)
+		}
+		return registry.Generated
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	first, second := t.TempDir(), t.TempDir()
+	for _, dir := range []string{first, second} {
+		if err := GenerateRegistryWithOptions(dir, credentials, RegistryOptions{}); err != nil {
+			t.Fatalf("GenerateRegistryWithOptions() error = %v", err)
+		}
+	}
+	if got := readGenerated(first); got != "2023-11-14T22:13:20Z" {
+		t.Errorf("generated = %q, want the SOURCE_DATE_EPOCH time", got)
+	}
+	a, _ := os.ReadFile(registryPath(first))
+	b, _ := os.ReadFile(registryPath(second))
+	if !bytes.Equal(a, b) {
+		t.Error("registries generated with the same SOURCE_DATE_EPOCH differ")
+	}
+
+	// An explicit timestamp takes precedence over the environment
+	dir := t.TempDir()
+	opts := RegistryOptions{GeneratedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))}
+	if err := GenerateRegistryWithOptions(dir, credentials, opts); err != nil {
+		t.Fatalf("GenerateRegistryWithOptions() error = %v", err)
+	}
+	if got := readGenerated(dir); got != "2024-05-01T10:00:00Z" {
+		t.Errorf("generated = %q, want GeneratedAt in UTC", got)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	if err := GenerateRegistryWithOptions(t.TempDir(), credentials, RegistryOptions{}); err == nil || !strings.Contains(err.Error(), "SOURCE_DATE_EPOCH") {
+		t.Errorf("GenerateRegistryWithOptions() with invalid SOURCE_DATE_EPOCH error = %v", err)
+	}
+}
+
 func TestGetRepositoryInfo_FromEnv(t *testing.T) {
 	// Set up test environment
 	originalRepo := os.Getenv("GITHUB_REPOSITORY")
@@ -116,7 +291,7 @@ func TestGetRepositoryInfo_FromEnv(t *testing.T) {
 		os.Setenv("GITHUB_SHA", originalSha)
 	}()
 
-	info := getRepositoryInfo()
+	info := GetRepositoryInfo()
 
 	if info.Owner != "testowner" {
 		t.Errorf("Owner = %q, want testowner", info.Owner)
@@ -132,6 +307,103 @@ func TestGetRepositoryInfo_FromEnv(t *testing.T) {
 	}
 }
 
+func TestCIRepositoryInfo(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantProvider string
+		want         RepositoryInfo
+	}{
+		{
+			name: "gitlab",
+			env: map[string]string{
+				"CI_PROJECT_URL":     "https://gitlab.example.com/org/credentials/registry",
+				"CI_PROJECT_PATH":    "org/credentials/registry",
+				"CI_COMMIT_REF_NAME": "main",
+				"CI_COMMIT_SHA":      "abc123",
+			},
+			wantProvider: "gitlab",
+			want: RepositoryInfo{
+				URL:    "https://gitlab.example.com/org/credentials/registry",
+				Owner:  "org/credentials",
+				Name:   "registry",
+				Branch: "main",
+				Commit: "abc123",
+			},
+		},
+		{
+			name: "bitbucket tag pipeline",
+			env: map[string]string{
+				"BITBUCKET_REPO_FULL_NAME": "workspace/registry",
+				"BITBUCKET_TAG":            "v1.0.0",
+				"BITBUCKET_COMMIT":         "def456",
+			},
+			wantProvider: "bitbucket",
+			want: RepositoryInfo{
+				URL:    "https://bitbucket.org/workspace/registry",
+				Owner:  "workspace",
+				Name:   "registry",
+				Branch: "v1.0.0",
+				Commit: "def456",
+			},
+		},
+		{
+			name: "github takes precedence",
+			env: map[string]string{
+				"GITHUB_REPOSITORY": "owner/repo",
+				"GITHUB_REF_NAME":   "main",
+				"GITHUB_SHA":        "789abc",
+				"CI_PROJECT_PATH":   "other/project",
+			},
+			wantProvider: "github",
+			want: RepositoryInfo{
+				URL:    "https://github.com/owner/repo",
+				Owner:  "owner",
+				Name:   "repo",
+				Branch: "main",
+				Commit: "789abc",
+			},
+		},
+		{
+			name: "no provider",
+			env:  map[string]string{"HOME": "/root"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, provider := ciRepositoryInfo(func(key string) string { return tt.env[key] })
+			if provider != tt.wantProvider {
+				t.Errorf("provider = %q, want %q", provider, tt.wantProvider)
+			}
+			if info != tt.want {
+				t.Errorf("info = %+v, want %+v", info, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRepositoryInfo_FromGitLabEnv(t *testing.T) {
+	for _, key := range []string{"GITHUB_REPOSITORY", "GITHUB_REF_NAME", "GITHUB_SHA"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("CI_PROJECT_URL", "https://gitlab.com/group/project")
+	t.Setenv("CI_PROJECT_PATH", "group/project")
+	t.Setenv("CI_COMMIT_REF_NAME", "release")
+	t.Setenv("CI_COMMIT_SHA", "0123abcd")
+
+	want := RepositoryInfo{
+		URL:    "https://gitlab.com/group/project",
+		Owner:  "group",
+		Name:   "project",
+		Branch: "release",
+		Commit: "0123abcd",
+	}
+	if info := GetRepositoryInfo(); info != want {
+		t.Errorf("GetRepositoryInfo() = %+v, want %+v", info, want)
+	}
+}
+
 func TestCredentialEntry_JSON(t *testing.T) {
 	entry := CredentialEntry{
 		VCT:          "https://example.com/credential",
@@ -181,7 +453,7 @@ func TestRepositoryInfo_Empty(t *testing.T) {
 	}()
 
 	// This should not panic
-	info := getRepositoryInfo()
+	info := GetRepositoryInfo()
 	// The function should handle missing env vars gracefully
 	_ = info
 }
@@ -0,0 +1,203 @@
+package action
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// CommandRunner runs git with args and returns its standard output
+type CommandRunner func(args ...string) (string, error)
+
+// Git runs the git commands of the action through Run, so that tests can replace
+// git with a stub
+type Git struct {
+	// Run executes a git command (default: the git binary on PATH)
+	Run CommandRunner
+}
+
+// NewGit creates a Git that runs the git binary
+func NewGit() *Git {
+	return &Git{Run: execGitCommand}
+}
+
+// defaultGit backs the package-level functions
+var defaultGit = NewGit()
+
+// execGitCommand runs git with args, including its stderr in the error when it fails
+func execGitCommand(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(bytes.TrimSpace(exitErr.Stderr)) > 0 {
+			return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return string(output), nil
+}
+
+// GetRepositoryInfo extracts repository information from the CI environment
+// (GitHub Actions, GitLab CI or Bitbucket Pipelines), falling back to git
+func GetRepositoryInfo() RepositoryInfo {
+	return defaultGit.RepositoryInfo()
+}
+
+// RepositoryInfo extracts repository information from the CI environment,
+// filling what it does not provide from the git checkout
+func (g *Git) RepositoryInfo() RepositoryInfo {
+	info, _ := ciRepositoryInfo(os.Getenv)
+
+	// Fall back to git commands if environment variables are not set
+	if info.URL == "" {
+		if url, err := g.Run("config", "--get", "remote.origin.url"); err == nil {
+			info.URL = strings.TrimSpace(url)
+		}
+	}
+
+	if info.Branch == "" {
+		if branch, err := g.Run("rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+			info.Branch = strings.TrimSpace(branch)
+		}
+	}
+
+	if info.Commit == "" {
+		if commit, err := g.Run("rev-parse", "HEAD"); err == nil {
+			info.Commit = strings.TrimSpace(commit)
+		}
+	}
+
+	// Extract owner and name from URL if not set
+	if info.Owner == "" || info.Name == "" {
+		info.Owner, info.Name = parseRepoURL(info.URL)
+	}
+
+	return info
+}
+
+// GetFileCommitHistory returns the commit history for a file
+func GetFileCommitHistory(filePath string, limit int) []CommitInfo {
+	return defaultGit.FileCommitHistory(filePath, limit)
+}
+
+// FileCommitHistory returns the last limit commits affecting a file
+func (g *Git) FileCommitHistory(filePath string, limit int) []CommitInfo {
+	var commits []CommitInfo
+
+	// git log --format="%H|%s|%an|%aI" -n 5 -- filepath
+	format := "%H|%s|%an|%aI"
+	output, err := g.Run("log", fmt.Sprintf("--format=%s", format), fmt.Sprintf("-n%d", limit), "--", filePath)
+	if err != nil {
+		return commits
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "|", 4)
+		if len(parts) == 4 {
+			commits = append(commits, CommitInfo{
+				SHA:     parts[0],
+				Message: parts[1],
+				Author:  parts[2],
+				Date:    parts[3],
+			})
+		}
+	}
+
+	return commits
+}
+
+// GetFileLastModified returns the last modification time of a file from git
+func GetFileLastModified(filePath string) string {
+	return defaultGit.FileLastModified(filePath)
+}
+
+// FileLastModified returns the author date of the last commit affecting a file,
+// or the current time when git cannot tell
+func (g *Git) FileLastModified(filePath string) string {
+	output, err := g.Run("log", "-1", "--format=%aI", "--", filePath)
+	if err != nil {
+		return time.Now().UTC().Format(time.RFC3339)
+	}
+	return strings.TrimSpace(output)
+}
+
+// SetupVCTMBranch sets up the vctm branch for GitHub Actions
+func SetupVCTMBranch(branchName string, outputDir string) error {
+	return defaultGit.SetupVCTMBranch(branchName, outputDir)
+}
+
+// SetupVCTMBranch switches to an orphan branchName holding only the contents of
+// outputDir, placed at the repository root
+func (g *Git) SetupVCTMBranch(branchName string, outputDir string) error {
+	// Save output directory contents to temp location before switching branches
+	tempDir, err := os.MkdirTemp("", "vctm-output-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Copy output directory to temp
+	if err := copyDir(outputDir, tempDir); err != nil {
+		return fmt.Errorf("failed to save output directory: %w", err)
+	}
+
+	// Configure git
+	g.Run("config", "--local", "user.email", "action@github.com")
+	g.Run("config", "--local", "user.name", "GitHub Action")
+
+	// Create orphan branch (or reset if exists)
+	g.Run("checkout", "--orphan", branchName)
+	g.Run("rm", "-rf", ".")
+
+	// Copy output contents to repo root (not in outputDir subdirectory)
+	if err := copyDir(tempDir, "."); err != nil {
+		return fmt.Errorf("failed to restore output directory: %w", err)
+	}
+
+	return nil
+}
+
+// CommitAndPush commits all changes and force-pushes the branch. A tree without
+// changes is not an error and skips the commit and push.
+func CommitAndPush(message string, branchName string) error {
+	return defaultGit.CommitAndPush(message, branchName)
+}
+
+// CommitAndPush commits all changes and force-pushes branchName
+func (g *Git) CommitAndPush(message string, branchName string) error {
+	// Add all files
+	if _, err := g.Run("add", "-A"); err != nil {
+		return fmt.Errorf("failed to stage files: %w", err)
+	}
+
+	// Nothing staged means the branch is already up to date
+	status, err := g.Run("status", "--porcelain")
+	if err != nil {
+		return fmt.Errorf("failed to check for changes: %w", err)
+	}
+	if strings.TrimSpace(status) == "" {
+		fmt.Println("No changes to commit")
+		return nil
+	}
+
+	// Commit
+	if _, err := g.Run("commit", "-m", message); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	// Force push (since we're using orphan branch)
+	if _, err := g.Run("push", "origin", branchName, "--force"); err != nil {
+		return fmt.Errorf("failed to push: %w", err)
+	}
+
+	return nil
+}
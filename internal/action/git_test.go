@@ -0,0 +1,143 @@
+package action
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubGit returns a Git whose runner answers each git subcommand from responses
+// and records the argument lists it was called with
+func stubGit(responses map[string]stubResponse) (*Git, *[]string) {
+	var calls []string
+	g := &Git{Run: func(args ...string) (string, error) {
+		calls = append(calls, strings.Join(args, " "))
+		r := responses[args[0]]
+		return r.output, r.err
+	}}
+	return g, &calls
+}
+
+// stubResponse is the output or error of a stubbed git subcommand
+type stubResponse struct {
+	output string
+	err    error
+}
+
+func TestGit_CommitAndPush(t *testing.T) {
+	commitErr := errors.New("git commit -m Update: exit status 128: Author identity unknown")
+
+	tests := []struct {
+		name      string
+		responses map[string]stubResponse
+		wantCalls []string
+		wantErr   string
+	}{
+		{
+			name:      "clean tree skips commit and push",
+			responses: map[string]stubResponse{"status": {output: ""}},
+			wantCalls: []string{"add -A", "status --porcelain"},
+		},
+		{
+			name:      "changes are committed and pushed",
+			responses: map[string]stubResponse{"status": {output: "A  identity.vctm.json\n"}},
+			wantCalls: []string{"add -A", "status --porcelain", "commit -m Update", "push origin vctm --force"},
+		},
+		{
+			name: "commit failure is returned",
+			responses: map[string]stubResponse{
+				"status": {output: "M  identity.vctm.json\n"},
+				"commit": {err: commitErr},
+			},
+			wantCalls: []string{"add -A", "status --porcelain", "commit -m Update"},
+			wantErr:   "Author identity unknown",
+		},
+		{
+			name:      "status failure is returned",
+			responses: map[string]stubResponse{"status": {err: errors.New("not a git repository")}},
+			wantCalls: []string{"add -A", "status --porcelain"},
+			wantErr:   "not a git repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, calls := stubGit(tt.responses)
+
+			err := g.CommitAndPush("Update", "vctm")
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("CommitAndPush() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("CommitAndPush() error = %v, want %q", err, tt.wantErr)
+			}
+			if strings.Join(*calls, "; ") != strings.Join(tt.wantCalls, "; ") {
+				t.Errorf("git calls = %q, want %q", *calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGit_SetupVCTMBranch(t *testing.T) {
+	repoDir := t.TempDir()
+	outputDir := filepath.Join(repoDir, "dist")
+	if err := os.MkdirAll(filepath.Join(outputDir, ".well-known"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, ".well-known", "vctm-registry.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(repoDir)
+
+	g, calls := stubGit(nil)
+	if err := g.SetupVCTMBranch("vctm", outputDir); err != nil {
+		t.Fatalf("SetupVCTMBranch() error = %v", err)
+	}
+
+	wantCalls := []string{
+		"config --local user.email action@github.com",
+		"config --local user.name GitHub Action",
+		"checkout --orphan vctm",
+		"rm -rf .",
+	}
+	if strings.Join(*calls, "; ") != strings.Join(wantCalls, "; ") {
+		t.Errorf("git calls = %q, want %q", *calls, wantCalls)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, ".well-known", "vctm-registry.json")); err != nil {
+		t.Errorf("output not copied to the repository root: %v", err)
+	}
+}
+
+func TestGit_FileCommitHistory(t *testing.T) {
+	g, calls := stubGit(map[string]stubResponse{"log": {output: "abc123|Add identity|Jane Doe|2024-01-15T10:00:00Z\nmalformed\n"}})
+
+	commits := g.FileCommitHistory("identity.md", 5)
+	if want := "log --format=%H|%s|%an|%aI -n5 -- identity.md"; len(*calls) != 1 || (*calls)[0] != want {
+		t.Errorf("git calls = %q, want %q", *calls, want)
+	}
+	want := CommitInfo{SHA: "abc123", Message: "Add identity", Author: "Jane Doe", Date: "2024-01-15T10:00:00Z"}
+	if len(commits) != 1 || commits[0] != want {
+		t.Errorf("FileCommitHistory() = %+v, want [%+v]", commits, want)
+	}
+
+	g, _ = stubGit(map[string]stubResponse{"log": {err: errors.New("not a git repository")}})
+	if commits := g.FileCommitHistory("identity.md", 5); len(commits) != 0 {
+		t.Errorf("FileCommitHistory() without git = %+v, want none", commits)
+	}
+}
+
+func TestExecGitCommand_Stderr(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	_, err := execGitCommand("-C", t.TempDir(), "rev-parse", "HEAD")
+	if err == nil {
+		t.Skip("git rev-parse unexpectedly succeeded outside a repository")
+	}
+	if !strings.Contains(err.Error(), "git -C") || !strings.Contains(strings.ToLower(err.Error()), "not a git repository") {
+		t.Errorf("error = %v, want the command and git's stderr", err)
+	}
+}
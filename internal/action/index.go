@@ -0,0 +1,118 @@
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
+)
+
+// indexTemplate renders the registry as an HTML page. html/template escapes every
+// credential and repository field for its context, including the link URLs.
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.8em; text-align: left; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Repository.URL}}
+<p>Source repository: {{if .RepositoryLink}}<a href="{{.RepositoryLink}}">{{.Repository.URL}}</a>{{else}}{{.Repository.URL}}{{end}}{{if .Repository.Commit}} at <code>{{.Repository.Commit}}</code>{{end}}</p>
+{{- end}}
+<p><a href=".well-known/vctm-registry.json">vctm-registry.json</a></p>
+<table>
+<thead>
+<tr><th>Name</th><th>VCT</th><th>Source</th><th>Last modified</th><th>Files</th></tr>
+</thead>
+<tbody>
+{{- range .Credentials}}
+<tr>
+<td>{{.Name}}</td>
+<td><code>{{.VCT}}</code></td>
+<td>{{if .SourceLink}}<a href="{{.SourceLink}}">{{.SourceFile}}</a>{{else}}{{.SourceFile}}{{end}}</td>
+<td>{{.LastModified}}</td>
+<td>{{range $i, $file := .Links}}{{if $i}} {{end}}<a href="{{$file.Path}}">{{$file.Format}}</a>{{end}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+// indexPage is the data rendered by indexTemplate
+type indexPage struct {
+	Title          string
+	Repository     RepositoryInfo
+	RepositoryLink string
+	Credentials    []indexCredential
+}
+
+// indexCredential is a registry entry with its resolved links
+type indexCredential struct {
+	CredentialEntry
+	SourceLink string
+	Links      []indexFile
+}
+
+// indexFile links a generated format file
+type indexFile struct {
+	Format string
+	Path   string
+}
+
+// GenerateIndexHTML writes an index.html to outputDir listing every credential with
+// its name, vct, source file, last modification and links to its format files
+func GenerateIndexHTML(outputDir string, credentials []CredentialEntry, repo RepositoryInfo) error {
+	page := indexPage{
+		Title:      "Credential Type Registry",
+		Repository: repo,
+	}
+	if repo.Owner != "" && repo.Name != "" {
+		page.Title = fmt.Sprintf("Credential Type Registry: %s/%s", repo.Owner, repo.Name)
+	}
+	if strings.HasPrefix(repo.URL, "https://") {
+		page.RepositoryLink = strings.TrimSuffix(repo.URL, ".git")
+	}
+
+	for _, cred := range credentials {
+		entry := indexCredential{CredentialEntry: cred}
+		if page.RepositoryLink != "" && repo.Branch != "" && cred.SourceFile != "" {
+			entry.SourceLink = page.RepositoryLink + "/blob/" + repo.Branch + "/" + filepath.ToSlash(cred.SourceFile)
+		}
+
+		formatNames := make([]string, 0, len(cred.Files))
+		for format := range cred.Files {
+			formatNames = append(formatNames, format)
+		}
+		sort.Strings(formatNames)
+		for _, format := range formatNames {
+			entry.Links = append(entry.Links, indexFile{Format: format, Path: filepath.ToSlash(cred.Files[format])})
+		}
+		if len(entry.Links) == 0 && cred.VCTMFile != "" {
+			entry.Links = []indexFile{{Format: "vctm", Path: cred.VCTMFile}}
+		}
+
+		page.Credentials = append(page.Credentials, entry)
+	}
+
+	var buf bytes.Buffer
+	if err := indexTemplate.Execute(&buf, page); err != nil {
+		return fmt.Errorf("action: failed to render index.html: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(filepath.Join(outputDir, "index.html"), buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("action: failed to write index.html: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateIndexHTML(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	credentials := []CredentialEntry{
+		{
+			VCT:          "https://registry.example.com/identity",
+			Name:         "Identity Credential",
+			SourceFile:   "credentials/identity.md",
+			VCTMFile:     "identity.vctm",
+			LastModified: "2026-01-02T03:04:05Z",
+			Files:        map[string]string{"vctm": "identity.vctm.json", "w3c": "identity.vc.json"},
+		},
+		{
+			VCT:        "https://registry.example.com/diploma",
+			Name:       "Diploma <script>alert(1)</script>",
+			SourceFile: "credentials/diploma.md",
+			VCTMFile:   "diploma.vctm",
+			Files:      map[string]string{"vctm": "diploma.vctm.json"},
+		},
+	}
+	repo := RepositoryInfo{URL: "https://github.com/example/registry.git", Owner: "example", Name: "registry", Branch: "main"}
+
+	if err := GenerateIndexHTML(tmpDir, credentials, repo); err != nil {
+		t.Fatalf("GenerateIndexHTML() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, "index.html"))
+	if err != nil {
+		t.Fatalf("index.html not written: %v", err)
+	}
+	html := string(data)
+
+	for _, want := range []string{
+		"<td>Identity Credential</td>",
+		`<a href="identity.vctm.json">vctm</a> <a href="identity.vc.json">w3c</a>`,
+		`<a href="diploma.vctm.json">vctm</a>`,
+		`<a href="https://github.com/example/registry/blob/main/credentials/identity.md">credentials/identity.md</a>`,
+		"Diploma &lt;script&gt;alert(1)&lt;/script&gt;",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("index.html missing %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("index.html contains an unescaped credential name")
+	}
+}
@@ -0,0 +1,67 @@
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
+)
+
+// IssuerMetadata represents a .well-known/openid-credential-issuer skeleton
+// aggregating the credential configurations of every processed credential
+type IssuerMetadata struct {
+	// CredentialIssuer is the credential issuer identifier
+	CredentialIssuer string `json:"credential_issuer"`
+
+	// CredentialEndpoint is a placeholder credential endpoint derived from the issuer
+	CredentialEndpoint string `json:"credential_endpoint,omitempty"`
+
+	// CredentialConfigurationsSupported maps configuration IDs to credential configurations
+	CredentialConfigurationsSupported map[string]interface{} `json:"credential_configurations_supported"`
+}
+
+// NewIssuerMetadata creates an empty issuer metadata skeleton for the given issuer
+func NewIssuerMetadata(issuer string) *IssuerMetadata {
+	metadata := &IssuerMetadata{
+		CredentialIssuer:                  issuer,
+		CredentialConfigurationsSupported: make(map[string]interface{}),
+	}
+	if issuer != "" {
+		metadata.CredentialEndpoint = strings.TrimSuffix(issuer, "/") + "/credential"
+	}
+	return metadata
+}
+
+// AddConfiguration adds a credential configuration under the given configuration ID
+func (m *IssuerMetadata) AddConfiguration(id string, configuration interface{}) {
+	m.CredentialConfigurationsSupported[id] = configuration
+}
+
+// IssuerConfigurationID derives a credential configuration ID from a credential base name and format
+func IssuerConfigurationID(baseName, formatName string) string {
+	id := strings.ReplaceAll(filepath.ToSlash(baseName), "/", "_")
+	return id + "_" + formatName
+}
+
+// GenerateIssuerMetadata writes the .well-known/openid-credential-issuer file
+func GenerateIssuerMetadata(outputDir string, metadata *IssuerMetadata) error {
+	wellKnownDir := filepath.Join(outputDir, ".well-known")
+	if err := os.MkdirAll(wellKnownDir, 0755); err != nil {
+		return fmt.Errorf("action: failed to create .well-known directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("action: failed to serialize issuer metadata: %w", err)
+	}
+
+	metadataPath := filepath.Join(wellKnownDir, "openid-credential-issuer")
+	if err := atomicfile.WriteFile(metadataPath, data, 0644); err != nil {
+		return fmt.Errorf("action: failed to write issuer metadata: %w", err)
+	}
+
+	return nil
+}
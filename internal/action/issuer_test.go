@@ -0,0 +1,57 @@
+package action
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIssuerConfigurationID(t *testing.T) {
+	tests := []struct {
+		baseName string
+		format   string
+		want     string
+	}{
+		{"identity", "vctm", "identity_vctm"},
+		{"eu/pid", "mddl", "eu_pid_mddl"},
+	}
+
+	for _, tt := range tests {
+		if got := IssuerConfigurationID(tt.baseName, tt.format); got != tt.want {
+			t.Errorf("IssuerConfigurationID(%q, %q) = %q, want %q", tt.baseName, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateIssuerMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	metadata := NewIssuerMetadata("https://issuer.example.com/")
+	metadata.AddConfiguration("identity_vctm", map[string]interface{}{"format": "dc+sd-jwt", "vct": "https://example.com/identity"})
+	metadata.AddConfiguration("diploma_vctm", map[string]interface{}{"format": "dc+sd-jwt", "vct": "https://example.com/diploma"})
+
+	if err := GenerateIssuerMetadata(tmpDir, metadata); err != nil {
+		t.Fatalf("GenerateIssuerMetadata() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, ".well-known", "openid-credential-issuer"))
+	if err != nil {
+		t.Fatalf("Failed to read issuer metadata: %v", err)
+	}
+
+	var parsed IssuerMetadata
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("Issuer metadata is not valid JSON: %v", err)
+	}
+
+	if parsed.CredentialIssuer != "https://issuer.example.com/" {
+		t.Errorf("CredentialIssuer = %q", parsed.CredentialIssuer)
+	}
+	if parsed.CredentialEndpoint != "https://issuer.example.com/credential" {
+		t.Errorf("CredentialEndpoint = %q", parsed.CredentialEndpoint)
+	}
+	if len(parsed.CredentialConfigurationsSupported) != 2 {
+		t.Errorf("len(CredentialConfigurationsSupported) = %d, want 2", len(parsed.CredentialConfigurationsSupported))
+	}
+}
@@ -0,0 +1,126 @@
+package action
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
+)
+
+// LoadSigningKey loads an EC P-256 or RSA private key from a PEM file in PKCS#8,
+// SEC 1 or PKCS#1 form
+func LoadSigningKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("action: failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("action: no PEM data in signing key %s", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("action: unsupported PEM block %q in signing key %s", block.Type, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("action: failed to parse signing key %s: %w", path, err)
+	}
+
+	if _, err := signingAlgorithm(key); err != nil {
+		return nil, fmt.Errorf("action: signing key %s: %w", path, err)
+	}
+	return key.(crypto.Signer), nil
+}
+
+// signingAlgorithm returns the JWS algorithm for a private key: ES256 for P-256
+// keys and RS256 for RSA keys
+func signingAlgorithm(key interface{}) (string, error) {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return "", fmt.Errorf("EC keys must use the P-256 curve for ES256")
+		}
+		return "ES256", nil
+	case *rsa.PrivateKey:
+		return "RS256", nil
+	default:
+		return "", fmt.Errorf("unsupported key type %T (want EC P-256 or RSA)", key)
+	}
+}
+
+// SignRegistry writes <registryPath>.jws, a compact JWS with detached payload over
+// the registry file exactly as it is on disk. The registry writer is
+// deterministic, so the signed bytes are the canonical registry; verifiers
+// base64url-encode the file contents into the empty payload segment.
+func SignRegistry(registryPath string, key crypto.Signer) (string, error) {
+	payload, err := os.ReadFile(registryPath)
+	if err != nil {
+		return "", fmt.Errorf("action: failed to read registry for signing: %w", err)
+	}
+
+	jws, err := signDetached(payload, key)
+	if err != nil {
+		return "", err
+	}
+
+	jwsPath := registryPath + ".jws"
+	if err := atomicfile.WriteFile(jwsPath, []byte(jws), 0644); err != nil {
+		return "", fmt.Errorf("action: failed to write registry signature: %w", err)
+	}
+	return jwsPath, nil
+}
+
+// signDetached returns the compact serialization header..signature of a JWS over
+// payload (RFC 7515, Appendix F)
+func signDetached(payload []byte, key crypto.Signer) (string, error) {
+	alg, err := signingAlgorithm(key)
+	if err != nil {
+		return "", fmt.Errorf("action: %w", err)
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "cty": "json"})
+	if err != nil {
+		return "", fmt.Errorf("action: failed to encode JWS header: %w", err)
+	}
+	enc := base64.RawURLEncoding
+	protected := enc.EncodeToString(header)
+	digest := sha256.Sum256([]byte(protected + "." + enc.EncodeToString(payload)))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			return "", fmt.Errorf("action: failed to sign registry: %w", err)
+		}
+		// JWS uses the fixed-size R || S encoding rather than ASN.1
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			return "", fmt.Errorf("action: failed to sign registry: %w", err)
+		}
+	}
+
+	return protected + ".." + enc.EncodeToString(sig), nil
+}
@@ -0,0 +1,164 @@
+package action
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// verifyDetached checks a compact detached JWS over payload with pub and returns its alg
+func verifyDetached(jws string, payload []byte, pub crypto.PublicKey) (string, error) {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return "", fmt.Errorf("not a compact detached JWS: %q", jws)
+	}
+	enc := base64.RawURLEncoding
+	headerJSON, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return "", err
+	}
+	var header map[string]string
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return "", err
+	}
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + enc.EncodeToString(payload)))
+
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if len(sig) != 64 {
+			return "", fmt.Errorf("ES256 signature length = %d, want 64", len(sig))
+		}
+		if !ecdsa.Verify(k, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+			return "", fmt.Errorf("ES256 signature does not verify")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig); err != nil {
+			return "", err
+		}
+	}
+	return header["alg"], nil
+}
+
+func writeKeyPEM(t *testing.T, dir, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSignRegistry(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8DER, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		blockType string
+		der       []byte
+		pub       crypto.PublicKey
+		wantAlg   string
+	}{
+		{"EC SEC 1", "EC PRIVATE KEY", ecDER, &ecKey.PublicKey, "ES256"},
+		{"RSA PKCS#1", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), &rsaKey.PublicKey, "RS256"},
+		{"RSA PKCS#8", "PRIVATE KEY", pkcs8DER, &rsaKey.PublicKey, "RS256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			credentials := []CredentialEntry{{Name: "Identity", VCT: "https://example.com/identity", VCTMFile: "identity.vctm"}}
+			if err := GenerateRegistry(dir, credentials); err != nil {
+				t.Fatalf("GenerateRegistry() error = %v", err)
+			}
+
+			key, err := LoadSigningKey(writeKeyPEM(t, t.TempDir(), tt.blockType, tt.der))
+			if err != nil {
+				t.Fatalf("LoadSigningKey() error = %v", err)
+			}
+			jwsPath, err := SignRegistry(RegistryPath(dir, ""), key)
+			if err != nil {
+				t.Fatalf("SignRegistry() error = %v", err)
+			}
+
+			jws, err := os.ReadFile(jwsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			payload, err := os.ReadFile(filepath.Join(dir, ".well-known", "vctm-registry.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			alg, err := verifyDetached(string(jws), payload, tt.pub)
+			if err != nil {
+				t.Fatalf("signature does not verify: %v", err)
+			}
+			if alg != tt.wantAlg {
+				t.Errorf("alg = %q, want %q", alg, tt.wantAlg)
+			}
+
+			// Any change to the registry bytes must invalidate the signature
+			tampered := append([]byte(nil), payload...)
+			tampered[len(tampered)-2] = ' '
+			if _, err := verifyDetached(string(jws), tampered, tt.pub); err == nil {
+				t.Error("signature verifies over a tampered registry")
+			}
+		})
+	}
+}
+
+func TestLoadSigningKey_Invalid(t *testing.T) {
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384DER, err := x509.MarshalECPrivateKey(p384)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadSigningKey(writeKeyPEM(t, t.TempDir(), "EC PRIVATE KEY", p384DER)); err == nil || !strings.Contains(err.Error(), "P-256") {
+		t.Errorf("LoadSigningKey(P-384) error = %v, want P-256 error", err)
+	}
+	if _, err := LoadSigningKey(writeKeyPEM(t, t.TempDir(), "CERTIFICATE", []byte("x"))); err == nil {
+		t.Error("LoadSigningKey(CERTIFICATE) expected error")
+	}
+	notPEM := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(notPEM, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSigningKey(notPEM); err == nil {
+		t.Error("LoadSigningKey(non-PEM) expected error")
+	}
+}
@@ -0,0 +1,104 @@
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
+)
+
+// DefaultRobots is the robots.txt written when no custom content is given: the
+// registry is public, so crawlers may index everything
+const DefaultRobots = "User-agent: *\nAllow: /\n"
+
+// HeaderRule lists the recommended response headers for files matching Path,
+// a path pattern in the style of static hosts such as /*.json
+type HeaderRule struct {
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+}
+
+// jsonHeaders are the headers for JSON documents; wallets fetch type metadata
+// cross-origin, so CORS is allowed
+func jsonHeaders(maxAge int) map[string]string {
+	return map[string]string{
+		"Content-Type":                "application/json",
+		"Cache-Control":               fmt.Sprintf("public, max-age=%d", maxAge),
+		"Access-Control-Allow-Origin": "*",
+	}
+}
+
+// assetHeaders are the headers for images referenced from the metadata
+func assetHeaders(contentType string) map[string]string {
+	return map[string]string{
+		"Content-Type":  contentType,
+		"Cache-Control": "public, max-age=86400",
+	}
+}
+
+// SiteHeaderRules returns the recommended headers for the files of a registry.
+// The registry index changes with every run and is cached briefly; generated
+// metadata is cached for minutes and images for a day.
+func SiteHeaderRules() []HeaderRule {
+	return []HeaderRule{
+		{Path: "/.well-known/vctm-registry.json", Headers: jsonHeaders(60)},
+		{Path: "/.well-known/openid-credential-issuer", Headers: jsonHeaders(300)},
+		{Path: "/*.json", Headers: jsonHeaders(300)},
+		{Path: "/*.svg", Headers: assetHeaders("image/svg+xml")},
+		{Path: "/*.png", Headers: assetHeaders("image/png")},
+		{Path: "/*.jpg", Headers: assetHeaders("image/jpeg")},
+		{Path: "/*.jpeg", Headers: assetHeaders("image/jpeg")},
+		{Path: "/*.webp", Headers: assetHeaders("image/webp")},
+		{Path: "/*.md", Headers: map[string]string{
+			"Content-Type":  "text/markdown; charset=utf-8",
+			"Cache-Control": "public, max-age=300",
+		}},
+	}
+}
+
+// GenerateSiteFiles writes the static hosting files for a registry to outputDir:
+// robots.txt (DefaultRobots when robots is empty), a _headers file in the
+// format of Netlify and Cloudflare Pages, and headers.json with the same rules
+// for other hosts
+func GenerateSiteFiles(outputDir, robots string) error {
+	if robots == "" {
+		robots = DefaultRobots
+	}
+	if !strings.HasSuffix(robots, "\n") {
+		robots += "\n"
+	}
+	if err := atomicfile.WriteFile(filepath.Join(outputDir, "robots.txt"), []byte(robots), 0644); err != nil {
+		return fmt.Errorf("action: failed to write robots.txt: %w", err)
+	}
+
+	rules := SiteHeaderRules()
+
+	var headers strings.Builder
+	for _, rule := range rules {
+		headers.WriteString(rule.Path + "\n")
+		names := make([]string, 0, len(rule.Headers))
+		for name := range rule.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&headers, "  %s: %s\n", name, rule.Headers[name])
+		}
+	}
+	if err := atomicfile.WriteFile(filepath.Join(outputDir, "_headers"), []byte(headers.String()), 0644); err != nil {
+		return fmt.Errorf("action: failed to write _headers: %w", err)
+	}
+
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("action: failed to serialize headers: %w", err)
+	}
+	if err := atomicfile.WriteFile(filepath.Join(outputDir, "headers.json"), data, 0644); err != nil {
+		return fmt.Errorf("action: failed to write headers.json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package action
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSiteFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := GenerateSiteFiles(tmpDir, "User-agent: *\nDisallow: /drafts/"); err != nil {
+		t.Fatalf("GenerateSiteFiles() error = %v", err)
+	}
+
+	robots, err := os.ReadFile(filepath.Join(tmpDir, "robots.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read robots.txt: %v", err)
+	}
+	if string(robots) != "User-agent: *\nDisallow: /drafts/\n" {
+		t.Errorf("robots.txt = %q, want custom content with trailing newline", robots)
+	}
+
+	headers, err := os.ReadFile(filepath.Join(tmpDir, "_headers"))
+	if err != nil {
+		t.Fatalf("Failed to read _headers: %v", err)
+	}
+	if !strings.Contains(string(headers), "/*.json\n  Access-Control-Allow-Origin: *\n  Cache-Control: public, max-age=300\n  Content-Type: application/json\n") {
+		t.Errorf("_headers missing JSON rule:\n%s", headers)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "headers.json"))
+	if err != nil {
+		t.Fatalf("Failed to read headers.json: %v", err)
+	}
+	var rules []HeaderRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		t.Fatalf("headers.json is not valid JSON: %v", err)
+	}
+	if len(rules) != len(SiteHeaderRules()) {
+		t.Errorf("headers.json has %d rules, want %d", len(rules), len(SiteHeaderRules()))
+	}
+	for _, rule := range rules {
+		if rule.Path == "/*.svg" && rule.Headers["Content-Type"] != "image/svg+xml" {
+			t.Errorf("svg Content-Type = %q", rule.Headers["Content-Type"])
+		}
+	}
+}
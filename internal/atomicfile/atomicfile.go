@@ -0,0 +1,99 @@
+// Package atomicfile writes output files atomically, so that readers never see
+// partially written content
+package atomicfile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// File is an output file being written. Content goes to a temporary file in the
+// destination directory, which Commit renames into place.
+type File struct {
+	*os.File
+	path      string
+	perm      os.FileMode
+	committed bool
+}
+
+// Create starts writing the file at path. The destination is not touched until
+// Commit; Close without Commit discards the written content.
+func Create(path string, perm os.FileMode) (*File, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("atomicfile: failed to create temporary file for %s: %w", path, err)
+	}
+	return &File{File: tmp, path: path, perm: perm}, nil
+}
+
+// Commit flushes the written content to disk and renames it to the destination path
+func (f *File) Commit() error {
+	if f.committed {
+		return nil
+	}
+	tmpPath := f.File.Name()
+
+	err := f.File.Sync()
+	if closeErr := f.File.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, f.perm)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, f.path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("atomicfile: failed to write %s: %w", f.path, err)
+	}
+
+	f.committed = true
+	return nil
+}
+
+// Close discards the temporary file unless the file was committed. It is safe
+// to defer Close after Create and call Commit on success.
+func (f *File) Close() error {
+	if f.committed {
+		return nil
+	}
+	f.File.Close()
+	return os.Remove(f.File.Name())
+}
+
+// WriteFile writes data to path atomically, like os.WriteFile
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	f, err := Create(path, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("atomicfile: failed to write %s: %w", path, err)
+	}
+	return f.Commit()
+}
+
+// CopyFile copies src to dst atomically
+func CopyFile(src, dst string, perm os.FileMode) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	f, err := Create(dst, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, source); err != nil {
+		return fmt.Errorf("atomicfile: failed to copy %s to %s: %w", src, dst, err)
+	}
+	return f.Commit()
+}
@@ -0,0 +1,128 @@
+package atomicfile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// assertOnlyFiles fails unless dir contains exactly the named files
+func assertOnlyFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	if len(got) != len(names) {
+		t.Fatalf("directory contains %v, want %v", got, names)
+	}
+	for i := range names {
+		if got[i] != names[i] {
+			t.Fatalf("directory contains %v, want %v", got, names)
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "registry.json")
+	data := bytes.Repeat([]byte(`{"credential":"identity"},`), 10000)
+
+	if err := WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("file has %d bytes, want %d", len(got), len(data))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, want 0644", info.Mode().Perm())
+	}
+	assertOnlyFiles(t, dir, "registry.json")
+}
+
+func TestCreate_InterruptedWriteKeepsPreviousContent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "identity.vctm.json")
+	if err := os.WriteFile(path, []byte(`{"vct":"old"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a write that fails half way: the destination keeps its old content
+	f, err := Create(path, 0644)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := f.Write([]byte(`{"vct":"ne`)); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != `{"vct":"old"}` {
+		t.Errorf("destination changed before Commit: %s", got)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != `{"vct":"old"}` {
+		t.Errorf("destination changed by aborted write: %s", got)
+	}
+	assertOnlyFiles(t, dir, "identity.vctm.json")
+
+	// A completed write replaces the file in one step
+	f, err = Create(path, 0644)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte(`{"vct":"new"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != `{"vct":"new"}` {
+		t.Errorf("destination = %s, want new content", got)
+	}
+	assertOnlyFiles(t, dir, "identity.vctm.json")
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "logo.png")
+	if err := os.WriteFile(src, []byte("png data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "out.png")
+	if err := CopyFile(src, dst, 0644); err != nil {
+		t.Fatalf("CopyFile() error = %v", err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "png data" {
+		t.Errorf("copy = %q", got)
+	}
+
+	// Copying a file onto itself keeps its content
+	if err := CopyFile(src, src, 0644); err != nil {
+		t.Fatalf("CopyFile() onto itself error = %v", err)
+	}
+	if got, _ := os.ReadFile(src); string(got) != "png data" {
+		t.Errorf("self copy = %q", got)
+	}
+	assertOnlyFiles(t, dir, "logo.png", "out.png")
+
+	if err := CopyFile(filepath.Join(dir, "missing.png"), dst, 0644); err == nil {
+		t.Error("CopyFile() of missing source should fail")
+	}
+}
@@ -0,0 +1,147 @@
+// Package jsondiff compares JSON documents semantically and renders the
+// differences as a unified diff
+package jsondiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+// Canonical re-encodes a JSON document with sorted object keys and two-space
+// indentation, so that documents differing only in key order or formatting
+// compare equal
+func Canonical(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("jsondiff: invalid JSON: %w", err)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// Diff returns a unified diff from the canonical form of oldData to that of
+// newData, labelled with oldName and newName, or "" if both are equal
+func Diff(oldName, newName string, oldData, newData []byte) (string, error) {
+	oldCanonical, err := Canonical(oldData)
+	if err != nil {
+		return "", fmt.Errorf("jsondiff: %s: %w", oldName, err)
+	}
+	newCanonical, err := Canonical(newData)
+	if err != nil {
+		return "", fmt.Errorf("jsondiff: %s: %w", newName, err)
+	}
+	if string(oldCanonical) == string(newCanonical) {
+		return "", nil
+	}
+	return Unified(oldName, newName, strings.Split(string(oldCanonical), "\n"), strings.Split(string(newCanonical), "\n")), nil
+}
+
+// edit is one line of an edit script: ' ' kept, '-' removed, '+' added
+type edit struct {
+	op   byte
+	line string
+}
+
+// Unified renders the line differences between a and b as a unified diff
+func Unified(oldName, newName string, a, b []string) string {
+	edits := editScript(a, b)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(edits); {
+		// Find the next change and the hunk of changes within 2*contextLines of each other
+		for start < len(edits) && edits[start].op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		end := start
+		for i := start; i < len(edits); i++ {
+			if edits[i].op != ' ' {
+				end = i + 1
+			} else if i-end >= 2*contextLines {
+				break
+			}
+		}
+
+		from := max(start-contextLines, 0)
+		to := min(end+contextLines, len(edits))
+
+		// Line numbers of the hunk in a and b
+		oldLine, newLine := 1, 1
+		for _, e := range edits[:from] {
+			if e.op != '+' {
+				oldLine++
+			}
+			if e.op != '-' {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, e := range edits[from:to] {
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+		}
+
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		for _, e := range edits[from:to] {
+			sb.WriteByte(e.op)
+			sb.WriteString(e.line)
+			sb.WriteByte('\n')
+		}
+		start = to
+	}
+
+	return sb.String()
+}
+
+// editScript computes a shortest edit script from a to b using the longest
+// common subsequence of lines
+func editScript(a, b []string) []edit {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		edits = append(edits, edit{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, edit{'+', b[j]})
+	}
+	return edits
+}
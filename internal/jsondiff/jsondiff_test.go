@@ -0,0 +1,41 @@
+package jsondiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff_KeyOrderIndependent(t *testing.T) {
+	diff, err := Diff("a.json", "b.json", []byte(`{"vct": "urn:test", "name": "Test"}`), []byte("{\n  \"name\": \"Test\",\n  \"vct\": \"urn:test\"\n}\n"))
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if diff != "" {
+		t.Errorf("Diff() = %q, want no difference", diff)
+	}
+}
+
+func TestDiff_Unified(t *testing.T) {
+	diff, err := Diff("old.json", "new.json", []byte(`{"name": "Test", "vct": "urn:old"}`), []byte(`{"name": "Test", "vct": "urn:new"}`))
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	want := `--- old.json
++++ new.json
+@@ -1,4 +1,4 @@
+ {
+   "name": "Test",
+-  "vct": "urn:old"
++  "vct": "urn:new"
+ }
+`
+	if diff != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", diff, want)
+	}
+}
+
+func TestDiff_InvalidJSON(t *testing.T) {
+	if _, err := Diff("old.json", "new.json", []byte(`{`), []byte(`{}`)); err == nil || !strings.Contains(err.Error(), "old.json") {
+		t.Errorf("Diff() error = %v, want an error naming old.json", err)
+	}
+}
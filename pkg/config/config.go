@@ -5,11 +5,28 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/sirosfoundation/mtcvctm/internal/atomicfile"
+	"github.com/sirosfoundation/mtcvctm/pkg/vctm"
 	"gopkg.in/yaml.v3"
 )
 
+// DirConfigFileName is the name of per-directory configuration files discovered during batch runs
+const DirConfigFileName = ".mtcvctm.yaml"
+
+// DefaultMaxInlineBytes is the size above which images are referenced by URL
+// rather than inlined as data URLs
+const DefaultMaxInlineBytes = 64 << 10
+
+// Limits of remote image downloads with ResolveRemoteImages
+const (
+	DefaultRemoteImageMaxBytes = 5 << 20
+	DefaultRemoteImageTimeout  = 30 * time.Second
+)
+
 // Config holds the configuration for mtcvctm
 type Config struct {
 	// InputFile is the path to the input markdown file
@@ -24,12 +41,20 @@ type Config struct {
 	// BaseURL is the base URL for generating image URLs
 	BaseURL string `yaml:"base_url" json:"base_url"`
 
+	// AssetBaseURL is the base URL for image, logo and SVG template URIs when assets
+	// are served separately from the type identifiers (default: BaseURL)
+	AssetBaseURL string `yaml:"asset_base_url" json:"asset_base_url"`
+
 	// VCT is the Verifiable Credential Type identifier
 	VCT string `yaml:"vct" json:"vct"`
 
 	// Language is the default language for display properties
 	Language string `yaml:"language" json:"language"`
 
+	// LocaleFallbackOrder lists locales to try, in order, for the default display;
+	// the first one the credential provides is used instead of Language
+	LocaleFallbackOrder []string `yaml:"locale_fallback_order" json:"locale_fallback_order,omitempty"`
+
 	// GitHubAction indicates if running in GitHub Action mode
 	GitHubAction bool `yaml:"github_action" json:"github_action"`
 
@@ -39,17 +64,303 @@ type Config struct {
 	// InlineImages embeds images as base64 data URLs in the VCTM
 	InlineImages bool `yaml:"inline_images" json:"inline_images"`
 
+	// InlineImagesSet records that InlineImages was given explicitly, in a config
+	// file or on the command line, so that Merge also copies a false value
+	InlineImagesSet bool `yaml:"-" json:"-"`
+
 	// Formats is a comma-separated list of output formats (vctm, mddl, w3c, all)
 	Formats string `yaml:"formats" json:"formats"`
+
+	// EmitValueType adds an x-value_type hint derived from the claim type to vctm claim entries
+	EmitValueType bool `yaml:"emit_value_type" json:"emit_value_type"`
+
+	// MDDLIncludeSD adds the claim's selective disclosure setting to mddl claim
+	// metadata as a non-standard sd hint
+	MDDLIncludeSD bool `yaml:"mddl_include_sd" json:"mddl_include_sd"`
+
+	// HashImageNames publishes images referenced by URL under a name with a short
+	// content hash, logo.<crc32>.png, for cache busting
+	HashImageNames bool `yaml:"hash_image_names" json:"hash_image_names"`
+
+	// W3CContextBase is the VCDM base context of W3C output (default: DefaultW3CContextBase)
+	W3CContextBase string `yaml:"w3c_context_base" json:"w3c_context_base,omitempty"`
+
+	// W3CContextPath is the template of the per-credential context added after the
+	// base context when a base URL is set; {base_url} and {id} are substituted
+	// (default: DefaultW3CContextPath)
+	W3CContextPath string `yaml:"w3c_context_path" json:"w3c_context_path,omitempty"`
+
+	// VCTMDraft selects the SD-JWT VC draft of the vctm claims shape: 11 for an
+	// object keyed by claim name, 12 for an array of path entries (default: 12)
+	VCTMDraft int `yaml:"vctm_draft" json:"vctm_draft,omitempty"`
+
+	// Strict turns conditions that are otherwise reported as warnings into errors
+	Strict bool `yaml:"strict" json:"strict"`
+
+	// ResolveExtends fetches the parent type of extends to compute a missing
+	// extends#integrity
+	ResolveExtends bool `yaml:"resolve_extends" json:"resolve_extends"`
+
+	// InheritClaims merges the claims of the extends parent into the credential
+	InheritClaims bool `yaml:"inherit_claims" json:"inherit_claims"`
+
+	// MaxInlineBytes is the largest image inlined as a data URL; larger images are
+	// referenced by URL when a base URL is set (default: DefaultMaxInlineBytes,
+	// negative for no limit)
+	MaxInlineBytes int64 `yaml:"max_inline_bytes" json:"max_inline_bytes,omitempty"`
+
+	// NoSVGMinify inlines SVG images as they are instead of stripping comments,
+	// insignificant whitespace and editor metadata first
+	NoSVGMinify bool `yaml:"no_svg_minify" json:"no_svg_minify"`
+
+	// ResolveRemoteImages downloads images referenced by http(s) URL to compute
+	// their uri#integrity; the URL itself is kept as the uri
+	ResolveRemoteImages bool `yaml:"resolve_remote_images" json:"resolve_remote_images"`
+
+	// RemoteImageMaxBytes limits the size of a downloaded remote image (default:
+	// DefaultRemoteImageMaxBytes)
+	RemoteImageMaxBytes int64 `yaml:"remote_image_max_bytes" json:"remote_image_max_bytes,omitempty"`
+
+	// RemoteImageTimeout bounds each remote image download (default:
+	// DefaultRemoteImageTimeout)
+	RemoteImageTimeout time.Duration `yaml:"remote_image_timeout" json:"remote_image_timeout,omitempty"`
+
+	// SDPolicy sets the selective disclosure of claims without an explicit sd flag
+	SDPolicy *SDPolicy `yaml:"sd_policy" json:"sd_policy,omitempty"`
+
+	// Indent is the JSON indentation: a number of spaces or "tab" (default: 2 spaces)
+	Indent string `yaml:"indent" json:"indent"`
+
+	// Canonical writes JSON output in canonical form (RFC 8785 style): compact,
+	// with recursively sorted keys, instead of indenting it
+	Canonical bool `yaml:"canonical" json:"canonical"`
+
+	// Layout arranges output files: LayoutFlat puts all formats side by side,
+	// LayoutByFormat into one subdirectory per format (default: flat)
+	Layout string `yaml:"layout" json:"layout,omitempty"`
+
+	// MDDLEncoding is the encoding of mddl output: MDDLEncodingJSON or
+	// MDDLEncodingCBOR (default: json)
+	MDDLEncoding string `yaml:"mddl_encoding" json:"mddl_encoding,omitempty"`
+
+	// ClaimsSection decides what lists define claims in a document without a
+	// claims heading: ClaimsSectionLenient or ClaimsSectionStrict (default: lenient)
+	ClaimsSection string `yaml:"claims_section" json:"claims_section,omitempty"`
+
+	// RegistryFilename is the registry file relative to the .well-known directory,
+	// possibly nested (default: DefaultRegistryFilename)
+	RegistryFilename string `yaml:"registry_filename" json:"registry_filename,omitempty"`
+
+	// RegistryVersion is the version field of the registry (default:
+	// DefaultRegistryVersion)
+	RegistryVersion string `yaml:"registry_version" json:"registry_version,omitempty"`
+
+	// PostProcess is a command that transforms each generated file before it is
+	// written: it receives the output on stdin and writes the replacement to stdout
+	PostProcess string `yaml:"post_process" json:"post_process,omitempty"`
+}
+
+// SDPolicy ties the selective disclosure of claims without an explicit sd flag
+// to whether they are mandatory, e.g. mandatory: never, optional: allowed
+type SDPolicy struct {
+	// Mandatory is the sd value for mandatory claims
+	Mandatory string `yaml:"mandatory" json:"mandatory,omitempty"`
+
+	// Optional is the sd value for claims that are not mandatory
+	Optional string `yaml:"optional" json:"optional,omitempty"`
+}
+
+// SD returns the policy's sd value for a claim, or "" if the policy leaves it unset
+func (p *SDPolicy) SD(mandatory bool) string {
+	if mandatory {
+		return p.Mandatory
+	}
+	return p.Optional
+}
+
+// Validate checks that the policy only uses always, allowed or never
+func (p *SDPolicy) Validate() error {
+	for key, value := range map[string]string{"mandatory": p.Mandatory, "optional": p.Optional} {
+		if value != "" && !vctm.IsValidSD(value) {
+			return fmt.Errorf("config: invalid sd_policy %s value %q (want always, allowed or never)", key, value)
+		}
+	}
+	return nil
+}
+
+// DefaultIndent is the JSON indentation used when none is configured
+const DefaultIndent = "  "
+
+// ParseIndent converts an indent setting (a number of spaces or "tab") into the
+// indentation string used for JSON output. An empty value yields DefaultIndent.
+func ParseIndent(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return DefaultIndent, nil
+	}
+	if strings.EqualFold(value, "tab") {
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > 16 {
+		return "", fmt.Errorf("config: invalid indent %q: must be a number of spaces (0-16) or \"tab\"", value)
+	}
+	return strings.Repeat(" ", n), nil
+}
+
+// Output layouts
+const (
+	// LayoutFlat writes all formats side by side: <output>/<name>.vctm.json
+	LayoutFlat = "flat"
+
+	// LayoutByFormat writes each format into its own subdirectory: <output>/vctm/<name>.vctm.json
+	LayoutByFormat = "by-format"
+)
+
+// ValidateLayout checks that layout is a supported output layout, "" meaning flat
+func ValidateLayout(layout string) error {
+	switch layout {
+	case "", LayoutFlat, LayoutByFormat:
+		return nil
+	}
+	return fmt.Errorf("config: invalid layout %q: must be %s or %s", layout, LayoutFlat, LayoutByFormat)
+}
+
+// Encodings of mddl output
+const (
+	// MDDLEncodingJSON writes the mddl configuration as JSON: <name>.mdoc.json
+	MDDLEncodingJSON = "json"
+
+	// MDDLEncodingCBOR writes the mddl configuration as deterministic CBOR: <name>.mdoc.cbor
+	MDDLEncodingCBOR = "cbor"
+)
+
+// ValidateMDDLEncoding checks that encoding is a supported mddl encoding, "" meaning json
+func ValidateMDDLEncoding(encoding string) error {
+	switch encoding {
+	case "", MDDLEncodingJSON, MDDLEncodingCBOR:
+		return nil
+	}
+	return fmt.Errorf("config: invalid mddl encoding %q: must be %s or %s", encoding, MDDLEncodingJSON, MDDLEncodingCBOR)
+}
+
+// Policies for lists in documents without a claims section
+const (
+	// ClaimsSectionLenient reads claims from any list when a document has no
+	// claims heading, with a deprecation warning
+	ClaimsSectionLenient = "lenient"
+
+	// ClaimsSectionStrict only reads claims from lists under a claims heading
+	ClaimsSectionStrict = "strict"
+)
+
+// ValidateClaimsSection checks that policy is a supported claims section policy, "" meaning lenient
+func ValidateClaimsSection(policy string) error {
+	switch policy {
+	case "", ClaimsSectionLenient, ClaimsSectionStrict:
+		return nil
+	}
+	return fmt.Errorf("config: invalid claims_section %q: must be %s or %s", policy, ClaimsSectionLenient, ClaimsSectionStrict)
+}
+
+// DefaultRegistryFilename is the registry file below the .well-known directory
+const DefaultRegistryFilename = "vctm-registry.json"
+
+// DefaultRegistryVersion is the version field of generated registries
+const DefaultRegistryVersion = "1.0"
+
+// ValidateRegistryFilename checks that filename, "" meaning the default, stays
+// inside the .well-known directory
+func ValidateRegistryFilename(filename string) error {
+	if filename != "" && !filepath.IsLocal(filename) {
+		return fmt.Errorf("config: invalid registry filename %q: must be a relative path inside .well-known", filename)
+	}
+	return nil
+}
+
+// StdioPath is the input or output path that stands for stdin or stdout
+const StdioPath = "-"
+
+// DefaultW3CContextBase is the VCDM 2.0 base context
+const DefaultW3CContextBase = "https://www.w3.org/ns/credentials/v2"
+
+// DefaultW3CContextPath is the default per-credential context template
+const DefaultW3CContextPath = "{base_url}/contexts/{id}/v1"
+
+// W3CContext returns the @context of a credential with the given id: the base
+// context, followed by the per-credential context when a base URL is set
+func (c *Config) W3CContext(id string) []string {
+	base := c.W3CContextBase
+	if base == "" {
+		base = DefaultW3CContextBase
+	}
+	contexts := []string{base}
+	if c.BaseURL == "" || id == "" {
+		return contexts
+	}
+	path := c.W3CContextPath
+	if path == "" {
+		path = DefaultW3CContextPath
+	}
+	replacer := strings.NewReplacer("{base_url}", strings.TrimSuffix(c.BaseURL, "/"), "{id}", id)
+	return append(contexts, replacer.Replace(path))
+}
+
+// DefaultVCTMDraft is the SD-JWT VC draft emitted when none is configured
+const DefaultVCTMDraft = 12
+
+// ValidateVCTMDraft checks that draft is a supported vctm draft, 0 meaning the default
+func ValidateVCTMDraft(draft int) error {
+	switch draft {
+	case 0, 11, 12:
+		return nil
+	}
+	return fmt.Errorf("config: invalid vctm_draft %d: must be 11 or 12", draft)
+}
+
+// GetVCTMDraft returns the configured vctm draft, or DefaultVCTMDraft
+func (c *Config) GetVCTMDraft() int {
+	if c.VCTMDraft == 0 {
+		return DefaultVCTMDraft
+	}
+	return c.VCTMDraft
+}
+
+// ParseLocaleList splits a comma-separated list of locales, dropping empty entries
+func ParseLocaleList(value string) []string {
+	var locales []string
+	for _, locale := range strings.Split(value, ",") {
+		if locale = strings.TrimSpace(locale); locale != "" {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
 }
 
 // DefaultConfig returns a configuration with default values
 func DefaultConfig() *Config {
 	return &Config{
-		Language:   "en-US",
-		VCTMBranch: "vctm",
-		Formats:    "vctm", // Default to VCTM only for backward compatibility
+		Language:     "en-US",
+		VCTMBranch:   "vctm",
+		InlineImages: true,
+		Formats:      "vctm", // Default to VCTM only for backward compatibility
+	}
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, recording whether inline_images is present
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	type plain Config
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
 	}
+	if value.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			if value.Content[i].Value == "inline_images" {
+				c.InlineImagesSet = true
+			}
+		}
+	}
+	return nil
 }
 
 // LoadFromFile loads configuration from a YAML file
@@ -67,15 +378,98 @@ func LoadFromFile(path string) (*Config, error) {
 	return config, nil
 }
 
+// DiscoverForFile walks up from the directory containing path to root, merging every
+// .mtcvctm.yaml found so that the file nearest to path takes precedence.
+// It returns nil if no configuration file was found.
+func DiscoverForFile(path, root string) (*Config, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to resolve %s: %w", root, err)
+	}
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to resolve %s: %w", path, err)
+	}
+
+	// Collect candidate files from the nearest directory up to the root
+	var found []string
+	for {
+		candidate := filepath.Join(dir, DirConfigFileName)
+		if _, err := os.Stat(candidate); err == nil {
+			found = append(found, candidate)
+		}
+		if dir == absRoot {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir || !strings.HasPrefix(dir, absRoot) {
+			break
+		}
+		dir = parent
+	}
+
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	// Merge from the root down so nearer files override
+	merged := &Config{}
+	for i := len(found) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(found[i])
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to read file %s: %w", found[i], err)
+		}
+		dirCfg := &Config{}
+		if err := yaml.Unmarshal(data, dirCfg); err != nil {
+			return nil, fmt.Errorf("config: failed to parse YAML in %s: %w", found[i], err)
+		}
+		merged.Merge(dirCfg)
+	}
+
+	return merged, nil
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	if c.InputFile == "" {
 		return fmt.Errorf("config: input file is required")
 	}
 
-	// Check if input file exists
-	if _, err := os.Stat(c.InputFile); os.IsNotExist(err) {
-		return fmt.Errorf("config: input file does not exist: %s", c.InputFile)
+	// Check if input file exists, unless it is read from stdin
+	if c.InputFile != StdioPath {
+		if _, err := os.Stat(c.InputFile); os.IsNotExist(err) {
+			return fmt.Errorf("config: input file does not exist: %s", c.InputFile)
+		}
+	}
+
+	if _, err := ParseIndent(c.Indent); err != nil {
+		return err
+	}
+
+	if err := ValidateVCTMDraft(c.VCTMDraft); err != nil {
+		return err
+	}
+
+	if err := ValidateLayout(c.Layout); err != nil {
+		return err
+	}
+
+	if err := ValidateMDDLEncoding(c.MDDLEncoding); err != nil {
+		return err
+	}
+
+	if err := ValidateClaimsSection(c.ClaimsSection); err != nil {
+		return err
+	}
+
+	if c.SDPolicy != nil {
+		if err := c.SDPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.RemoteImageMaxBytes < 0 || c.RemoteImageTimeout < 0 {
+		return fmt.Errorf("config: remote image size limit and timeout must not be negative")
 	}
 
 	return nil
@@ -96,6 +490,42 @@ func (c *Config) GetOutputFile() string {
 	return filepath.Join(dir, name+".vctm")
 }
 
+// GetIndent returns the JSON indentation string, falling back to DefaultIndent for invalid settings
+func (c *Config) GetIndent() string {
+	indent, err := ParseIndent(c.Indent)
+	if err != nil {
+		return DefaultIndent
+	}
+	return indent
+}
+
+// GetAssetBaseURL returns the base URL for asset URIs, falling back to BaseURL
+func (c *Config) GetAssetBaseURL() string {
+	if c.AssetBaseURL != "" {
+		return c.AssetBaseURL
+	}
+	return c.BaseURL
+}
+
+// InlineTooLarge reports whether an image of size bytes exceeds MaxInlineBytes
+func (c *Config) InlineTooLarge(size int64) bool {
+	limit := c.MaxInlineBytes
+	if limit == 0 {
+		limit = DefaultMaxInlineBytes
+	}
+	return limit > 0 && size > limit
+}
+
+// AssetURL builds the URI of an asset relative to the asset base URL.
+// It returns an empty string if no base URL is configured.
+func (c *Config) AssetURL(path string) string {
+	base := c.GetAssetBaseURL()
+	if base == "" {
+		return ""
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(path, "./")
+}
+
 // GetVCT returns the VCT identifier, deriving from base_url if not set
 func (c *Config) GetVCT() string {
 	if c.VCT != "" {
@@ -113,6 +543,19 @@ func (c *Config) GetVCT() string {
 	return ""
 }
 
+// DefaultLocale returns the locale of the default display: the first locale in
+// LocaleFallbackOrder that is among the available locales, otherwise Language
+func (c *Config) DefaultLocale(available []string) string {
+	for _, locale := range c.LocaleFallbackOrder {
+		for _, a := range available {
+			if strings.EqualFold(locale, a) {
+				return a
+			}
+		}
+	}
+	return c.Language
+}
+
 // SaveToFile saves the configuration to a YAML file
 func (c *Config) SaveToFile(path string) error {
 	data, err := yaml.Marshal(c)
@@ -120,7 +563,7 @@ func (c *Config) SaveToFile(path string) error {
 		return fmt.Errorf("config: failed to marshal YAML: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	if err := atomicfile.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("config: failed to write file %s: %w", path, err)
 	}
 
@@ -141,22 +584,98 @@ func (c *Config) Merge(other *Config) {
 	if other.BaseURL != "" {
 		c.BaseURL = other.BaseURL
 	}
+	if other.AssetBaseURL != "" {
+		c.AssetBaseURL = other.AssetBaseURL
+	}
 	if other.VCT != "" {
 		c.VCT = other.VCT
 	}
 	if other.Language != "" {
 		c.Language = other.Language
 	}
+	if len(other.LocaleFallbackOrder) > 0 {
+		c.LocaleFallbackOrder = other.LocaleFallbackOrder
+	}
 	if other.GitHubAction {
 		c.GitHubAction = true
 	}
 	if other.VCTMBranch != "" {
 		c.VCTMBranch = other.VCTMBranch
 	}
-	if other.InlineImages {
-		c.InlineImages = true
+	if other.InlineImagesSet {
+		c.InlineImages = other.InlineImages
+		c.InlineImagesSet = true
 	}
 	if other.Formats != "" {
 		c.Formats = other.Formats
 	}
+	if other.EmitValueType {
+		c.EmitValueType = true
+	}
+	if other.MDDLIncludeSD {
+		c.MDDLIncludeSD = true
+	}
+	if other.HashImageNames {
+		c.HashImageNames = true
+	}
+	if other.VCTMDraft != 0 {
+		c.VCTMDraft = other.VCTMDraft
+	}
+	if other.W3CContextBase != "" {
+		c.W3CContextBase = other.W3CContextBase
+	}
+	if other.W3CContextPath != "" {
+		c.W3CContextPath = other.W3CContextPath
+	}
+	if other.Strict {
+		c.Strict = true
+	}
+	if other.ResolveExtends {
+		c.ResolveExtends = true
+	}
+	if other.InheritClaims {
+		c.InheritClaims = true
+	}
+	if other.MaxInlineBytes != 0 {
+		c.MaxInlineBytes = other.MaxInlineBytes
+	}
+	if other.NoSVGMinify {
+		c.NoSVGMinify = true
+	}
+	if other.RegistryFilename != "" {
+		c.RegistryFilename = other.RegistryFilename
+	}
+	if other.RegistryVersion != "" {
+		c.RegistryVersion = other.RegistryVersion
+	}
+	if other.ResolveRemoteImages {
+		c.ResolveRemoteImages = true
+	}
+	if other.RemoteImageMaxBytes != 0 {
+		c.RemoteImageMaxBytes = other.RemoteImageMaxBytes
+	}
+	if other.RemoteImageTimeout != 0 {
+		c.RemoteImageTimeout = other.RemoteImageTimeout
+	}
+	if other.SDPolicy != nil {
+		c.SDPolicy = other.SDPolicy
+	}
+	if other.Indent != "" {
+		c.Indent = other.Indent
+	}
+	if other.Canonical {
+		c.Canonical = true
+	}
+	if other.PostProcess != "" {
+		c.PostProcess = other.PostProcess
+	}
+	if other.Layout != "" {
+		c.Layout = other.Layout
+	}
+	if other.MDDLEncoding != "" {
+		c.MDDLEncoding = other.MDDLEncoding
+	}
+	if other.ClaimsSection != "" {
+		c.ClaimsSection = other.ClaimsSection
+	}
 }
@@ -104,6 +104,38 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid sd_policy",
+			config: Config{
+				InputFile: testFile,
+				SDPolicy:  &SDPolicy{Mandatory: "never", Optional: "allowed"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid sd_policy",
+			config: Config{
+				InputFile: testFile,
+				SDPolicy:  &SDPolicy{Mandatory: "sometimes"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "strict claims_section",
+			config: Config{
+				InputFile:     testFile,
+				ClaimsSection: ClaimsSectionStrict,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid claims_section",
+			config: Config{
+				InputFile:     testFile,
+				ClaimsSection: "loose",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -258,3 +290,163 @@ func TestConfig_Merge(t *testing.T) {
 		t.Errorf("GitHubAction should be true")
 	}
 }
+
+func TestConfig_Merge_InlineImages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("inline_images: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fileCfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+
+	// --no-inline-images wins over the config file
+	cfg := DefaultConfig()
+	cfg.Merge(fileCfg)
+	cfg.Merge(&Config{InlineImages: false, InlineImagesSet: true})
+	if cfg.InlineImages {
+		t.Error("InlineImages should be disabled by the flag config")
+	}
+
+	// Without the flag, inline_images: false from the config file is kept
+	if err := os.WriteFile(path, []byte("inline_images: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fileCfg, err = LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	cfg = DefaultConfig()
+	cfg.Merge(fileCfg)
+	cfg.Merge(&Config{InlineImages: true})
+	if cfg.InlineImages {
+		t.Error("InlineImages should stay disabled by the config file")
+	}
+}
+
+func TestDiscoverForFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// No configuration files yet
+	cfg, err := DiscoverForFile(filepath.Join(nested, "cred.md"), root)
+	if err != nil {
+		t.Fatalf("DiscoverForFile() error = %v", err)
+	}
+	if cfg != nil {
+		t.Errorf("DiscoverForFile() = %+v, want nil", cfg)
+	}
+
+	writeDirConfig := func(dir, content string) {
+		if err := os.WriteFile(filepath.Join(dir, DirConfigFileName), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeDirConfig(root, "base_url: https://root.example.com\nlanguage: de-DE\n")
+	writeDirConfig(filepath.Join(root, "a"), "base_url: https://a.example.com\n")
+
+	cfg, err = DiscoverForFile(filepath.Join(nested, "cred.md"), root)
+	if err != nil {
+		t.Fatalf("DiscoverForFile() error = %v", err)
+	}
+	if cfg == nil {
+		t.Fatal("DiscoverForFile() returned nil")
+	}
+	if cfg.BaseURL != "https://a.example.com" {
+		t.Errorf("BaseURL = %q, want nearest https://a.example.com", cfg.BaseURL)
+	}
+	if cfg.Language != "de-DE" {
+		t.Errorf("Language = %q, want inherited de-DE", cfg.Language)
+	}
+
+	cfg, err = DiscoverForFile(filepath.Join(root, "cred.md"), root)
+	if err != nil {
+		t.Fatalf("DiscoverForFile() error = %v", err)
+	}
+	if cfg == nil || cfg.BaseURL != "https://root.example.com" {
+		t.Errorf("root BaseURL = %+v, want https://root.example.com", cfg)
+	}
+}
+
+func TestParseIndent(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"", "  ", false},
+		{"4", "    ", false},
+		{"0", "", false},
+		{"tab", "\t", false},
+		{"TAB", "\t", false},
+		{"-1", "", true},
+		{"wide", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseIndent(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseIndent(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseIndent(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	cfg := &Config{Indent: "wide"}
+	if got := cfg.GetIndent(); got != DefaultIndent {
+		t.Errorf("GetIndent() with invalid indent = %q, want default", got)
+	}
+}
+
+func TestConfig_AssetURL(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		path string
+		want string
+	}{
+		{"no base URL", Config{}, "logo.png", ""},
+		{"falls back to base URL", Config{BaseURL: "https://example.com/"}, "./logo.png", "https://example.com/logo.png"},
+		{"asset base URL wins", Config{BaseURL: "https://example.com", AssetBaseURL: "https://cdn.example.com"}, "images/logo.png", "https://cdn.example.com/images/logo.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.AssetURL(tt.path); got != tt.want {
+				t.Errorf("AssetURL(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_DefaultLocale(t *testing.T) {
+	cfg := &Config{Language: "en-US", LocaleFallbackOrder: ParseLocaleList("fr-FR, de-DE,,en-US")}
+	if len(cfg.LocaleFallbackOrder) != 3 {
+		t.Fatalf("ParseLocaleList() = %v, want 3 locales", cfg.LocaleFallbackOrder)
+	}
+
+	tests := []struct {
+		available []string
+		want      string
+	}{
+		{[]string{"de-DE", "sv"}, "de-DE"},
+		{[]string{"sv", "fr-fr"}, "fr-fr"},
+		{[]string{"sv"}, "en-US"},
+		{nil, "en-US"},
+	}
+	for _, tt := range tests {
+		if got := cfg.DefaultLocale(tt.available); got != tt.want {
+			t.Errorf("DefaultLocale(%v) = %q, want %q", tt.available, got, tt.want)
+		}
+	}
+
+	if got := (&Config{Language: "sv"}).DefaultLocale([]string{"de-DE"}); got != "sv" {
+		t.Errorf("DefaultLocale() without fallback order = %q, want Language", got)
+	}
+}
@@ -0,0 +1,163 @@
+// Package anoncreds provides the AnonCreds format generator for schema and credential definition stubs
+package anoncreds
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+)
+
+func init() {
+	formats.Register(NewGenerator())
+}
+
+// Generator implements the AnonCreds format generator
+type Generator struct{}
+
+// NewGenerator creates a new AnonCreds generator
+func NewGenerator() *Generator {
+	return &Generator{}
+}
+
+// Name returns the format identifier
+func (g *Generator) Name() string {
+	return "anoncreds"
+}
+
+// Description returns a human-readable description
+func (g *Generator) Description() string {
+	return "AnonCreds schema and credential definition stub"
+}
+
+// FileExtension returns the output file extension
+func (g *Generator) FileExtension() string {
+	return "anoncreds.json"
+}
+
+// DeriveIdentifier derives the schema identifier as name:version
+func (g *Generator) DeriveIdentifier(parsed *formats.ParsedCredential, cfg *config.Config) string {
+	name := g.deriveName(parsed)
+	if name == "" {
+		return ""
+	}
+	return name + ":" + g.deriveVersion(parsed)
+}
+
+// deriveName returns the schema name, preferring the credential name over the ID
+func (g *Generator) deriveName(parsed *formats.ParsedCredential) string {
+	if parsed.Name != "" {
+		return parsed.Name
+	}
+	return parsed.ID
+}
+
+// deriveVersion returns the schema version from overrides or front matter (default: 1.0)
+func (g *Generator) deriveVersion(parsed *formats.ParsedCredential) string {
+	if overrides, ok := parsed.FormatOverrides["anoncreds"]; ok {
+		if v, ok := overrides["version"].(string); ok && v != "" {
+			return v
+		}
+	}
+	if v, ok := parsed.Metadata["version"].(string); ok && v != "" {
+		return v
+	}
+	return "1.0"
+}
+
+// override returns a string format override for AnonCreds
+func (g *Generator) override(parsed *formats.ParsedCredential, key string) string {
+	if overrides, ok := parsed.FormatOverrides["anoncreds"]; ok {
+		if v, ok := overrides[key].(string); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// Output is the generated AnonCreds document
+type Output struct {
+	Schema               Schema               `json:"schema"`
+	CredentialDefinition CredentialDefinition `json:"credentialDefinition"`
+}
+
+// Schema represents an AnonCreds schema object
+type Schema struct {
+	IssuerID  string   `json:"issuerId,omitempty"`
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	AttrNames []string `json:"attrNames"`
+}
+
+// CredentialDefinition is an AnonCreds credential definition stub; the key material in
+// value is produced by the issuer's ledger tooling
+type CredentialDefinition struct {
+	IssuerID string                 `json:"issuerId,omitempty"`
+	SchemaID string                 `json:"schemaId,omitempty"`
+	Type     string                 `json:"type"`
+	Tag      string                 `json:"tag"`
+	Value    map[string]interface{} `json:"value"`
+}
+
+// Generate produces the AnonCreds output
+func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Config) ([]byte, error) {
+	name := g.deriveName(parsed)
+	if name == "" {
+		return nil, fmt.Errorf("anoncreds: schema name is required")
+	}
+
+	attrNames := g.attrNames(parsed)
+	if len(attrNames) == 0 {
+		return nil, fmt.Errorf("anoncreds: at least one claim is required for attrNames")
+	}
+
+	issuerID := g.override(parsed, "issuer_id")
+	tag := g.override(parsed, "tag")
+	if tag == "" {
+		tag = "default"
+	}
+
+	output := &Output{
+		Schema: Schema{
+			IssuerID:  issuerID,
+			Name:      name,
+			Version:   g.deriveVersion(parsed),
+			AttrNames: attrNames,
+		},
+		CredentialDefinition: CredentialDefinition{
+			IssuerID: issuerID,
+			SchemaID: g.override(parsed, "schema_id"),
+			Type:     "CL",
+			Tag:      tag,
+			Value:    map[string]interface{}{},
+		},
+	}
+
+	return formats.FormatJSON(output, cfg)
+}
+
+// attrNames returns the sorted, de-duplicated attribute names, applying AnonCreds claim mappings
+func (g *Generator) attrNames(parsed *formats.ParsedCredential) []string {
+	seen := make(map[string]bool, len(parsed.Claims))
+	names := make([]string, 0, len(parsed.Claims))
+	for _, claim := range parsed.Claims {
+		// Get claim name, applying format mapping if present
+		attrName := claim.Name
+		if mapping, ok := claim.FormatMappings["anoncreds"]; ok {
+			attrName = mapping
+		}
+		if mappings, ok := parsed.ClaimMappings["anoncreds"]; ok {
+			if mapped, ok := mappings[claim.Name]; ok {
+				attrName = mapped
+			}
+		}
+		if attrName == "" || seen[attrName] {
+			continue
+		}
+		seen[attrName] = true
+		names = append(names, attrName)
+	}
+	sort.Strings(names)
+	return names
+}
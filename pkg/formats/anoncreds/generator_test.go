@@ -0,0 +1,89 @@
+package anoncreds
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+)
+
+func TestGenerator_Metadata(t *testing.T) {
+	g := NewGenerator()
+	if g.Name() != "anoncreds" {
+		t.Errorf("Name() = %q, want anoncreds", g.Name())
+	}
+	if g.FileExtension() != "anoncreds.json" {
+		t.Errorf("FileExtension() = %q, want anoncreds.json", g.FileExtension())
+	}
+	if _, ok := formats.Get("anoncreds"); !ok {
+		t.Error("anoncreds generator should be registered")
+	}
+}
+
+func TestGenerator_DeriveIdentifier(t *testing.T) {
+	g := NewGenerator()
+	cred := &formats.ParsedCredential{
+		Name:     "Student Card",
+		Metadata: map[string]interface{}{"version": "2.1"},
+	}
+	if got := g.DeriveIdentifier(cred, &config.Config{}); got != "Student Card:2.1" {
+		t.Errorf("DeriveIdentifier() = %q, want Student Card:2.1", got)
+	}
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	g := NewGenerator()
+	cred := &formats.ParsedCredential{
+		ID:   "student",
+		Name: "Student Card",
+		Claims: []formats.ClaimDefinition{
+			{Name: "given_name"},
+			{Name: "family_name"},
+			{Name: "student_id"},
+		},
+		ClaimMappings: map[string]map[string]string{
+			"anoncreds": {"student_id": "studentNumber"},
+		},
+		FormatOverrides: map[string]map[string]interface{}{
+			"anoncreds": {"issuer_id": "did:example:issuer"},
+		},
+	}
+
+	data, err := g.Generate(cred, &config.Config{Language: "en-US"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var output Output
+	if err := json.Unmarshal(data, &output); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+
+	want := []string{"family_name", "given_name", "studentNumber"}
+	if len(output.Schema.AttrNames) != len(want) {
+		t.Fatalf("attrNames = %v, want %v", output.Schema.AttrNames, want)
+	}
+	for i := range want {
+		if output.Schema.AttrNames[i] != want[i] {
+			t.Errorf("attrNames[%d] = %q, want %q", i, output.Schema.AttrNames[i], want[i])
+		}
+	}
+
+	if output.Schema.Name != "Student Card" || output.Schema.Version != "1.0" {
+		t.Errorf("schema name/version = %q/%q", output.Schema.Name, output.Schema.Version)
+	}
+	if output.Schema.IssuerID != "did:example:issuer" || output.CredentialDefinition.IssuerID != "did:example:issuer" {
+		t.Errorf("issuerId not propagated: %+v", output)
+	}
+	if output.CredentialDefinition.Type != "CL" || output.CredentialDefinition.Tag != "default" {
+		t.Errorf("credentialDefinition = %+v", output.CredentialDefinition)
+	}
+}
+
+func TestGenerator_Generate_RequiresClaims(t *testing.T) {
+	g := NewGenerator()
+	if _, err := g.Generate(&formats.ParsedCredential{Name: "Empty"}, &config.Config{}); err == nil {
+		t.Error("expected error for credential without claims")
+	}
+}
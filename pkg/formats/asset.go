@@ -0,0 +1,32 @@
+package formats
+
+import (
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+)
+
+// HashedAssetPath returns path with a short content hash before its extension,
+// images/logo.png becoming images/logo.<crc32>.png, so that changed content gets
+// a new URL and published images can be cached indefinitely
+func HashedAssetPath(assetPath string, data []byte) string {
+	ext := path.Ext(assetPath)
+	return fmt.Sprintf("%s.%08x%s", strings.TrimSuffix(assetPath, ext), crc32.ChecksumIEEE(data), ext)
+}
+
+// AssetPath returns the path a local image is published under: assetPath itself,
+// or its HashedAssetPath when HashImageNames is set and localPath can be read
+func AssetPath(assetPath, localPath string, cfg *config.Config) string {
+	if !cfg.HashImageNames || IsRemoteURI(assetPath) {
+		return assetPath
+	}
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return assetPath
+	}
+	return HashedAssetPath(assetPath, data)
+}
@@ -0,0 +1,49 @@
+package formats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+)
+
+func TestHashedAssetPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"images/logo.png", "images/logo.e48e9a13.png"},
+		{"logo", "logo.e48e9a13"},
+		{"./card.v2.svg", "./card.v2.e48e9a13.svg"},
+	}
+	for _, tt := range tests {
+		if got := HashedAssetPath(tt.path, []byte("logo")); got != tt.want {
+			t.Errorf("HashedAssetPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	if HashedAssetPath("logo.png", []byte("other")) == HashedAssetPath("logo.png", []byte("logo")) {
+		t.Error("different content should give a different name")
+	}
+}
+
+func TestAssetPath(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "logo.png")
+	if err := os.WriteFile(local, []byte("logo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := AssetPath("images/logo.png", local, &config.Config{}); got != "images/logo.png" {
+		t.Errorf("AssetPath() without HashImageNames = %q", got)
+	}
+	cfg := &config.Config{HashImageNames: true}
+	if got := AssetPath("images/logo.png", local, cfg); got != "images/logo.e48e9a13.png" {
+		t.Errorf("AssetPath() = %q, want the hashed name", got)
+	}
+	if got := AssetPath("images/missing.png", local+".missing", cfg); got != "images/missing.png" {
+		t.Errorf("AssetPath() of an unreadable file = %q, want it unchanged", got)
+	}
+	if got := AssetPath("https://cdn.example.com/logo.png", local, cfg); got != "https://cdn.example.com/logo.png" {
+		t.Errorf("AssetPath() of a remote URI = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,72 @@
+package formats
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		want  string
+	}{
+		{
+			name: "nested keys sorted",
+			input: map[string]interface{}{
+				"b": map[string]interface{}{"z": 1, "a": []interface{}{map[string]interface{}{"y": true, "x": nil}}},
+				"a": "first",
+			},
+			want: `{"a":"first","b":{"a":[{"x":null,"y":true}],"z":1}}`,
+		},
+		{
+			name: "struct fields sorted",
+			input: struct {
+				Name string `json:"name"`
+				ID   string `json:"id"`
+			}{Name: "Identity", ID: "identity"},
+			want: `{"id":"identity","name":"Identity"}`,
+		},
+		{
+			name:  "keys ordered by UTF-16 code units",
+			input: map[string]interface{}{"\U0001F600": 1, "דּ": 2, "a": 3},
+			want:  `{"a":3,"` + "\U0001F600" + `":1,"` + "דּ" + `":2}`,
+		},
+		{
+			name:  "minimal string escaping",
+			input: "<a href=\"x\"> \t\u0001\\</a>",
+			want:  `"<a href=\"x\">` + " " + `\t\u0001\\</a>"`,
+		},
+		{
+			name:  "numbers in ECMAScript form",
+			input: []interface{}{1.0, -0.0, 0.5, 1e21, 1.5e-7, 123456789012, json.Number("1E2")},
+			want:  `[1,0,0.5,1e+21,1.5e-7,123456789012,100]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalJSON(tt.input)
+			if err != nil {
+				t.Fatalf("CanonicalJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("CanonicalJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatJSON_Canonical(t *testing.T) {
+	data := map[string]interface{}{"b": 1, "a": 2}
+
+	got, err := FormatJSON(data, &config.Config{Canonical: true, Indent: "4"})
+	if err != nil {
+		t.Fatalf("FormatJSON() error = %v", err)
+	}
+	if string(got) != `{"a":2,"b":1}` {
+		t.Errorf("FormatJSON(canonical) = %s", got)
+	}
+}
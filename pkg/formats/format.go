@@ -2,9 +2,15 @@
 package formats
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -34,6 +40,13 @@ type ParsedCredential struct {
 	LogoAltText     string
 	LogoAbsPath     string
 
+	// BackgroundImagePath is a local path or remote URL of the background image
+	BackgroundImagePath string
+
+	// ColorSchemes holds the display variants from the front matter colors block,
+	// keyed by ColorSchemeLight or ColorSchemeDark
+	ColorSchemes map[string]ColorScheme
+
 	// SVG Template for rendering
 	SVGTemplatePath      string
 	SVGTemplateURI       string
@@ -63,6 +76,42 @@ type ParsedCredential struct {
 
 	// Raw metadata from front matter
 	Metadata map[string]interface{}
+
+	// Extends lists the parent type identifiers in declaration order
+	Extends []string
+
+	// Profiles lists the deployment profiles the credential is tagged with
+	Profiles []string
+
+	// CredentialSchema references an external credential schema, if declared
+	CredentialSchema *CredentialSchemaRef
+
+	// Warnings collects non-fatal issues found while converting the source
+	Warnings []string
+
+	// Integrity, when set, computes SRI integrity hashes of local files, e.g.
+	// from a cache shared across the credentials of a batch run
+	Integrity IntegritySource `json:"-"`
+}
+
+// IntegritySource computes the SRI integrity hash of a local file
+type IntegritySource interface {
+	Get(path string) (string, error)
+}
+
+// CredentialSchemaRef references an external credential schema by URL
+type CredentialSchemaRef struct {
+	// ID is the URL of the schema
+	ID string
+
+	// Type is the schema type, e.g. JsonSchema
+	Type string
+
+	// Path is an optional local copy of the schema, relative to the source directory
+	Path string
+
+	// Integrity is an explicit SRI integrity hash of the schema
+	Integrity string
 }
 
 // DisplayLocalization contains localized display properties
@@ -76,8 +125,9 @@ type ClaimDefinition struct {
 	// Name is the claim identifier
 	Name string
 
-	// Path for nested claims (e.g., ["address", "street"])
-	Path []string
+	// Path for nested claims (e.g., ["address", "street"] or ["addresses", 0])
+	// Elements are strings for object keys and ints for array indices
+	Path []interface{}
 
 	// DisplayName is the human-readable label
 	DisplayName string
@@ -97,6 +147,34 @@ type ClaimDefinition struct {
 	// SvgId for SVG template reference
 	SvgId string
 
+	// Group is a UI grouping hint such as "Personal"
+	Group string
+
+	// Const is a fixed value the claim always has
+	Const string
+
+	// Example is a single example value
+	Example string
+
+	// Examples are additional example values
+	Examples []string
+
+	// Enum lists the allowed values
+	Enum []string
+
+	// Min and Max bound numeric values (inclusive)
+	Min string
+	Max string
+
+	// Pattern is a regular expression string values must match
+	Pattern string
+
+	// Order is the claim's display position, 0 when not annotated
+	Order int
+
+	// Hidden omits the claim from display metadata; schemas still include it
+	Hidden bool
+
 	// Localizations per locale
 	Localizations map[string]ClaimLocalization
 
@@ -110,11 +188,37 @@ type ClaimLocalization struct {
 	Description string
 }
 
+// Color schemes of display variants
+const (
+	ColorSchemeLight = "light"
+	ColorSchemeDark  = "dark"
+)
+
+// ColorScheme holds the colors and logo of a light or dark display variant
+type ColorScheme struct {
+	BackgroundColor string
+	TextColor       string
+	LogoPath        string
+	LogoAltText     string
+}
+
+// ImageRoleLogo marks the image to use as the credential logo, written as
+// ![Logo](logo.png){role=logo} in markdown
+const ImageRoleLogo = "logo"
+
 // ImageRef represents an image reference
 type ImageRef struct {
 	Path         string
 	AltText      string
 	AbsolutePath string
+
+	// Role is the purpose the image is marked with, e.g. ImageRoleLogo
+	Role string
+
+	// SVG template properties
+	Orientation string
+	ColorScheme string
+	Contrast    string
 }
 
 // Generator is the interface for format-specific generators
@@ -135,6 +239,34 @@ type Generator interface {
 	DeriveIdentifier(parsed *ParsedCredential, cfg *config.Config) string
 }
 
+// ExtensionProvider is implemented by generators whose output file extension
+// depends on the configuration, e.g. on the selected encoding
+type ExtensionProvider interface {
+	// FileExtensionFor returns the output file extension (without dot) for cfg
+	FileExtensionFor(cfg *config.Config) string
+}
+
+// CredentialConfigurationProvider is implemented by generators that can describe their
+// output as an OpenID4VCI credential configuration (credential_configurations_supported entry)
+type CredentialConfigurationProvider interface {
+	// CredentialConfiguration returns the issuer metadata entry for the parsed credential
+	CredentialConfiguration(parsed *ParsedCredential, cfg *config.Config) (map[string]interface{}, error)
+}
+
+// SchemaProvider is implemented by generators whose output references a standalone
+// JSON Schema document, written alongside the output as <name>.schema.json
+type SchemaProvider interface {
+	// Schema returns the JSON Schema document, or nil if the credential has none
+	Schema(parsed *ParsedCredential, cfg *config.Config) ([]byte, error)
+}
+
+// ExampleProvider is implemented by generators that can produce a sample instance
+// of the credential, written alongside the output as <name>.example.json
+type ExampleProvider interface {
+	// Example returns the sample instance, or nil if the credential has no claims
+	Example(parsed *ParsedCredential, cfg *config.Config) ([]byte, error)
+}
+
 // Registry holds all registered format generators
 type Registry struct {
 	mu         sync.RWMutex
@@ -238,7 +370,195 @@ func ParseFormats(formatStr string) ([]string, error) {
 	return DefaultRegistry.ParseFormats(formatStr)
 }
 
-// FormatJSON is a helper to marshal data as indented JSON
-func FormatJSON(data interface{}) ([]byte, error) {
-	return json.MarshalIndent(data, "", "  ")
+// DefaultLocale returns the locale of the credential's default display, which
+// carries the name, description and claim labels from the markdown body
+func DefaultLocale(parsed *ParsedCredential, cfg *config.Config) string {
+	locales := make([]string, 0, len(parsed.Localizations))
+	for locale := range parsed.Localizations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return cfg.DefaultLocale(locales)
+}
+
+// CredentialDisplay builds the credential-level display array used in issuer metadata
+func CredentialDisplay(parsed *ParsedCredential, cfg *config.Config) []map[string]interface{} {
+	language := DefaultLocale(parsed, cfg)
+	display := map[string]interface{}{
+		"name":   parsed.Name,
+		"locale": language,
+	}
+	if parsed.Description != "" {
+		display["description"] = parsed.Description
+	}
+	if parsed.BackgroundColor != "" {
+		display["background_color"] = parsed.BackgroundColor
+	}
+	if parsed.TextColor != "" {
+		display["text_color"] = parsed.TextColor
+	}
+	displays := []map[string]interface{}{display}
+
+	locales := make([]string, 0, len(parsed.Localizations))
+	for locale := range parsed.Localizations {
+		if locale != language {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		loc := parsed.Localizations[locale]
+		localized := map[string]interface{}{
+			"name":   loc.Name,
+			"locale": locale,
+		}
+		if loc.Description != "" {
+			localized["description"] = loc.Description
+		}
+		displays = append(displays, localized)
+	}
+
+	return displays
+}
+
+// IsRemoteURI reports whether s is an absolute http(s) or data URI rather than a local path
+func IsRemoteURI(s string) bool {
+	lower := strings.ToLower(s)
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "data:")
+}
+
+// FileIntegrity returns the SRI integrity hash of a local file referenced by the
+// credential, using its IntegritySource when set
+func (p *ParsedCredential) FileIntegrity(path string) (string, error) {
+	if p.Integrity != nil {
+		return p.Integrity.Get(path)
+	}
+	return FileIntegrity(path)
+}
+
+// FileIntegrity calculates the SRI integrity hash (sha256) of a local file
+func FileIntegrity(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return "sha256-" + base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
+}
+
+// claimIndexPattern matches a claim path segment with array selectors, e.g. addresses[0] or nationalities[]
+var claimIndexPattern = regexp.MustCompile(`^([^\[\]]*)((?:\[\d*\])+)$`)
+
+// claimSelectorPattern matches a single array selector within a path segment
+var claimSelectorPattern = regexp.MustCompile(`\[(\d*)\]`)
+
+// ParseClaimPath splits a claim name into path elements. Dots separate object keys,
+// bracketed indices such as addresses[0] become integer path elements and empty
+// brackets such as nationalities[] become null, selecting all array elements.
+func ParseClaimPath(name string) []interface{} {
+	var path []interface{}
+	for _, segment := range strings.Split(name, ".") {
+		matches := claimIndexPattern.FindStringSubmatch(segment)
+		if matches == nil {
+			path = append(path, segment)
+			continue
+		}
+		if matches[1] != "" {
+			path = append(path, matches[1])
+		}
+		for _, selector := range claimSelectorPattern.FindAllStringSubmatch(matches[2], -1) {
+			if selector[1] == "" {
+				path = append(path, nil)
+				continue
+			}
+			i, _ := strconv.Atoi(selector[1])
+			path = append(path, i)
+		}
+	}
+	return path
+}
+
+// ClaimPathName is the inverse of ParseClaimPath: it joins path elements into a
+// claim name, writing integer elements as [i] and null elements as []
+func ClaimPathName(path []interface{}) string {
+	var sb strings.Builder
+	for i, element := range path {
+		switch e := element.(type) {
+		case nil:
+			sb.WriteString("[]")
+		case int:
+			fmt.Fprintf(&sb, "[%d]", e)
+		case int64:
+			fmt.Fprintf(&sb, "[%d]", e)
+		case float64:
+			fmt.Fprintf(&sb, "[%d]", int(e))
+		default:
+			if i > 0 {
+				sb.WriteString(".")
+			}
+			fmt.Fprintf(&sb, "%v", e)
+		}
+	}
+	return sb.String()
+}
+
+// HasIndexedPath reports whether the claim path addresses a specific array element.
+// Null elements selecting all array elements are not indexed.
+func (c ClaimDefinition) HasIndexedPath() bool {
+	for _, element := range c.Path {
+		if _, ok := element.(int); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// HasProfile reports whether the credential is tagged with the given profile
+func (c *ParsedCredential) HasProfile(profile string) bool {
+	for _, p := range c.Profiles {
+		if strings.EqualFold(p, profile) {
+			return true
+		}
+	}
+	return false
+}
+
+// TypedValue converts a raw markdown value into a JSON value matching the claim type.
+// Values that cannot be converted are returned unchanged as strings.
+func TypedValue(claimType, raw string) interface{} {
+	switch strings.ToLower(claimType) {
+	case "integer":
+		if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f
+		}
+	case "boolean", "bool":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// FormatJSON is a helper to marshal data as indented JSON using the configured indentation,
+// or as CanonicalJSON when the config asks for canonical output.
+// A nil config uses the default two-space indentation.
+func FormatJSON(data interface{}, cfg *config.Config) ([]byte, error) {
+	if cfg != nil && cfg.Canonical {
+		return CanonicalJSON(data)
+	}
+	indent := config.DefaultIndent
+	if cfg != nil {
+		indent = cfg.GetIndent()
+	}
+	return json.MarshalIndent(data, "", indent)
 }
@@ -252,7 +252,7 @@ func TestFormatJSON(t *testing.T) {
 		"value": 42,
 	}
 
-	output, err := FormatJSON(data)
+	output, err := FormatJSON(data, nil)
 	if err != nil {
 		t.Fatalf("FormatJSON() error = %v", err)
 	}
@@ -274,10 +274,95 @@ func TestFormatJSON(t *testing.T) {
 	}
 }
 
+func TestFormatJSON_Indent(t *testing.T) {
+	data := map[string]interface{}{"name": "test"}
+
+	tests := []struct {
+		indent string
+		want   string
+	}{
+		{"", "{\n  \"name\": \"test\"\n}"},
+		{"4", "{\n    \"name\": \"test\"\n}"},
+		{"tab", "{\n\t\"name\": \"test\"\n}"},
+	}
+
+	for _, tt := range tests {
+		output, err := FormatJSON(data, &config.Config{Indent: tt.indent})
+		if err != nil {
+			t.Fatalf("FormatJSON() error = %v", err)
+		}
+		if string(output) != tt.want {
+			t.Errorf("FormatJSON() with indent %q = %q, want %q", tt.indent, output, tt.want)
+		}
+	}
+}
+
+func TestTypedValue(t *testing.T) {
+	tests := []struct {
+		claimType string
+		raw       string
+		want      interface{}
+	}{
+		{"string", "42", "42"},
+		{"integer", "42", int64(42)},
+		{"number", "1.5", 1.5},
+		{"boolean", "true", true},
+		{"integer", "not-a-number", "not-a-number"},
+	}
+
+	for _, tt := range tests {
+		if got := TypedValue(tt.claimType, tt.raw); got != tt.want {
+			t.Errorf("TypedValue(%q, %q) = %v (%T), want %v (%T)", tt.claimType, tt.raw, got, got, tt.want, tt.want)
+		}
+	}
+}
+
+func TestParseClaimPath(t *testing.T) {
+	tests := []struct {
+		name string
+		want []interface{}
+	}{
+		{"given_name", []interface{}{"given_name"}},
+		{"address.street", []interface{}{"address", "street"}},
+		{"addresses[0]", []interface{}{"addresses", 0}},
+		{"addresses[1].street", []interface{}{"addresses", 1, "street"}},
+		{"matrix[2][3]", []interface{}{"matrix", 2, 3}},
+		{"odd[x]", []interface{}{"odd[x]"}},
+		{"nationalities[]", []interface{}{"nationalities", nil}},
+		{"addresses[].street", []interface{}{"addresses", nil, "street"}},
+		{"matrix[0][]", []interface{}{"matrix", 0, nil}},
+	}
+
+	for _, tt := range tests {
+		got := ParseClaimPath(tt.name)
+		if len(got) != len(tt.want) {
+			t.Errorf("ParseClaimPath(%q) = %#v, want %#v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ParseClaimPath(%q)[%d] = %#v, want %#v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestClaimPathName(t *testing.T) {
+	for _, name := range []string{"given_name", "address.street", "addresses[1].street", "matrix[0][]", "addresses[].street"} {
+		if got := ClaimPathName(ParseClaimPath(name)); got != name {
+			t.Errorf("ClaimPathName(ParseClaimPath(%q)) = %q", name, got)
+		}
+	}
+	// Indices decoded from JSON are float64
+	if got := ClaimPathName([]interface{}{"addresses", float64(2), "city"}); got != "addresses[2].city" {
+		t.Errorf("ClaimPathName() = %q, want addresses[2].city", got)
+	}
+}
+
 func TestFormatJSON_InvalidData(t *testing.T) {
 	// Channels cannot be marshaled to JSON
 	data := make(chan int)
-	_, err := FormatJSON(data)
+	_, err := FormatJSON(data, nil)
 	if err == nil {
 		t.Error("Expected error for unmarshallable data")
 	}
@@ -323,7 +408,7 @@ func TestParsedCredential_Fields(t *testing.T) {
 		Claims: []ClaimDefinition{
 			{
 				Name:        "given_name",
-				Path:        []string{"given_name"},
+				Path:        []interface{}{"given_name"},
 				DisplayName: "Given Name",
 				Type:        "string",
 				Mandatory:   true,
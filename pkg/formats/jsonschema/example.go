@@ -0,0 +1,138 @@
+package jsonschema
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+)
+
+// ExampleInstance builds a sample object matching ClaimsSchema(parsed, formatName),
+// with a value for every claim. Each claim uses its const, its first example or
+// its first allowed value, falling back to a placeholder of its type.
+func ExampleInstance(parsed *formats.ParsedCredential, formatName string) map[string]interface{} {
+	instance := make(map[string]interface{})
+
+	for _, claim := range parsed.Claims {
+		// Get claim name, applying format mapping if present
+		path := claim.Path
+		if len(path) == 0 {
+			path = []interface{}{claim.Name}
+		}
+		if mapping, ok := claim.FormatMappings[formatName]; ok {
+			path = []interface{}{mapping}
+		}
+		if mappings, ok := parsed.ClaimMappings[formatName]; ok {
+			if mappedName, ok := mappings[claim.Name]; ok {
+				path = []interface{}{mappedName}
+			}
+		}
+
+		name, ok := path[0].(string)
+		if !ok {
+			instance[claim.Name] = ExampleValue(claim)
+			continue
+		}
+		instance[name] = placeExample(instance[name], path[1:], ExampleValue(claim))
+	}
+
+	return instance
+}
+
+// ExampleValue returns a value for a claim that satisfies its schema: the const,
+// the first example, the first allowed value or a placeholder of the claim type
+func ExampleValue(claim formats.ClaimDefinition) interface{} {
+	if claim.Const != "" {
+		return formats.TypedValue(claim.Type, claim.Const)
+	}
+	if examples := claimExamples(claim); len(examples) > 0 {
+		return examples[0]
+	}
+	if len(claim.Enum) > 0 {
+		return formats.TypedValue(claim.Type, claim.Enum[0])
+	}
+
+	switch strings.ToLower(claim.Type) {
+	case "number":
+		return exampleNumber(claim)
+	case "integer":
+		return int64(math.Ceil(exampleNumber(claim)))
+	case "boolean", "bool":
+		return true
+	case "date":
+		return "2000-01-01"
+	case "datetime":
+		return "2000-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uri":
+		return "https://example.com"
+	case "uuid":
+		return "123e4567-e89b-12d3-a456-426614174000"
+	case "phone":
+		return "+46701234567"
+	case "image":
+		return ""
+	case "object":
+		return map[string]interface{}{}
+	case "array":
+		return []interface{}{}
+	default:
+		return "example"
+	}
+}
+
+// exampleNumber returns zero, moved into the claim's bounds when they exclude it
+func exampleNumber(claim formats.ClaimDefinition) float64 {
+	if n, err := strconv.ParseFloat(claim.Min, 64); err == nil && n > 0 {
+		return n
+	}
+	if n, err := strconv.ParseFloat(claim.Max, 64); err == nil && n < 0 {
+		return n
+	}
+	return 0
+}
+
+// placeExample places value at the given path below parent, mirroring
+// placeNestedProperty: integer elements index arrays, null elements become a
+// single-element array and string elements object members. A container already
+// built from child claims is kept over an empty object or array placeholder.
+func placeExample(parent interface{}, path []interface{}, value interface{}) interface{} {
+	if len(path) == 0 {
+		switch parent.(type) {
+		case map[string]interface{}, []interface{}:
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				return parent
+			}
+		}
+		return value
+	}
+
+	switch element := path[0].(type) {
+	case nil:
+		items, _ := parent.([]interface{})
+		if len(items) == 0 {
+			items = []interface{}{nil}
+		}
+		items[0] = placeExample(items[0], path[1:], value)
+		return items
+	case int:
+		items, _ := parent.([]interface{})
+		for len(items) <= element {
+			items = append(items, nil)
+		}
+		items[element] = placeExample(items[element], path[1:], value)
+		return items
+	case string:
+		members, ok := parent.(map[string]interface{})
+		if !ok {
+			members = make(map[string]interface{})
+		}
+		members[element] = placeExample(members[element], path[1:], value)
+		return members
+	}
+
+	return parent
+}
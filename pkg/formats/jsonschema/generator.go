@@ -0,0 +1,88 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+)
+
+func init() {
+	formats.Register(NewGenerator())
+}
+
+// Generator implements the standalone JSON Schema format generator
+type Generator struct{}
+
+// NewGenerator creates a new JSON Schema generator
+func NewGenerator() *Generator {
+	return &Generator{}
+}
+
+// Name returns the format identifier
+func (g *Generator) Name() string {
+	return "jsonschema"
+}
+
+// Description returns a human-readable description
+func (g *Generator) Description() string {
+	return "JSON Schema (draft 2020-12) for validating credential payloads"
+}
+
+// FileExtension returns the output file extension. The plain .schema.json name is
+// taken by the schema the w3c format references as credentialSchema.
+func (g *Generator) FileExtension() string {
+	return "claims.schema.json"
+}
+
+// DeriveIdentifier returns the schema $id: <base_url>/<id>.claims.schema.json, or
+// the file name when no base URL is configured
+func (g *Generator) DeriveIdentifier(parsed *formats.ParsedCredential, cfg *config.Config) string {
+	name := parsed.ID + "." + g.FileExtension()
+	if cfg.BaseURL == "" {
+		return name
+	}
+	return strings.TrimSuffix(cfg.BaseURL, "/") + "/" + name
+}
+
+// Document is a JSON Schema document whose properties validate the claims of a
+// credential payload
+type Document struct {
+	Schema      string               `json:"$schema"`
+	ID          string               `json:"$id,omitempty"`
+	Title       string               `json:"title,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Type        string               `json:"type"`
+	Properties  map[string]*Property `json:"properties"`
+	Required    []string             `json:"required,omitempty"`
+
+	// propertyOrder keeps the claim definition order of Properties
+	propertyOrder []string
+}
+
+// MarshalJSON emits properties in claim definition order
+func (d Document) MarshalJSON() ([]byte, error) {
+	type alias Document
+	return json.Marshal(struct {
+		*alias
+		Properties *orderedProperties `json:"properties"`
+	}{alias: (*alias)(&d), Properties: &orderedProperties{order: d.propertyOrder, properties: d.Properties}})
+}
+
+// Generate produces the JSON Schema of the credential claims
+func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Config) ([]byte, error) {
+	claims := ClaimsSchema(parsed, g.Name())
+	doc := &Document{
+		Schema:        Dialect,
+		ID:            g.DeriveIdentifier(parsed, cfg),
+		Title:         parsed.Name,
+		Description:   parsed.Description,
+		Type:          "object",
+		Properties:    claims.Properties,
+		Required:      claims.Required,
+		propertyOrder: claims.propertyOrder,
+	}
+
+	return formats.FormatJSON(doc, cfg)
+}
@@ -0,0 +1,130 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	validator "github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// identityCredential has top-level, nested and indexed claims with constraints
+func identityCredential() *formats.ParsedCredential {
+	return &formats.ParsedCredential{
+		ID:          "identity",
+		Name:        "Identity Credential",
+		Description: "A basic identity credential",
+		Claims: []formats.ClaimDefinition{
+			{Name: "given_name", Path: []interface{}{"given_name"}, DisplayName: "Given Name", Type: "string", Mandatory: true},
+			{Name: "birth_date", Path: []interface{}{"birth_date"}, Type: "date", Mandatory: true, Example: "1990-01-31"},
+			{Name: "age", Path: []interface{}{"age"}, Type: "integer", Min: "0", Max: "150"},
+			{Name: "email", Path: []interface{}{"email"}, Type: "email"},
+			{Name: "address.country", Path: []interface{}{"address", "country"}, Type: "string", Enum: []string{"SE", "DE", "FR"}, Mandatory: true},
+			{Name: "address.postal_code", Path: []interface{}{"address", "postal_code"}, Type: "string", Pattern: `^\d{5}$`},
+			{Name: "nationalities[]", Path: []interface{}{"nationalities", nil}, Type: "string"},
+		},
+	}
+}
+
+func TestGenerator_Metadata(t *testing.T) {
+	g := NewGenerator()
+	if g.Name() != "jsonschema" {
+		t.Errorf("Name() = %q, want jsonschema", g.Name())
+	}
+	if g.FileExtension() != "claims.schema.json" {
+		t.Errorf("FileExtension() = %q, want claims.schema.json", g.FileExtension())
+	}
+	if _, ok := formats.Get("jsonschema"); !ok {
+		t.Error("jsonschema format is not registered")
+	}
+
+	cred := &formats.ParsedCredential{ID: "identity"}
+	if got := g.DeriveIdentifier(cred, &config.Config{}); got != "identity.claims.schema.json" {
+		t.Errorf("DeriveIdentifier() = %q without base URL", got)
+	}
+	if got := g.DeriveIdentifier(cred, &config.Config{BaseURL: "https://example.com/"}); got != "https://example.com/identity.claims.schema.json" {
+		t.Errorf("DeriveIdentifier() = %q with base URL", got)
+	}
+}
+
+func TestGenerator_Generate_Golden(t *testing.T) {
+	output, err := NewGenerator().Generate(identityCredential(), &config.Config{BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	golden := filepath.Join("testdata", "identity.claims.schema.json")
+	if *update {
+		if err := os.WriteFile(golden, output, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(output, want) {
+		t.Errorf("Generate() output differs from %s:\n%s", golden, output)
+	}
+}
+
+func TestGenerator_Generate_ValidatesInstances(t *testing.T) {
+	output, err := NewGenerator().Generate(identityCredential(), &config.Config{BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	doc, err := validator.UnmarshalJSON(bytes.NewReader(output))
+	if err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	compiler := validator.NewCompiler()
+	compiler.AssertFormat()
+	const url = "https://example.com/identity.claims.schema.json"
+	if err := compiler.AddResource(url, doc); err != nil {
+		t.Fatalf("AddResource() error = %v", err)
+	}
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		t.Fatalf("generated schema does not compile: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		instance string
+		valid    bool
+	}{
+		{"conformant", `{"given_name": "Alice", "birth_date": "1990-01-31", "age": 34, "email": "alice@example.com",
+			"address": {"country": "SE", "postal_code": "12345"}, "nationalities": ["SE", "FI"]}`, true},
+		{"minimal", `{"given_name": "Alice", "birth_date": "1990-01-31", "address": {"country": "DE"}}`, true},
+		{"missing required", `{"given_name": "Alice", "address": {"country": "DE"}}`, false},
+		{"nested enum", `{"given_name": "Alice", "birth_date": "1990-01-31", "address": {"country": "US"}}`, false},
+		{"above maximum", `{"given_name": "Alice", "birth_date": "1990-01-31", "age": 200, "address": {"country": "SE"}}`, false},
+		{"pattern", `{"given_name": "Alice", "birth_date": "1990-01-31", "address": {"country": "SE", "postal_code": "1234"}}`, false},
+		{"date format", `{"given_name": "Alice", "birth_date": "31.01.1990", "address": {"country": "SE"}}`, false},
+		{"email format", `{"given_name": "Alice", "birth_date": "1990-01-31", "email": "alice", "address": {"country": "SE"}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var instance interface{}
+			if err := json.Unmarshal([]byte(tt.instance), &instance); err != nil {
+				t.Fatal(err)
+			}
+			err := schema.Validate(instance)
+			if tt.valid && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("Validate() accepted an invalid instance")
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+// Package jsonschema builds JSON Schema (draft 2020-12) documents from credential
+// claims and provides the standalone JSON Schema format generator
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+)
+
+// Dialect is the JSON Schema version of generated schemas
+const Dialect = "https://json-schema.org/draft/2020-12/schema"
+
+// PhonePattern matches phone numbers in E.164 form with an optional leading plus
+const PhonePattern = `^\+?[0-9]{7,15}$`
+
+// Property represents a JSON Schema property
+type Property struct {
+	Type            string               `json:"type,omitempty"`
+	Title           string               `json:"title,omitempty"`
+	Description     string               `json:"description,omitempty"`
+	Format          string               `json:"format,omitempty"`
+	ContentEncoding string               `json:"contentEncoding,omitempty"`
+	Const           interface{}          `json:"const,omitempty"`
+	Examples        []interface{}        `json:"examples,omitempty"`
+	Enum            []interface{}        `json:"enum,omitempty"`
+	Minimum         *float64             `json:"minimum,omitempty"`
+	Maximum         *float64             `json:"maximum,omitempty"`
+	Pattern         string               `json:"pattern,omitempty"`
+	Items           *Property            `json:"items,omitempty"`
+	PrefixItems     []*Property          `json:"prefixItems,omitempty"`
+	Properties      map[string]*Property `json:"properties,omitempty"`
+	Required        []string             `json:"required,omitempty"`
+	Group           string               `json:"x-group,omitempty"`
+	SvgID           string               `json:"x-svg-id,omitempty"`
+
+	// propertyOrder records the order properties were added in, so that they are
+	// emitted in claim definition order rather than alphabetically
+	propertyOrder []string
+}
+
+// SetProperty sets a property, recording its position the first time it is added
+func (p *Property) SetProperty(name string, prop *Property) {
+	if p.Properties == nil {
+		p.Properties = make(map[string]*Property)
+	}
+	if _, ok := p.Properties[name]; !ok {
+		p.propertyOrder = append(p.propertyOrder, name)
+	}
+	p.Properties[name] = prop
+}
+
+// MarshalJSON emits properties in the order they were added; properties set
+// directly on the map without SetProperty follow sorted by name
+func (p Property) MarshalJSON() ([]byte, error) {
+	type alias Property
+	var props *orderedProperties
+	if len(p.Properties) > 0 {
+		props = &orderedProperties{order: p.propertyOrder, properties: p.Properties}
+	}
+	return json.Marshal(struct {
+		*alias
+		Properties *orderedProperties `json:"properties,omitempty"`
+	}{alias: (*alias)(&p), Properties: props})
+}
+
+// orderedProperties marshals a property map as a JSON object with ordered keys
+type orderedProperties struct {
+	order      []string
+	properties map[string]*Property
+}
+
+// MarshalJSON implements json.Marshaler
+func (o *orderedProperties) MarshalJSON() ([]byte, error) {
+	names := make([]string, 0, len(o.properties))
+	seen := make(map[string]bool, len(o.properties))
+	for _, name := range o.order {
+		if _, ok := o.properties[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+	var rest []string
+	for name := range o.properties {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	names = append(names, rest...)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.properties[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ClaimsSchema builds an object schema with a property per claim, applying the claim
+// name mappings of formatName. Nested paths such as address.street become object
+// properties and indexed paths such as addresses[0] become arrays with prefixItems.
+func ClaimsSchema(parsed *formats.ParsedCredential, formatName string) *Property {
+	schema := &Property{
+		Type:       "object",
+		Properties: make(map[string]*Property),
+	}
+
+	for _, claim := range parsed.Claims {
+		// Get claim name, applying format mapping if present
+		claimName := claim.Name
+		mapped := false
+		if mapping, ok := claim.FormatMappings[formatName]; ok {
+			claimName = mapping
+			mapped = true
+		}
+		// Also check ClaimMappings from parsed credential
+		if mappings, ok := parsed.ClaimMappings[formatName]; ok {
+			if mappedName, ok := mappings[claim.Name]; ok {
+				claimName = mappedName
+				mapped = true
+			}
+		}
+
+		prop := ClaimProperty(claim)
+
+		if !mapped && len(claim.Path) > 1 {
+			if name, ok := claim.Path[0].(string); ok {
+				claimName = name
+				schema.SetProperty(claimName, placeNestedProperty(schema.Properties[claimName], claim.Path[1:], prop))
+				if claim.Mandatory && !containsString(schema.Required, claimName) {
+					schema.Required = append(schema.Required, claimName)
+				}
+				continue
+			}
+		}
+
+		schema.SetProperty(claimName, mergeProperty(schema.Properties[claimName], prop))
+
+		if claim.Mandatory {
+			schema.Required = append(schema.Required, claimName)
+		}
+	}
+
+	return schema
+}
+
+// ClaimProperty returns the schema of a single claim: its mapped type with title,
+// description, const, examples, group, svg id and value constraints
+func ClaimProperty(claim formats.ClaimDefinition) *Property {
+	prop := MapType(claim.Type)
+	prop.Title = claim.DisplayName
+	if prop.Title == "" {
+		prop.Title = claim.Name
+	}
+	prop.Description = claim.Description
+	if claim.Const != "" {
+		prop.Const = formats.TypedValue(claim.Type, claim.Const)
+	}
+	prop.Examples = claimExamples(claim)
+	prop.Group = claim.Group
+	prop.SvgID = claim.SvgId
+	applyConstraints(prop, claim)
+	return prop
+}
+
+// MapType maps markdown types to JSON Schema properties
+func MapType(mdType string) *Property {
+	switch strings.ToLower(mdType) {
+	case "string":
+		return &Property{Type: "string"}
+	case "number":
+		return &Property{Type: "number"}
+	case "integer":
+		return &Property{Type: "integer"}
+	case "boolean", "bool":
+		return &Property{Type: "boolean"}
+	case "date":
+		return &Property{Type: "string", Format: "date"}
+	case "datetime":
+		return &Property{Type: "string", Format: "date-time"}
+	case "email":
+		return &Property{Type: "string", Format: "email"}
+	case "uri":
+		return &Property{Type: "string", Format: "uri"}
+	case "uuid":
+		return &Property{Type: "string", Format: "uuid"}
+	case "phone":
+		// JSON Schema has no phone format; accept E.164-style numbers
+		return &Property{Type: "string", Pattern: PhonePattern}
+	case "image":
+		return &Property{Type: "string", ContentEncoding: "base64"}
+	case "object":
+		return &Property{Type: "object"}
+	case "array":
+		return &Property{Type: "array", Items: &Property{Type: "string"}}
+	default:
+		return &Property{Type: "string"}
+	}
+}
+
+// placeNestedProperty places prop at the given path below parent, creating array
+// schemas with prefixItems for integer elements, array schemas with items for null
+// elements and object schemas for string elements
+func placeNestedProperty(parent *Property, path []interface{}, prop *Property) *Property {
+	if len(path) == 0 {
+		return mergeProperty(parent, prop)
+	}
+
+	switch element := path[0].(type) {
+	case nil:
+		if parent == nil || parent.Type != "array" {
+			parent = &Property{Type: "array"}
+		}
+		parent.Items = placeNestedProperty(parent.Items, path[1:], prop)
+	case int:
+		if parent == nil || parent.Type != "array" {
+			parent = &Property{Type: "array"}
+		}
+		parent.Items = nil
+		for len(parent.PrefixItems) <= element {
+			// Unspecified positions accept any value
+			parent.PrefixItems = append(parent.PrefixItems, &Property{})
+		}
+		parent.PrefixItems[element] = placeNestedProperty(nilIfEmpty(parent.PrefixItems[element]), path[1:], prop)
+	case string:
+		if parent == nil || parent.Type != "object" {
+			parent = &Property{Type: "object"}
+		}
+		parent.SetProperty(element, placeNestedProperty(parent.Properties[element], path[1:], prop))
+	}
+
+	return parent
+}
+
+// mergeProperty returns prop, keeping the children already placed in existing so
+// that a parent claim defined after its child claims does not discard them
+func mergeProperty(existing, prop *Property) *Property {
+	if existing == nil {
+		return prop
+	}
+	if prop.Properties == nil {
+		prop.Properties = existing.Properties
+		prop.propertyOrder = existing.propertyOrder
+	}
+	if prop.PrefixItems == nil {
+		prop.PrefixItems = existing.PrefixItems
+	}
+	if prop.Required == nil {
+		prop.Required = existing.Required
+	}
+	return prop
+}
+
+// nilIfEmpty returns nil for placeholder schemas without a type
+func nilIfEmpty(prop *Property) *Property {
+	if prop != nil && prop.Type == "" {
+		return nil
+	}
+	return prop
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// applyConstraints sets the enum, minimum, maximum and pattern keywords from the
+// claim's value constraints. The parser has already checked that bounds are numbers.
+func applyConstraints(prop *Property, claim formats.ClaimDefinition) {
+	for _, value := range claim.Enum {
+		prop.Enum = append(prop.Enum, formats.TypedValue(claim.Type, value))
+	}
+	if n, err := strconv.ParseFloat(claim.Min, 64); err == nil {
+		prop.Minimum = &n
+	}
+	if n, err := strconv.ParseFloat(claim.Max, 64); err == nil {
+		prop.Maximum = &n
+	}
+	if claim.Pattern != "" {
+		prop.Pattern = claim.Pattern
+	}
+}
+
+// claimExamples returns the typed example values of a claim, single example first
+func claimExamples(claim formats.ClaimDefinition) []interface{} {
+	raw := claim.Examples
+	if claim.Example != "" {
+		raw = append([]string{claim.Example}, raw...)
+	}
+
+	var examples []interface{}
+	seen := make(map[string]bool, len(raw))
+	for _, example := range raw {
+		if seen[example] {
+			continue
+		}
+		seen[example] = true
+		examples = append(examples, formats.TypedValue(claim.Type, example))
+	}
+	return examples
+}
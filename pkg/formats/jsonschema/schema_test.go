@@ -0,0 +1,57 @@
+package jsonschema
+
+import "testing"
+
+func TestMapType(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantType string
+		wantFmt  string
+	}{
+		{"string", "string", ""},
+		{"STRING", "string", ""},
+		{"number", "number", ""},
+		{"integer", "integer", ""},
+		{"boolean", "boolean", ""},
+		{"bool", "boolean", ""},
+		{"date", "string", "date"},
+		{"datetime", "string", "date-time"},
+		{"email", "string", "email"},
+		{"uri", "string", "uri"},
+		{"UUID", "string", "uuid"},
+		{"phone", "string", ""}, // has pattern
+		{"image", "string", ""}, // has contentEncoding
+		{"object", "object", ""},
+		{"array", "array", ""},
+		{"unknown", "string", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			prop := MapType(tt.input)
+			if prop.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", prop.Type, tt.wantType)
+			}
+			if prop.Format != tt.wantFmt {
+				t.Errorf("Format = %q, want %q", prop.Format, tt.wantFmt)
+			}
+		})
+	}
+}
+
+func TestMapType_ImageEncoding(t *testing.T) {
+	prop := MapType("image")
+	if prop.ContentEncoding != "base64" {
+		t.Errorf("ContentEncoding = %q, want 'base64'", prop.ContentEncoding)
+	}
+}
+
+func TestMapType_ArrayItems(t *testing.T) {
+	prop := MapType("array")
+	if prop.Items == nil {
+		t.Fatal("Items should not be nil for array type")
+	}
+	if prop.Items.Type != "string" {
+		t.Errorf("Items.Type = %q, want 'string'", prop.Items.Type)
+	}
+}
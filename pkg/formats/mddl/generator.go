@@ -2,10 +2,14 @@
 package mddl
 
 import (
-	"encoding/json"
+	"encoding/base64"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/sirosfoundation/mtcvctm/pkg/config"
 	"github.com/sirosfoundation/mtcvctm/pkg/formats"
 )
@@ -37,6 +41,14 @@ func (g *Generator) FileExtension() string {
 	return "mdoc.json"
 }
 
+// FileExtensionFor returns the output file extension for the configured mddl encoding
+func (g *Generator) FileExtensionFor(cfg *config.Config) string {
+	if cfg.MDDLEncoding == config.MDDLEncodingCBOR {
+		return "mdoc.cbor"
+	}
+	return g.FileExtension()
+}
+
 // DeriveIdentifier derives the doctype from the parsed credential
 func (g *Generator) DeriveIdentifier(parsed *formats.ParsedCredential, cfg *config.Config) string {
 	// Check for explicit doctype
@@ -53,23 +65,42 @@ func (g *Generator) DeriveIdentifier(parsed *formats.ParsedCredential, cfg *conf
 
 	// Derive from base URL (reverse domain notation)
 	if cfg.BaseURL != "" && parsed.ID != "" {
-		// https://registry.siros.org -> org.siros.registry.credentials.{id}
-		baseURL := strings.TrimPrefix(cfg.BaseURL, "https://")
-		baseURL = strings.TrimPrefix(baseURL, "http://")
-		baseURL = strings.TrimSuffix(baseURL, "/")
-
-		parts := strings.Split(baseURL, ".")
-		// Reverse the parts
-		for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
-			parts[i], parts[j] = parts[j], parts[i]
+		if prefix := reverseDomain(cfg.BaseURL); prefix != "" {
+			return prefix + ".credentials." + parsed.ID
 		}
-
-		return strings.Join(parts, ".") + ".credentials." + parsed.ID
 	}
 
 	return ""
 }
 
+// reverseDomain converts a base URL to reverse domain notation: the host labels in
+// reverse order, lowercased and without any port, followed by the non-empty path
+// segments in order, with dots inside a segment replaced by underscores, e.g.
+// https://registry.siros.org:8443/eu/v1/ -> org.siros.registry.eu.v1. A base URL
+// without a scheme is read as a host. It returns "" when there is no host.
+func reverseDomain(baseURL string) string {
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(u.Hostname(), ".")), ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment != "" {
+			labels = append(labels, strings.ReplaceAll(segment, ".", "_"))
+		}
+	}
+
+	return strings.Join(labels, ".")
+}
+
 // deriveNamespace derives the namespace from doctype or config
 func (g *Generator) deriveNamespace(parsed *formats.ParsedCredential, cfg *config.Config) string {
 	// Check for explicit namespace
@@ -88,6 +119,19 @@ func (g *Generator) deriveNamespace(parsed *formats.ParsedCredential, cfg *confi
 	return g.DeriveIdentifier(parsed, cfg)
 }
 
+// CredentialConfiguration returns the OpenID4VCI credential configuration for mso_mdoc
+func (g *Generator) CredentialConfiguration(parsed *formats.ParsedCredential, cfg *config.Config) (map[string]interface{}, error) {
+	doctype := g.DeriveIdentifier(parsed, cfg)
+	if doctype == "" {
+		return nil, fmt.Errorf("mddl: doctype is required (set doctype in front matter or provide base_url)")
+	}
+	return map[string]interface{}{
+		"format":  "mso_mdoc",
+		"doctype": doctype,
+		"display": formats.CredentialDisplay(parsed, cfg),
+	}, nil
+}
+
 // MDDL represents mso_mdoc credential configuration metadata
 type MDDL struct {
 	Format  string                     `json:"format"`
@@ -121,6 +165,9 @@ type ClaimMetadata struct {
 	Display   []ClaimDisplay `json:"display,omitempty"`
 	Mandatory bool           `json:"mandatory,omitempty"`
 	ValueType string         `json:"value_type,omitempty"`
+	SvgID     string         `json:"svg_id,omitempty"`
+	SD        string         `json:"sd,omitempty"` // non-standard hint, mso_mdoc has no per-claim selective disclosure
+	Order     int            `json:"order,omitempty"`
 }
 
 // ClaimDisplay for claim-level display
@@ -129,6 +176,32 @@ type ClaimDisplay struct {
 	Name   string `json:"name"`
 }
 
+// resolveLogoURI resolves the logo URI: remote URIs pass through unchanged, local
+// files are inlined as data URLs or built from the asset base URL. Local files that
+// cannot be read fall back to their URL or path.
+func resolveLogoURI(parsed *formats.ParsedCredential, cfg *config.Config) string {
+	path := parsed.LogoPath
+	if formats.IsRemoteURI(path) {
+		return path
+	}
+
+	imagePath := path
+	if !filepath.IsAbs(imagePath) {
+		imagePath = filepath.Join(parsed.SourceDir, imagePath)
+	}
+	if parsed.InlineImages {
+		if data, err := os.ReadFile(imagePath); err == nil {
+			mimeType := formats.ImageMIMEType(path, data)
+			return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
+		}
+	}
+
+	if uri := cfg.AssetURL(formats.AssetPath(path, imagePath, cfg)); uri != "" {
+		return uri
+	}
+	return path
+}
+
 // Generate produces the MDDL output
 func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Config) ([]byte, error) {
 	doctype := g.DeriveIdentifier(parsed, cfg)
@@ -143,10 +216,12 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 		DocType: doctype,
 	}
 
+	language := formats.DefaultLocale(parsed, cfg)
+
 	// Add display properties
 	if parsed.Name != "" || parsed.Description != "" {
 		display := DisplayProperties{
-			Locale:          cfg.Language,
+			Locale:          language,
 			Name:            parsed.Name,
 			Description:     parsed.Description,
 			BackgroundColor: parsed.BackgroundColor,
@@ -156,7 +231,7 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 		// Add logo
 		if parsed.LogoPath != "" {
 			display.Logo = &Logo{
-				URI:     parsed.LogoPath,
+				URI:     resolveLogoURI(parsed, cfg),
 				AltText: parsed.LogoAltText,
 			}
 		}
@@ -165,7 +240,7 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 
 		// Add localizations
 		for locale, loc := range parsed.Localizations {
-			if locale == cfg.Language {
+			if locale == language {
 				continue
 			}
 			mddl.Display = append(mddl.Display, DisplayProperties{
@@ -197,6 +272,10 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 			meta := ClaimMetadata{
 				Mandatory: claim.Mandatory,
 				ValueType: mapTypeToCDDL(claim.Type),
+				Order:     claim.Order,
+			}
+			if cfg.MDDLIncludeSD {
+				meta.SD = claim.SD
 			}
 
 			// Build display array
@@ -208,13 +287,13 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 				displayName = claim.Name
 			}
 			displays = append(displays, ClaimDisplay{
-				Locale: cfg.Language,
+				Locale: language,
 				Name:   displayName,
 			})
 
 			// Additional localizations
 			for locale, loc := range claim.Localizations {
-				if locale == cfg.Language {
+				if locale == language {
 					continue
 				}
 				label := loc.Label
@@ -227,7 +306,10 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 				})
 			}
 
-			meta.Display = displays
+			if !claim.Hidden {
+				meta.Display = displays
+				meta.SvgID = claim.SvgId
+			}
 			mddl.Claims[namespace][claimName] = meta
 		}
 	}
@@ -243,13 +325,36 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 		}
 	}
 
-	return json.MarshalIndent(mddl, "", "  ")
+	if cfg != nil && cfg.MDDLEncoding == config.MDDLEncodingCBOR {
+		return encodeCBOR(mddl)
+	}
+	return formats.FormatJSON(mddl, cfg)
+}
+
+// cborEncMode encodes with the canonical CBOR options (sorted map keys, shortest
+// integer and float forms), so the same configuration always yields the same bytes
+var cborEncMode = func() cbor.EncMode {
+	mode, err := cbor.CanonicalEncOptions().EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return mode
+}()
+
+// encodeCBOR marshals the mddl configuration as deterministic CBOR. Fields use the
+// same names as the JSON output.
+func encodeCBOR(mddl *MDDL) ([]byte, error) {
+	data, err := cborEncMode.Marshal(mddl)
+	if err != nil {
+		return nil, fmt.Errorf("mddl: failed to encode CBOR: %w", err)
+	}
+	return data, nil
 }
 
 // mapTypeToCDDL maps markdown types to CDDL types
 func mapTypeToCDDL(mdType string) string {
 	switch strings.ToLower(mdType) {
-	case "string":
+	case "string", "email", "uri", "uuid", "phone":
 		return "tstr"
 	case "number":
 		return "int"
@@ -1,9 +1,14 @@
 package mddl
 
 import (
+	"bytes"
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/sirosfoundation/mtcvctm/pkg/config"
 	"github.com/sirosfoundation/mtcvctm/pkg/formats"
 )
@@ -79,6 +84,36 @@ func TestGenerator_DeriveIdentifier(t *testing.T) {
 			},
 			want: "org.siros.registry.credentials.pid",
 		},
+		{
+			name: "base URL with path",
+			cred: &formats.ParsedCredential{ID: "pid"},
+			cfg:  &config.Config{BaseURL: "https://example.com/registry"},
+			want: "com.example.registry.credentials.pid",
+		},
+		{
+			name: "base URL with nested path and trailing slash",
+			cred: &formats.ParsedCredential{ID: "pid"},
+			cfg:  &config.Config{BaseURL: "https://example.com/eu/v1.2/"},
+			want: "com.example.eu.v1_2.credentials.pid",
+		},
+		{
+			name: "base URL with port",
+			cred: &formats.ParsedCredential{ID: "pid"},
+			cfg:  &config.Config{BaseURL: "http://localhost:8080"},
+			want: "localhost.credentials.pid",
+		},
+		{
+			name: "base URL with subdomains, port and path",
+			cred: &formats.ParsedCredential{ID: "diploma"},
+			cfg:  &config.Config{BaseURL: "https://Issuer.Registry.Example.org:8443/credentials?x=1"},
+			want: "org.example.registry.issuer.credentials.credentials.diploma",
+		},
+		{
+			name: "base URL without scheme",
+			cred: &formats.ParsedCredential{ID: "pid"},
+			cfg:  &config.Config{BaseURL: "registry.siros.org/"},
+			want: "org.siros.registry.credentials.pid",
+		},
 		{
 			name: "empty when no source",
 			cred: &formats.ParsedCredential{
@@ -240,6 +275,77 @@ func TestGenerator_Generate_WithDisplay(t *testing.T) {
 	}
 }
 
+func TestGenerator_Generate_LogoURI(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "logo.svg"), []byte("<svg/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		logoPath string
+		inline   bool
+		cfg      *config.Config
+		wantURI  string
+	}{
+		{
+			name:     "remote URL passes through",
+			logoPath: "https://cdn.example.com/logo.png",
+			inline:   true,
+			cfg:      &config.Config{Language: "en-US", BaseURL: "https://example.com"},
+			wantURI:  "https://cdn.example.com/logo.png",
+		},
+		{
+			name:     "local file built from base URL",
+			logoPath: "logo.svg",
+			cfg:      &config.Config{Language: "en-US", BaseURL: "https://example.com"},
+			wantURI:  "https://example.com/logo.svg",
+		},
+		{
+			name:     "local file inlined",
+			logoPath: "logo.svg",
+			inline:   true,
+			cfg:      &config.Config{Language: "en-US", BaseURL: "https://example.com"},
+			wantURI:  "data:image/svg+xml;base64,PHN2Zy8+",
+		},
+	}
+
+	g := NewGenerator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cred := &formats.ParsedCredential{
+				ID:           "test",
+				Name:         "Test",
+				DocType:      "org.example.test",
+				LogoPath:     tt.logoPath,
+				LogoAltText:  "Example logo",
+				SourceDir:    tmpDir,
+				InlineImages: tt.inline,
+			}
+
+			output, err := g.Generate(cred, tt.cfg)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			var parsed MDDL
+			if err := json.Unmarshal(output, &parsed); err != nil {
+				t.Fatalf("Output is not valid JSON: %v", err)
+			}
+			logo := parsed.Display[0].Logo
+			if logo == nil {
+				t.Fatal("display logo missing")
+			}
+			if logo.URI != tt.wantURI {
+				t.Errorf("logo.uri = %q, want %q", logo.URI, tt.wantURI)
+			}
+			if logo.AltText != "Example logo" {
+				t.Errorf("logo.alt_text = %q, want Example logo", logo.AltText)
+			}
+		})
+	}
+}
+
 func TestGenerator_Generate_WithLocalizations(t *testing.T) {
 	g := NewGenerator()
 	cfg := &config.Config{Language: "en-US"}
@@ -339,6 +445,77 @@ func TestGenerator_Generate_WithClaims(t *testing.T) {
 	}
 }
 
+func TestGenerator_Generate_ClaimSvgID(t *testing.T) {
+	g := NewGenerator()
+	cfg := &config.Config{Language: "en-US"}
+
+	cred := &formats.ParsedCredential{
+		Name:      "Test",
+		DocType:   "org.example.test",
+		Namespace: "org.example.ns",
+		Claims: []formats.ClaimDefinition{
+			{Name: "given_name", Type: "string", SvgId: "givenNameField"},
+			{Name: "family_name", Type: "string"},
+		},
+	}
+
+	output, err := g.Generate(cred, cfg)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	nsClaims := parsed["claims"].(map[string]interface{})["org.example.ns"].(map[string]interface{})
+
+	if got := nsClaims["given_name"].(map[string]interface{})["svg_id"]; got != "givenNameField" {
+		t.Errorf("given_name svg_id = %v, want givenNameField", got)
+	}
+	if _, ok := nsClaims["family_name"].(map[string]interface{})["svg_id"]; ok {
+		t.Error("family_name should not have an svg_id")
+	}
+}
+
+func TestGenerator_Generate_ClaimSD(t *testing.T) {
+	g := NewGenerator()
+	cred := &formats.ParsedCredential{
+		Name:      "Test",
+		DocType:   "org.example.test",
+		Namespace: "org.example.ns",
+		Claims: []formats.ClaimDefinition{
+			{Name: "given_name", Type: "string", SD: "always"},
+			{Name: "family_name", Type: "string"},
+		},
+	}
+
+	for _, include := range []bool{false, true} {
+		cfg := &config.Config{Language: "en-US", MDDLIncludeSD: include}
+		output, err := g.Generate(cred, cfg)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		var parsed MDDL
+		if err := json.Unmarshal(output, &parsed); err != nil {
+			t.Fatalf("Output is not valid JSON: %v", err)
+		}
+		nsClaims := parsed.Claims["org.example.ns"]
+
+		want := ""
+		if include {
+			want = "always"
+		}
+		if got := nsClaims["given_name"].SD; got != want {
+			t.Errorf("MDDLIncludeSD=%v: given_name sd = %q, want %q", include, got, want)
+		}
+		if got := bytes.Count(output, []byte(`"sd"`)); include && got != 1 || !include && got != 0 {
+			t.Errorf("MDDLIncludeSD=%v: %d sd fields, want only the given_name one when included", include, got)
+		}
+	}
+}
+
 func TestGenerator_Generate_WithClaimMappings(t *testing.T) {
 	g := NewGenerator()
 	cfg := &config.Config{Language: "en-US"}
@@ -418,6 +595,10 @@ func TestMapTypeToCDDL(t *testing.T) {
 		{"bool", "bool"},
 		{"date", "full-date"},
 		{"datetime", "tdate"},
+		{"email", "tstr"},
+		{"uri", "tstr"},
+		{"uuid", "tstr"},
+		{"phone", "tstr"},
 		{"image", "bstr"},
 		{"object", ""},
 		{"array", ""},
@@ -442,3 +623,60 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestGenerator_Generate_CBOR(t *testing.T) {
+	g := NewGenerator()
+
+	cred := &formats.ParsedCredential{
+		Name:            "Identity Card",
+		Description:     "A digital identity card",
+		DocType:         "org.example.identity",
+		Namespace:       "org.example.identity.1",
+		BackgroundColor: "#0000ff",
+		Localizations: map[string]formats.DisplayLocalization{
+			"de": {Name: "Ausweis"},
+		},
+		Claims: []formats.ClaimDefinition{
+			{Name: "given_name", DisplayName: "Given Name", Type: "string", Mandatory: true,
+				Localizations: map[string]formats.ClaimLocalization{"de": {Label: "Vorname"}}},
+			{Name: "birth_date", Type: "date"},
+		},
+		FormatOverrides: map[string]map[string]interface{}{"mddl": {"order": 2}},
+	}
+
+	jsonOutput, err := g.Generate(cred, &config.Config{Language: "en-US"})
+	if err != nil {
+		t.Fatalf("Generate(json) error = %v", err)
+	}
+	cborCfg := &config.Config{Language: "en-US", MDDLEncoding: config.MDDLEncodingCBOR}
+	cborOutput, err := g.Generate(cred, cborCfg)
+	if err != nil {
+		t.Fatalf("Generate(cbor) error = %v", err)
+	}
+
+	var fromJSON, fromCBOR MDDL
+	if err := json.Unmarshal(jsonOutput, &fromJSON); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if err := cbor.Unmarshal(cborOutput, &fromCBOR); err != nil {
+		t.Fatalf("cbor.Unmarshal() error = %v", err)
+	}
+	if len(fromCBOR.Claims) == 0 || fromCBOR.Order == nil {
+		t.Fatalf("CBOR output lost claims or order: %+v", fromCBOR)
+	}
+	if !reflect.DeepEqual(fromCBOR, fromJSON) {
+		t.Errorf("CBOR output = %+v, want %+v", fromCBOR, fromJSON)
+	}
+
+	again, err := g.Generate(cred, cborCfg)
+	if err != nil {
+		t.Fatalf("Generate(cbor) error = %v", err)
+	}
+	if !bytes.Equal(again, cborOutput) {
+		t.Error("CBOR output is not deterministic")
+	}
+
+	if ext := g.FileExtensionFor(cborCfg); ext != "mdoc.cbor" {
+		t.Errorf("FileExtensionFor(cbor) = %q, want mdoc.cbor", ext)
+	}
+}
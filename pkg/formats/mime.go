@@ -0,0 +1,106 @@
+package formats
+
+import (
+	"bytes"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// svgSniffLen is how far into an XML document the root <svg> element is looked for
+const svgSniffLen = 4096
+
+// imageMagic maps leading bytes to the MIME types they identify
+var imageMagic = []struct {
+	prefix   string
+	mimeType string
+}{
+	{"\x89PNG\r\n\x1a\n", "image/png"},
+	{"\xff\xd8\xff", "image/jpeg"},
+	{"GIF87a", "image/gif"},
+	{"GIF89a", "image/gif"},
+}
+
+// imageExtensions maps lower-case file extensions to image MIME types
+var imageExtensions = map[string]string{
+	".svg":  "image/svg+xml",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".avif": "image/avif",
+	".ico":  "image/x-icon",
+}
+
+// ImageMIMEType returns the MIME type of image data for a data URL. PNG, JPEG,
+// GIF, WebP and AVIF are recognized by their magic bytes and SVG by its content;
+// other data falls back to the extension of path and then to
+// http.DetectContentType.
+func ImageMIMEType(path string, data []byte) string {
+	if mimeType := sniffImage(data); mimeType != "" {
+		return mimeType
+	}
+	if mimeType, ok := imageExtensions[strings.ToLower(filepath.Ext(path))]; ok {
+		return mimeType
+	}
+	return http.DetectContentType(data)
+}
+
+// sniffImage identifies image data by its content, returning "" when unknown
+func sniffImage(data []byte) string {
+	for _, magic := range imageMagic {
+		if bytes.HasPrefix(data, []byte(magic.prefix)) {
+			return magic.mimeType
+		}
+	}
+	if len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP" {
+		return "image/webp"
+	}
+	if isAVIF(data) {
+		return "image/avif"
+	}
+	if isSVG(data) {
+		return "image/svg+xml"
+	}
+	return ""
+}
+
+// isAVIF reports whether data starts with an ISO BMFF ftyp box whose major or
+// compatible brands include avif or avis
+func isAVIF(data []byte) bool {
+	if len(data) < 16 || string(data[4:8]) != "ftyp" {
+		return false
+	}
+	size := int(data[0])<<24 | int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+	if size < 16 || size > len(data) {
+		size = len(data)
+	}
+	// Major brand at 8, minor version at 12, compatible brands from 16
+	for i := 8; i+4 <= size; i += 4 {
+		if i == 12 {
+			continue
+		}
+		if brand := string(data[i : i+4]); brand == "avif" || brand == "avis" {
+			return true
+		}
+	}
+	return false
+}
+
+// isSVG reports whether data is an SVG document: an <svg> root element,
+// possibly after an XML declaration, comments or a doctype
+func isSVG(data []byte) bool {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if bytes.HasPrefix(data, []byte("<svg")) {
+		return true
+	}
+	if !bytes.HasPrefix(data, []byte("<?xml")) && !bytes.HasPrefix(data, []byte("<!")) {
+		return false
+	}
+	if len(data) > svgSniffLen {
+		data = data[:svgSniffLen]
+	}
+	return bytes.Contains(data, []byte("<svg"))
+}
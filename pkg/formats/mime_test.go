@@ -0,0 +1,36 @@
+package formats
+
+import "testing"
+
+func TestImageMIMEType(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		data string
+		want string
+	}{
+		{"png", "logo.png", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", "image/png"},
+		{"jpeg", "logo.jpg", "\xff\xd8\xff\xe0\x00\x10JFIF\x00", "image/jpeg"},
+		{"gif87a", "logo.gif", "GIF87a\x01\x00\x01\x00", "image/gif"},
+		{"gif89a", "logo.gif", "GIF89a\x01\x00\x01\x00", "image/gif"},
+		{"webp", "logo.webp", "RIFF\x24\x00\x00\x00WEBPVP8 ", "image/webp"},
+		{"avif major brand", "logo.avif", "\x00\x00\x00\x1cftypavif\x00\x00\x00\x00avifmif1miaf", "image/avif"},
+		{"avif compatible brand", "logo.avif", "\x00\x00\x00\x18ftypmif1\x00\x00\x00\x00avifmiaf", "image/avif"},
+		{"heic is not avif", "photo.heic", "\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic", "application/octet-stream"},
+		{"svg root", "logo", `<svg xmlns="http://www.w3.org/2000/svg"/>`, "image/svg+xml"},
+		{"svg after prolog", "logo.bin", "\xef\xbb\xbf<?xml version=\"1.0\"?>\n<!-- exported -->\n<!DOCTYPE svg>\n<svg/>", "image/svg+xml"},
+		{"xml without svg", "data.xml", `<?xml version="1.0"?><note/>`, "text/xml; charset=utf-8"},
+		{"content wins over extension", "logo.png", "RIFF\x24\x00\x00\x00WEBPVP8L", "image/webp"},
+		{"extension fallback", "icon.ICO", "\x00\x00\x01\x00", "image/x-icon"},
+		{"uppercase svg extension", "logo.SVG", "", "image/svg+xml"},
+		{"detect content type fallback", "logo", "%PDF-1.7", "application/pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ImageMIMEType(tt.path, []byte(tt.data)); got != tt.want {
+				t.Errorf("ImageMIMEType(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
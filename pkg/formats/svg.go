@@ -0,0 +1,157 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// editorPrefixes are the namespace prefixes of editor-only elements and attributes
+var editorPrefixes = map[string]bool{
+	"inkscape": true,
+	"sodipodi": true,
+}
+
+// textElements hold character data whose whitespace may be rendered or parsed
+var textElements = map[string]bool{
+	"text":          true,
+	"tspan":         true,
+	"textPath":      true,
+	"style":         true,
+	"script":        true,
+	"title":         true,
+	"desc":          true,
+	"foreignObject": true,
+}
+
+// attrEscaper escapes attribute values re-serialized in double quotes
+var attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;")
+
+// MinifySVG removes what does not affect rendering from an SVG document: XML
+// comments, whitespace-only text between elements, metadata elements and
+// Inkscape/Sodipodi editor elements, attributes and namespace declarations.
+// Everything else is copied byte for byte, and whitespace inside text, style,
+// script and xml:space="preserve" elements is kept. Documents that do not parse
+// as XML are returned unchanged.
+func MinifySVG(data []byte) []byte {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var out bytes.Buffer
+	out.Grow(len(data))
+
+	var (
+		start    int
+		skip     int    // depth inside a dropped element
+		preserve []bool // whether each open element keeps its whitespace
+	)
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return data
+		}
+		end := int(dec.InputOffset())
+		raw := data[start:end]
+		start = end
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 || isEditorElement(t.Name) {
+				skip++
+				continue
+			}
+			keep := textElements[t.Name.Local] || (len(preserve) > 0 && preserve[len(preserve)-1])
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xml" && attr.Name.Local == "space" {
+					keep = attr.Value == "preserve"
+				}
+			}
+			preserve = append(preserve, keep)
+			out.Write(withoutEditorAttrs(t, raw))
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if len(preserve) > 0 {
+				preserve = preserve[:len(preserve)-1]
+			}
+			out.Write(raw)
+		case xml.CharData:
+			if skip > 0 {
+				continue
+			}
+			kept := len(preserve) > 0 && preserve[len(preserve)-1]
+			if !kept && len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+			out.Write(raw)
+		case xml.Comment:
+			continue
+		default:
+			if skip == 0 {
+				out.Write(raw)
+			}
+		}
+	}
+
+	if skip > 0 || len(preserve) > 0 {
+		return data
+	}
+	return out.Bytes()
+}
+
+// isEditorElement reports whether an element only carries editor metadata
+func isEditorElement(name xml.Name) bool {
+	return name.Local == "metadata" || editorPrefixes[name.Space]
+}
+
+// isEditorAttr reports whether an attribute, or namespace declaration, belongs to an editor
+func isEditorAttr(attr xml.Attr) bool {
+	return editorPrefixes[attr.Name.Space] || (attr.Name.Space == "xmlns" && editorPrefixes[attr.Name.Local])
+}
+
+// withoutEditorAttrs returns the raw start tag, re-serialized without editor
+// attributes when it has any
+func withoutEditorAttrs(el xml.StartElement, raw []byte) []byte {
+	found := false
+	for _, attr := range el.Attr {
+		if isEditorAttr(attr) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return raw
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('<')
+	buf.WriteString(qualifiedName(el.Name))
+	for _, attr := range el.Attr {
+		if isEditorAttr(attr) {
+			continue
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(qualifiedName(attr.Name))
+		buf.WriteString(`="`)
+		buf.WriteString(attrEscaper.Replace(attr.Value))
+		buf.WriteByte('"')
+	}
+	if bytes.HasSuffix(bytes.TrimRight(raw, " \t\r\n"), []byte("/>")) {
+		buf.WriteString("/>")
+	} else {
+		buf.WriteByte('>')
+	}
+	return buf.Bytes()
+}
+
+// qualifiedName returns prefix:local, or local without a prefix
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
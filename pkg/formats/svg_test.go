@@ -0,0 +1,50 @@
+package formats
+
+import "testing"
+
+func TestMinifySVG(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "comments and whitespace between elements",
+			input: "<?xml version=\"1.0\"?>\n<!-- card -->\n<svg xmlns=\"http://www.w3.org/2000/svg\">\n  <!-- background -->\n  <rect width=\"100\" height=\"50\"/>\n</svg>\n",
+			want:  `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><rect width="100" height="50"/></svg>`,
+		},
+		{
+			name:  "editor metadata",
+			input: `<svg xmlns="http://www.w3.org/2000/svg" xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape" xmlns:sodipodi="http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd" inkscape:version="1.3"><metadata><rdf>x</rdf></metadata><sodipodi:namedview id="nv"/><g inkscape:label="Layer 1" id="g1"><rect width="1"/></g></svg>`,
+			want:  `<svg xmlns="http://www.w3.org/2000/svg"><g id="g1"><rect width="1"/></g></svg>`,
+		},
+		{
+			name:  "text whitespace kept",
+			input: "<svg>\n  <text x=\"1\">  Given <tspan>name</tspan> </text>\n  <style>\n .a { fill: red }\n</style>\n</svg>",
+			want:  "<svg><text x=\"1\">  Given <tspan>name</tspan> </text><style>\n .a { fill: red }\n</style></svg>",
+		},
+		{
+			name:  "xml:space preserve kept",
+			input: "<svg><g xml:space=\"preserve\">\n <a>\n</a></g>\n</svg>",
+			want:  "<svg><g xml:space=\"preserve\">\n <a>\n</a></g></svg>",
+		},
+		{
+			name:  "attribute values escaped when re-serialized",
+			input: `<svg inkscape:x="1" data-v="a &amp; &quot;b&quot; &lt;c&gt;"></svg>`,
+			want:  `<svg data-v="a &amp; &quot;b&quot; &lt;c>"></svg>`,
+		},
+		{
+			name:  "malformed input unchanged",
+			input: "<svg><!-- open\n<rect></svg>",
+			want:  "<svg><!-- open\n<rect></svg>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(MinifySVG([]byte(tt.input))); got != tt.want {
+				t.Errorf("MinifySVG() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
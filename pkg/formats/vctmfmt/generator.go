@@ -2,10 +2,7 @@
 package vctmfmt
 
 import (
-	"encoding/base64"
 	"fmt"
-	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -37,20 +34,29 @@ func (g *Generator) DeriveIdentifier(parsed *formats.ParsedCredential, cfg *conf
 	if parsed.VCT != "" {
 		return parsed.VCT
 	}
+	// Derive from base URL and ID, matching config.GetVCT
+	if cfg.BaseURL != "" && parsed.ID != "" {
+		return strings.TrimSuffix(cfg.BaseURL, "/") + "/" + parsed.ID
+	}
 	// Fallback to ID
 	return parsed.ID
 }
 
+// CredentialConfiguration returns the OpenID4VCI credential configuration for SD-JWT VC
+func (g *Generator) CredentialConfiguration(parsed *formats.ParsedCredential, cfg *config.Config) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"format":  "dc+sd-jwt",
+		"vct":     g.DeriveIdentifier(parsed, cfg),
+		"display": formats.CredentialDisplay(parsed, cfg),
+	}, nil
+}
+
 // Generate produces VCTM JSON for SD-JWT VC credentials
 func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Config) ([]byte, error) {
 	output := make(map[string]interface{})
 
-	// Required: vct - use VCT field, fallback to ID
-	vct := parsed.VCT
-	if vct == "" {
-		vct = parsed.ID
-	}
-	output["vct"] = vct
+	// Required: vct - use VCT field, derived from base URL or ID otherwise
+	output["vct"] = g.DeriveIdentifier(parsed, cfg)
 
 	// Required: name (must not be empty)
 	if parsed.Name == "" {
@@ -64,7 +70,16 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 	}
 
 	// Handle optional fields from metadata
-	if v, ok := parsed.Metadata["extends"]; ok {
+	if len(parsed.Extends) > 0 {
+		// Draft 12 allows a single parent; additional parents go into a namespaced extra
+		if len(parsed.Extends) > 1 {
+			if cfg.Strict {
+				return nil, fmt.Errorf("vctm: extends declares %d parent types, but only one is allowed", len(parsed.Extends))
+			}
+			output["x-additional_extends"] = parsed.Extends[1:]
+		}
+		output["extends"] = parsed.Extends[0]
+	} else if v, ok := parsed.Metadata["extends"]; ok {
 		output["extends"] = v
 	}
 	if v, ok := parsed.Metadata["extends#integrity"]; ok {
@@ -79,13 +94,23 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 
 	// Build claims from claim definitions
 	if len(parsed.Claims) > 0 {
+		// Claim display uses the same default locale as the credential display
+		locale := formats.DefaultLocale(parsed, cfg)
+		if locale == "" {
+			locale = "en-US"
+		}
+		// Draft 11 keys claims by name; draft 12 lists them with a path
+		draft11 := cfg.GetVCTMDraft() == 11
 		claims := make([]map[string]interface{}, 0, len(parsed.Claims))
+		claimsByName := make(map[string]interface{}, len(parsed.Claims))
 		for _, claim := range parsed.Claims {
 			claimEntry := make(map[string]interface{})
-			claimEntry["path"] = claim.Path
-			if claim.DisplayName != "" {
+			if !draft11 {
+				claimEntry["path"] = claim.Path
+			}
+			if claim.DisplayName != "" && !claim.Hidden {
 				claimEntry["display"] = []map[string]string{
-					{"locale": "en-US", "label": claim.DisplayName},
+					{"locale": locale, "label": claim.DisplayName},
 				}
 			}
 			if claim.Description != "" {
@@ -97,12 +122,28 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 			if claim.SD != "" {
 				claimEntry["sd"] = claim.SD
 			}
-			if claim.SvgId != "" {
+			if claim.SvgId != "" && !claim.Hidden {
 				claimEntry["svg_id"] = claim.SvgId
 			}
-			claims = append(claims, claimEntry)
+			// Non-standard UI grouping hint, namespaced as an extra
+			if claim.Group != "" {
+				claimEntry["x-group"] = claim.Group
+			}
+			// Non-standard value type hint for tooling, namespaced as an extra
+			if cfg.EmitValueType && claim.Type != "" {
+				claimEntry["x-value_type"] = strings.ToLower(claim.Type)
+			}
+			if draft11 {
+				claimsByName[claim.Name] = claimEntry
+			} else {
+				claims = append(claims, claimEntry)
+			}
+		}
+		if draft11 {
+			output["claims"] = claimsByName
+		} else {
+			output["claims"] = claims
 		}
-		output["claims"] = claims
 	}
 
 	// Build display with rendering section
@@ -112,9 +153,13 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 	// Collect SVG templates from images and explicit configuration
 	svgTemplates := make([]map[string]interface{}, 0)
 
+	// Images shared between logo and templates are read and encoded once
+	cache := newInlineCache()
+	cache.integrity = parsed.Integrity
+
 	// First, add explicit SVG template from metadata
 	if parsed.SVGTemplatePath != "" || parsed.SVGTemplateURI != "" {
-		template, err := g.buildSVGTemplate(parsed.SVGTemplateURI, parsed.SVGTemplatePath, parsed.SVGTemplateIntegrity, parsed.SourceDir, parsed.InlineImages, cfg)
+		template, err := g.buildSVGTemplate(parsed.SVGTemplateURI, parsed.SVGTemplatePath, parsed.SVGTemplateIntegrity, parsed.SourceDir, parsed.InlineImages, cache, cfg)
 		if err == nil && template != nil {
 			svgTemplates = append(svgTemplates, template)
 		}
@@ -124,9 +169,13 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 	var logoImage *formats.ImageRef
 	for i := range parsed.Images {
 		img := &parsed.Images[i]
+		if img.Role == formats.ImageRoleLogo {
+			// The marked logo is carried by parsed.LogoPath
+			continue
+		}
 		if strings.HasSuffix(strings.ToLower(img.Path), ".svg") {
 			// SVG files become svg_templates
-			template, err := g.buildSVGTemplateFromImage(img, parsed.SourceDir, parsed.InlineImages, cfg)
+			template, err := g.buildSVGTemplateFromImage(img, parsed.SourceDir, parsed.InlineImages, cache, cfg)
 			if err == nil && template != nil {
 				svgTemplates = append(svgTemplates, template)
 			}
@@ -146,17 +195,24 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 
 	// Logo handling - prefer explicit logo, then first non-SVG image
 	if parsed.LogoPath != "" {
-		logo, err := g.imageToLogo(parsed.LogoPath, parsed.LogoAltText, parsed.SourceDir, parsed.InlineImages, cfg)
+		logo, err := g.imageToLogo(parsed.LogoPath, parsed.LogoAltText, parsed.SourceDir, parsed.InlineImages, cache, cfg)
 		if err == nil && logo != nil {
 			simple["logo"] = logo
 		}
 	} else if logoImage != nil {
-		logo, err := g.imageToLogo(logoImage.Path, logoImage.AltText, parsed.SourceDir, parsed.InlineImages, cfg)
+		logo, err := g.imageToLogo(logoImage.Path, logoImage.AltText, parsed.SourceDir, parsed.InlineImages, cache, cfg)
 		if err == nil && logo != nil {
 			simple["logo"] = logo
 		}
 	}
 
+	// Background image, inlined or referenced with integrity like the logo
+	if parsed.BackgroundImagePath != "" {
+		if image, err := g.imageToLogo(parsed.BackgroundImagePath, "", parsed.SourceDir, parsed.InlineImages, cache, cfg); err == nil && image != nil {
+			simple["background_image"] = image
+		}
+	}
+
 	// Background/text colors
 	if parsed.BackgroundColor != "" {
 		simple["background_color"] = parsed.BackgroundColor
@@ -169,12 +225,33 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 		rendering["simple"] = simple
 	}
 
+	// Light and dark variants; simple already carries the light one
+	if len(parsed.ColorSchemes) > 0 {
+		schemes := make(map[string]interface{}, len(parsed.ColorSchemes))
+		for scheme, colors := range parsed.ColorSchemes {
+			variant := make(map[string]interface{})
+			if colors.LogoPath != "" {
+				if logo, err := g.imageToLogo(colors.LogoPath, colors.LogoAltText, parsed.SourceDir, parsed.InlineImages, cache, cfg); err == nil && logo != nil {
+					variant["logo"] = logo
+				}
+			}
+			if colors.BackgroundColor != "" {
+				variant["background_color"] = colors.BackgroundColor
+			}
+			if colors.TextColor != "" {
+				variant["text_color"] = colors.TextColor
+			}
+			schemes[scheme] = variant
+		}
+		rendering["x-color_schemes"] = schemes
+	}
+
 	if len(rendering) > 0 {
 		display["rendering"] = rendering
 	}
 
 	// Add locale to display (REQUIRED per spec)
-	display["locale"] = cfg.Language
+	display["locale"] = formats.DefaultLocale(parsed, cfg)
 
 	// Add name to display (REQUIRED per spec)
 	display["name"] = parsed.Name
@@ -182,11 +259,11 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 	// Always include display array since locale and name are required
 	output["display"] = []map[string]interface{}{display}
 
-	return formats.FormatJSON(output)
+	return formats.FormatJSON(output, cfg)
 }
 
 // buildSVGTemplate creates an SVG template entry from explicit configuration
-func (g *Generator) buildSVGTemplate(uri, path, integrity, sourceDir string, inline bool, cfg *config.Config) (map[string]interface{}, error) {
+func (g *Generator) buildSVGTemplate(uri, path, integrity, sourceDir string, inline bool, cache *inlineCache, cfg *config.Config) (map[string]interface{}, error) {
 	template := make(map[string]interface{})
 
 	if uri != "" {
@@ -198,14 +275,17 @@ func (g *Generator) buildSVGTemplate(uri, path, integrity, sourceDir string, inl
 			svgPath = filepath.Join(sourceDir, svgPath)
 		}
 
-		if inline {
-			data, err := os.ReadFile(svgPath)
+		if shouldInline(inline, path, svgPath, cache, cfg) {
+			data, err := cache.read(svgPath)
 			if err != nil {
 				return nil, err
 			}
-			template["uri"] = "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(data)
-		} else if cfg.BaseURL != "" {
-			template["uri"] = cfg.BaseURL + "/" + path
+			template["uri"] = cache.dataURL(svgData(data, cfg), "image/svg+xml")
+		} else if assetURL := cfg.AssetURL(cache.assetPath(path, svgPath, cfg)); assetURL != "" {
+			template["uri"] = assetURL
+			if integrity == "" {
+				integrity, _ = cache.fileIntegrity(svgPath)
+			}
 		}
 	}
 
@@ -221,7 +301,7 @@ func (g *Generator) buildSVGTemplate(uri, path, integrity, sourceDir string, inl
 }
 
 // buildSVGTemplateFromImage creates an SVG template entry from an image reference
-func (g *Generator) buildSVGTemplateFromImage(img *formats.ImageRef, sourceDir string, inline bool, cfg *config.Config) (map[string]interface{}, error) {
+func (g *Generator) buildSVGTemplateFromImage(img *formats.ImageRef, sourceDir string, inline bool, cache *inlineCache, cfg *config.Config) (map[string]interface{}, error) {
 	template := make(map[string]interface{})
 
 	imagePath := img.Path
@@ -231,47 +311,100 @@ func (g *Generator) buildSVGTemplateFromImage(img *formats.ImageRef, sourceDir s
 		imagePath = filepath.Join(sourceDir, imagePath)
 	}
 
-	if inline {
-		data, err := os.ReadFile(imagePath)
+	if formats.IsRemoteURI(img.Path) {
+		// Remote templates keep their URL; integrity is only known when they are resolved
+		template["uri"] = img.Path
+		if integrity, err := cache.fileIntegrity(img.Path); err == nil {
+			template["uri#integrity"] = integrity
+		}
+	} else if shouldInline(inline, img.Path, imagePath, cache, cfg) {
+		data, err := cache.read(imagePath)
 		if err != nil {
 			return nil, err
 		}
-		template["uri"] = "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(data)
-	} else if cfg.BaseURL != "" {
-		template["uri"] = cfg.BaseURL + "/" + img.Path
+		template["uri"] = cache.dataURL(svgData(data, cfg), "image/svg+xml")
+	} else if assetURL := cfg.AssetURL(cache.assetPath(img.Path, imagePath, cfg)); assetURL != "" {
+		template["uri"] = assetURL
+		if integrity, err := cache.fileIntegrity(imagePath); err == nil {
+			template["uri#integrity"] = integrity
+		}
 	}
 
 	if len(template) == 0 {
 		return nil, nil
 	}
 
+	properties := make(map[string]string)
+	if img.Orientation != "" {
+		properties["orientation"] = img.Orientation
+	}
+	if img.ColorScheme != "" {
+		properties["color_scheme"] = img.ColorScheme
+	}
+	if img.Contrast != "" {
+		properties["contrast"] = img.Contrast
+	}
+	if len(properties) > 0 {
+		template["properties"] = properties
+	}
+
 	return template, nil
 }
 
-// imageToLogo converts an image path to a logo object
-func (g *Generator) imageToLogo(path, altText, sourceDir string, inline bool, cfg *config.Config) (map[string]interface{}, error) {
+// svgData returns SVG contents to inline, minified unless disabled
+func svgData(data []byte, cfg *config.Config) []byte {
+	if cfg.NoSVGMinify {
+		return data
+	}
+	return formats.MinifySVG(data)
+}
+
+// shouldInline reports whether the local image at imagePath is inlined. Images
+// over the configured inline limit are referenced by URL instead when an asset
+// base URL is set; the parser has already warned about them.
+func shouldInline(inline bool, path, imagePath string, cache *inlineCache, cfg *config.Config) bool {
+	if !inline || cfg.AssetURL(path) == "" {
+		return inline
+	}
+	data, err := cache.read(imagePath)
+	return err != nil || !cfg.InlineTooLarge(int64(len(data)))
+}
+
+// imageToLogo converts an image path to a logo object. Background images use
+// the same uri and uri#integrity members without alt text. Remote URLs are used
+// as-is and never inlined.
+func (g *Generator) imageToLogo(path, altText, sourceDir string, inline bool, cache *inlineCache, cfg *config.Config) (map[string]interface{}, error) {
 	logo := make(map[string]interface{})
 
-	if path != "" {
+	if formats.IsRemoteURI(path) {
+		// Remote images keep their URL; integrity is only known when they are resolved
+		logo["uri"] = path
+		if integrity, err := cache.fileIntegrity(path); err == nil {
+			logo["uri#integrity"] = integrity
+		}
+	} else if path != "" {
 		imagePath := path
 		if !filepath.IsAbs(imagePath) {
 			imagePath = filepath.Join(sourceDir, imagePath)
 		}
 
-		if inline {
+		if shouldInline(inline, path, imagePath, cache, cfg) {
 			// Read and inline the image
-			data, err := os.ReadFile(imagePath)
+			data, err := cache.read(imagePath)
 			if err != nil {
 				return nil, err
 			}
-			mimeType := http.DetectContentType(data)
-			// Handle SVG which DetectContentType doesn't detect well
-			if strings.HasSuffix(strings.ToLower(path), ".svg") {
-				mimeType = "image/svg+xml"
+			mimeType := formats.ImageMIMEType(path, data)
+			if mimeType == "image/svg+xml" {
+				data = svgData(data, cfg)
+			}
+			logo["uri"] = cache.dataURL(data, mimeType)
+		} else if assetURL := cfg.AssetURL(cache.assetPath(path, imagePath, cfg)); assetURL != "" {
+			logo["uri"] = assetURL
+			// Integrity is computed from the local copy when available
+			if integrity, err := cache.fileIntegrity(imagePath); err == nil {
+				logo["uri#integrity"] = integrity
 			}
-			logo["uri"] = fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
-		} else if cfg.BaseURL != "" {
-			logo["uri"] = cfg.BaseURL + "/" + path
 		}
 	}
 
@@ -1,9 +1,15 @@
 package vctmfmt
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/sirosfoundation/mtcvctm/pkg/config"
@@ -223,7 +229,7 @@ func TestGenerator_Generate_WithClaims(t *testing.T) {
 		Claims: []formats.ClaimDefinition{
 			{
 				Name:        "given_name",
-				Path:        []string{"given_name"},
+				Path:        []interface{}{"given_name"},
 				DisplayName: "Given Name",
 				Description: "The holder's given name",
 				Mandatory:   true,
@@ -232,7 +238,7 @@ func TestGenerator_Generate_WithClaims(t *testing.T) {
 			},
 			{
 				Name: "email",
-				Path: []string{"email"},
+				Path: []interface{}{"email"},
 			},
 		},
 	}
@@ -279,6 +285,121 @@ func TestGenerator_Generate_WithClaims(t *testing.T) {
 	}
 }
 
+func TestGenerator_Generate_ClaimDisplayLocale(t *testing.T) {
+	g := &Generator{}
+	cfg := &config.Config{Language: "de-DE"}
+
+	cred := &formats.ParsedCredential{
+		ID:   "test",
+		Name: "Test",
+		Claims: []formats.ClaimDefinition{
+			{Name: "given_name", Path: []interface{}{"given_name"}, DisplayName: "Vorname"},
+		},
+	}
+
+	output, err := g.Generate(cred, cfg)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+
+	claim0 := parsed["claims"].([]interface{})[0].(map[string]interface{})
+	d0 := claim0["display"].([]interface{})[0].(map[string]interface{})
+	if d0["locale"] != "de-DE" {
+		t.Errorf("claims[0].display[0].locale = %v, want de-DE", d0["locale"])
+	}
+}
+
+func TestGenerator_Generate_ValueType(t *testing.T) {
+	g := &Generator{}
+	cred := &formats.ParsedCredential{
+		ID:   "test",
+		Name: "Test",
+		Claims: []formats.ClaimDefinition{
+			{Name: "birth_date", Path: []interface{}{"birth_date"}, Type: "Date"},
+		},
+	}
+
+	for _, enabled := range []bool{false, true} {
+		output, err := g.Generate(cred, &config.Config{Language: "en-US", EmitValueType: enabled})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		var parsed map[string]interface{}
+		if err := json.Unmarshal(output, &parsed); err != nil {
+			t.Fatalf("Output is not valid JSON: %v", err)
+		}
+		claim0 := parsed["claims"].([]interface{})[0].(map[string]interface{})
+		valueType, ok := claim0["x-value_type"]
+		if enabled && valueType != "date" {
+			t.Errorf("x-value_type = %v, want date when enabled", valueType)
+		}
+		if !enabled && ok {
+			t.Errorf("x-value_type = %v, want absent by default", valueType)
+		}
+	}
+}
+
+func TestGenerator_Generate_ClaimsDraft(t *testing.T) {
+	g := &Generator{}
+	cred := &formats.ParsedCredential{
+		ID:   "test",
+		Name: "Test",
+		Claims: []formats.ClaimDefinition{
+			{Name: "given_name", Path: []interface{}{"given_name"}, Mandatory: true},
+			{Name: "address.street", Path: []interface{}{"address", "street"}},
+		},
+	}
+
+	// Draft 12 (the default) lists claims with a path
+	for _, draft := range []int{0, 12} {
+		output, err := g.Generate(cred, &config.Config{Language: "en-US", VCTMDraft: draft})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		var parsed map[string]interface{}
+		if err := json.Unmarshal(output, &parsed); err != nil {
+			t.Fatalf("Output is not valid JSON: %v", err)
+		}
+		claims, ok := parsed["claims"].([]interface{})
+		if !ok || len(claims) != 2 {
+			t.Fatalf("draft %d: claims = %v, want an array of 2 entries", draft, parsed["claims"])
+		}
+		if path := claims[1].(map[string]interface{})["path"]; len(path.([]interface{})) != 2 {
+			t.Errorf("draft %d: path = %v, want [address street]", draft, path)
+		}
+	}
+
+	// Draft 11 keys claims by name without a path
+	output, err := g.Generate(cred, &config.Config{Language: "en-US", VCTMDraft: 11})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	claims, ok := parsed["claims"].(map[string]interface{})
+	if !ok || len(claims) != 2 {
+		t.Fatalf("draft 11: claims = %v, want an object of 2 entries", parsed["claims"])
+	}
+	given, ok := claims["given_name"].(map[string]interface{})
+	if !ok || given["mandatory"] != true {
+		t.Errorf("draft 11: given_name = %v", claims["given_name"])
+	}
+	if _, ok := given["path"]; ok {
+		t.Error("draft 11: claim entries should not carry a path")
+	}
+	if _, ok := claims["address.street"]; !ok {
+		t.Errorf("draft 11: claims = %v, want address.street", claims)
+	}
+}
+
 func TestGenerator_Generate_WithColors(t *testing.T) {
 	g := &Generator{}
 	cfg := &config.Config{Language: "en-US"}
@@ -352,6 +473,34 @@ func TestGenerator_Generate_WithLogo_Inline(t *testing.T) {
 	}
 }
 
+func TestGenerator_Generate_WebPLogoMIMEType(t *testing.T) {
+	tmpDir := t.TempDir()
+	webp := []byte("RIFF\x24\x00\x00\x00WEBPVP8 \x18\x00\x00\x00")
+	if err := os.WriteFile(filepath.Join(tmpDir, "logo.webp"), webp, 0644); err != nil {
+		t.Fatalf("Failed to create logo file: %v", err)
+	}
+
+	cred := &formats.ParsedCredential{
+		ID:           "test",
+		Name:         "Test",
+		LogoPath:     "logo.webp",
+		SourceDir:    tmpDir,
+		InlineImages: true,
+	}
+	output, err := (&Generator{}).Generate(cred, &config.Config{Language: "en-US"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	json.Unmarshal(output, &parsed)
+	display := parsed["display"].([]interface{})[0].(map[string]interface{})
+	logo := display["rendering"].(map[string]interface{})["simple"].(map[string]interface{})["logo"].(map[string]interface{})
+	if uri, _ := logo["uri"].(string); !hasPrefix(uri, "data:image/webp;base64,") {
+		t.Errorf("logo.uri = %.40q, want a data:image/webp URL", uri)
+	}
+}
+
 func TestGenerator_Generate_WithLogo_URL(t *testing.T) {
 	g := &Generator{}
 	cfg := &config.Config{
@@ -385,6 +534,52 @@ func TestGenerator_Generate_WithLogo_URL(t *testing.T) {
 	}
 }
 
+func TestGenerator_Generate_AssetBaseURL(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "logo.png"), []byte("png-data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := &Generator{}
+	cfg := &config.Config{
+		Language:     "en-US",
+		BaseURL:      "https://registry.example.com",
+		AssetBaseURL: "https://cdn.example.com/assets/",
+	}
+
+	cred := &formats.ParsedCredential{
+		ID:           "test",
+		Name:         "Test",
+		LogoPath:     "./logo.png",
+		SourceDir:    tmpDir,
+		InlineImages: false,
+	}
+
+	output, err := g.Generate(cred, cfg)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+
+	if parsed["vct"] != "https://registry.example.com/test" {
+		t.Errorf("vct = %v, want base URL host", parsed["vct"])
+	}
+
+	display := parsed["display"].([]interface{})[0].(map[string]interface{})
+	logo := display["rendering"].(map[string]interface{})["simple"].(map[string]interface{})["logo"].(map[string]interface{})
+	if logo["uri"] != "https://cdn.example.com/assets/logo.png" {
+		t.Errorf("logo.uri = %v, want asset base URL host", logo["uri"])
+	}
+	wantIntegrity, _ := formats.FileIntegrity(filepath.Join(tmpDir, "logo.png"))
+	if logo["uri#integrity"] != wantIntegrity {
+		t.Errorf("logo.uri#integrity = %v, want %s", logo["uri#integrity"], wantIntegrity)
+	}
+}
+
 func TestGenerator_Generate_WithSVGTemplate_Inline(t *testing.T) {
 	tmpDir := t.TempDir()
 	svgPath := filepath.Join(tmpDir, "template.svg")
@@ -427,6 +622,69 @@ func TestGenerator_Generate_WithSVGTemplate_Inline(t *testing.T) {
 	}
 }
 
+func TestGenerator_Generate_SVGTemplateMinified(t *testing.T) {
+	tmpDir := t.TempDir()
+	svgContent := "<?xml version=\"1.0\"?>\n<!-- Card template, exported from the editor -->\n" +
+		"<svg xmlns=\"http://www.w3.org/2000/svg\" xmlns:inkscape=\"http://www.inkscape.org/namespaces/inkscape\">\n" +
+		"  <metadata>\n    <title>Editor notes</title>\n  </metadata>\n" +
+		"  <!-- background -->\n  <rect width=\"100\" height=\"50\" inkscape:label=\"bg\"/>\n" +
+		"  <text x=\"10\" y=\"20\">{{given_name}}</text>\n</svg>\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "template.svg"), []byte(svgContent), 0644); err != nil {
+		t.Fatalf("Failed to create SVG file: %v", err)
+	}
+
+	inlined := func(cfg *config.Config) []byte {
+		t.Helper()
+		cred := &formats.ParsedCredential{
+			ID:              "test",
+			Name:            "Test",
+			SVGTemplatePath: "template.svg",
+			SourceDir:       tmpDir,
+			InlineImages:    true,
+		}
+		output, err := (&Generator{}).Generate(cred, cfg)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		var parsed map[string]interface{}
+		if err := json.Unmarshal(output, &parsed); err != nil {
+			t.Fatal(err)
+		}
+		display := parsed["display"].([]interface{})[0].(map[string]interface{})
+		tmpl := display["rendering"].(map[string]interface{})["svg_templates"].([]interface{})[0].(map[string]interface{})
+		data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(tmpl["uri"].(string), "data:image/svg+xml;base64,"))
+		if err != nil {
+			t.Fatalf("uri is not a base64 SVG data URL: %v", err)
+		}
+		return data
+	}
+
+	minified := inlined(&config.Config{Language: "en-US"})
+	if len(minified) >= len(svgContent) {
+		t.Errorf("minified SVG is %d bytes, want fewer than %d", len(minified), len(svgContent))
+	}
+	for _, dropped := range []string{"<!--", "metadata", "inkscape", "\n"} {
+		if strings.Contains(string(minified), dropped) {
+			t.Errorf("minified SVG %q still contains %q", minified, dropped)
+		}
+	}
+	if !strings.Contains(string(minified), `<text x="10" y="20">{{given_name}}</text>`) {
+		t.Errorf("minified SVG %q lost the text element", minified)
+	}
+	dec := xml.NewDecoder(bytes.NewReader(minified))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("minified SVG is not well-formed XML: %v", err)
+		}
+	}
+
+	if raw := inlined(&config.Config{Language: "en-US", NoSVGMinify: true}); string(raw) != svgContent {
+		t.Errorf("NoSVGMinify inlined %q, want the file unchanged", raw)
+	}
+}
+
 func TestGenerator_Generate_WithSVGTemplate_URI(t *testing.T) {
 	g := &Generator{}
 	cfg := &config.Config{Language: "en-US"}
@@ -524,3 +782,58 @@ func contains(s, substr string) bool {
 func hasPrefix(s, prefix string) bool {
 	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
 }
+
+func TestGenerator_Generate_Deterministic(t *testing.T) {
+	g := &Generator{}
+
+	cred := &formats.ParsedCredential{
+		ID:          "many",
+		Name:        "Many Claims",
+		Description: "A credential with many localized claims",
+		Localizations: map[string]formats.DisplayLocalization{
+			"de": {Name: "Viele Ansprüche"},
+			"fr": {Name: "Nombreuses revendications"},
+			"sv": {Name: "Många anspråk"},
+		},
+	}
+	for i := 0; i < 60; i++ {
+		name := fmt.Sprintf("claim_%02d", i)
+		cred.Claims = append(cred.Claims, formats.ClaimDefinition{
+			Name:        name,
+			Path:        []interface{}{"group", name},
+			DisplayName: "Claim " + name,
+			Mandatory:   i%2 == 0,
+			SD:          "allowed",
+			Localizations: map[string]formats.ClaimLocalization{
+				"de": {Label: "Anspruch " + name},
+				"fr": {Label: "Revendication " + name},
+				"sv": {Label: "Anspråk " + name},
+			},
+		})
+	}
+
+	for _, canonical := range []bool{false, true} {
+		cfg := &config.Config{Language: "en-US", Canonical: canonical}
+		first, err := g.Generate(cred, cfg)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		for run := 0; run < 20; run++ {
+			output, err := g.Generate(cred, cfg)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if string(output) != string(first) {
+				t.Fatalf("canonical=%v: run %d produced different bytes", canonical, run)
+			}
+		}
+
+		want, err := formats.CanonicalJSON(json.RawMessage(first))
+		if err != nil {
+			t.Fatalf("CanonicalJSON() error = %v", err)
+		}
+		if canonical && string(first) != string(want) {
+			t.Errorf("canonical output is not in canonical form:\n%s", first)
+		}
+	}
+}
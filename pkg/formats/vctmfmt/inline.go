@@ -0,0 +1,78 @@
+package vctmfmt
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+)
+
+// readFile reads image files for inlining; tests replace it to count reads
+var readFile = os.ReadFile
+
+// inlineCache deduplicates image reads and data URL encoding within a single Generate call.
+// Files are read once per path and identical contents are base64-encoded once.
+type inlineCache struct {
+	files    map[string][]byte
+	dataURLs map[string]string
+
+	// integrity computes integrity hashes for non-inlined files, when set
+	integrity formats.IntegritySource
+}
+
+// newInlineCache creates an empty inline cache
+func newInlineCache() *inlineCache {
+	return &inlineCache{
+		files:    make(map[string][]byte),
+		dataURLs: make(map[string]string),
+	}
+}
+
+// read returns the contents of path, reading the file only on first use
+func (c *inlineCache) read(path string) ([]byte, error) {
+	key := filepath.Clean(path)
+	if data, ok := c.files[key]; ok {
+		return data, nil
+	}
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c.files[key] = data
+	return data, nil
+}
+
+// assetPath returns the path the image at imagePath is published under, see formats.AssetPath
+func (c *inlineCache) assetPath(path, imagePath string, cfg *config.Config) string {
+	if !cfg.HashImageNames {
+		return path
+	}
+	data, err := c.read(imagePath)
+	if err != nil {
+		return path
+	}
+	return formats.HashedAssetPath(path, data)
+}
+
+// fileIntegrity returns the SRI integrity hash of the file at path
+func (c *inlineCache) fileIntegrity(path string) (string, error) {
+	if c.integrity != nil {
+		return c.integrity.Get(path)
+	}
+	return formats.FileIntegrity(path)
+}
+
+// dataURL returns the base64 data URL for data, encoding each distinct content and MIME type once
+func (c *inlineCache) dataURL(data []byte, mimeType string) string {
+	key := fmt.Sprintf("%x %s", sha256.Sum256(data), mimeType)
+	if url, ok := c.dataURLs[key]; ok {
+		return url
+	}
+	url := fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
+	c.dataURLs[key] = url
+	return url
+}
@@ -0,0 +1,98 @@
+package vctmfmt
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+)
+
+// sharedImageCredential returns a credential using the same SVG as logo, explicit template and markdown image
+func sharedImageCredential(t testing.TB) *formats.ParsedCredential {
+	t.Helper()
+	tmpDir := t.TempDir()
+	svgPath := filepath.Join(tmpDir, "card.svg")
+	if err := os.WriteFile(svgPath, []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return &formats.ParsedCredential{
+		ID:              "test",
+		Name:            "Test",
+		LogoPath:        "card.svg",
+		SVGTemplatePath: "card.svg",
+		SourceDir:       tmpDir,
+		InlineImages:    true,
+		Images: []formats.ImageRef{
+			{Path: "card.svg", AbsolutePath: svgPath},
+		},
+	}
+}
+
+// countReads replaces readFile for the duration of the test and returns the per-path read counts
+func countReads(t testing.TB) map[string]int {
+	t.Helper()
+	reads := make(map[string]int)
+	orig := readFile
+	readFile = func(name string) ([]byte, error) {
+		reads[filepath.Clean(name)]++
+		return orig(name)
+	}
+	t.Cleanup(func() { readFile = orig })
+	return reads
+}
+
+func TestGenerator_Generate_SharedImageReadOnce(t *testing.T) {
+	cred := sharedImageCredential(t)
+	reads := countReads(t)
+
+	output, err := (&Generator{}).Generate(cred, &config.Config{Language: "en-US"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	svgPath := filepath.Join(cred.SourceDir, "card.svg")
+	if reads[svgPath] != 1 {
+		t.Errorf("shared image read %d times, want 1", reads[svgPath])
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	rendering := parsed["display"].([]interface{})[0].(map[string]interface{})["rendering"].(map[string]interface{})
+	logoURI := rendering["simple"].(map[string]interface{})["logo"].(map[string]interface{})["uri"]
+	for i, tmpl := range rendering["svg_templates"].([]interface{}) {
+		if uri := tmpl.(map[string]interface{})["uri"]; uri != logoURI {
+			t.Errorf("svg_templates[%d].uri differs from logo uri", i)
+		}
+	}
+}
+
+func TestInlineCache_DataURLDeduplicatesContent(t *testing.T) {
+	cache := newInlineCache()
+	a := cache.dataURL([]byte("same"), "image/png")
+	b := cache.dataURL([]byte("same"), "image/png")
+	if a != b || len(cache.dataURLs) != 1 {
+		t.Errorf("identical content encoded %d times, want 1", len(cache.dataURLs))
+	}
+	if cache.dataURL([]byte("same"), "image/svg+xml") == a {
+		t.Error("different MIME types should produce different data URLs")
+	}
+}
+
+func BenchmarkGenerator_Generate_SharedImage(b *testing.B) {
+	cred := sharedImageCredential(b)
+	cfg := &config.Config{Language: "en-US"}
+	g := &Generator{}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.Generate(cred, cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,11 +2,13 @@
 package w3c
 
 import (
-	"encoding/json"
+	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/sirosfoundation/mtcvctm/pkg/config"
 	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats/jsonschema"
 )
 
 func init() {
@@ -39,8 +41,11 @@ func (g *Generator) FileExtension() string {
 // DeriveIdentifier derives the W3C type array from the parsed credential
 func (g *Generator) DeriveIdentifier(parsed *formats.ParsedCredential, cfg *config.Config) string {
 	types := g.deriveTypes(parsed, cfg)
-	if len(types) > 1 {
-		return types[len(types)-1] // Return the specific type (not VerifiableCredential)
+	// Return the most specific type, i.e. the last one that is not VerifiableCredential
+	for i := len(types) - 1; i >= 0; i-- {
+		if types[i] != "VerifiableCredential" {
+			return types[i]
+		}
 	}
 	return ""
 }
@@ -49,39 +54,26 @@ func (g *Generator) DeriveIdentifier(parsed *formats.ParsedCredential, cfg *conf
 func (g *Generator) deriveTypes(parsed *formats.ParsedCredential, cfg *config.Config) []string {
 	// Check for explicit types
 	if len(parsed.W3CTypes) > 0 {
-		// Ensure VerifiableCredential is first
-		hasVC := false
-		for _, t := range parsed.W3CTypes {
-			if t == "VerifiableCredential" {
-				hasVC = true
-				break
-			}
-		}
-		if !hasVC {
-			return append([]string{"VerifiableCredential"}, parsed.W3CTypes...)
-		}
-		return parsed.W3CTypes
+		return normalizeTypes(parsed.W3CTypes)
 	}
 
 	// Check format-specific override
 	if overrides, ok := parsed.FormatOverrides["w3c"]; ok {
-		if types, ok := overrides["type"].([]interface{}); ok {
-			result := make([]string, 0, len(types)+1)
-			hasVC := false
-			for _, t := range types {
+		var types []string
+		switch v := overrides["type"].(type) {
+		case string:
+			types = []string{v}
+		case []string:
+			types = v
+		case []interface{}:
+			for _, t := range v {
 				if s, ok := t.(string); ok {
-					if s == "VerifiableCredential" {
-						hasVC = true
-					}
-					result = append(result, s)
+					types = append(types, s)
 				}
 			}
-			if !hasVC && len(result) > 0 {
-				result = append([]string{"VerifiableCredential"}, result...)
-			}
-			if len(result) > 0 {
-				return result
-			}
+		}
+		if result := normalizeTypes(types); len(result) > 0 {
+			return result
 		}
 	}
 
@@ -100,6 +92,26 @@ func (g *Generator) deriveTypes(parsed *formats.ParsedCredential, cfg *config.Co
 	return types
 }
 
+// normalizeTypes returns a new type array with VerifiableCredential first, followed by the
+// remaining types in their given order with empty and duplicate entries removed.
+// It returns nil if types contains no usable entry.
+func normalizeTypes(types []string) []string {
+	result := []string{"VerifiableCredential"}
+	seen := map[string]bool{"VerifiableCredential": true}
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		result = append(result, t)
+	}
+	if len(result) == 1 && !containsString(types, "VerifiableCredential") {
+		return nil
+	}
+	return result
+}
+
 // deriveContext derives the @context array
 func (g *Generator) deriveContext(parsed *formats.ParsedCredential, cfg *config.Config) []string {
 	// Check for explicit context
@@ -122,16 +134,19 @@ func (g *Generator) deriveContext(parsed *formats.ParsedCredential, cfg *config.
 		}
 	}
 
-	// Default context
-	contexts := []string{"https://www.w3.org/2018/credentials/v1"}
-
-	// Add custom context based on base URL
-	if cfg.BaseURL != "" && parsed.ID != "" {
-		baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
-		contexts = append(contexts, baseURL+"/contexts/"+parsed.ID+"/v1")
-	}
+	// Configured base context and per-credential context based on the base URL
+	return cfg.W3CContext(parsed.ID)
+}
 
-	return contexts
+// CredentialConfiguration returns the OpenID4VCI credential configuration for jwt_vc_json
+func (g *Generator) CredentialConfiguration(parsed *formats.ParsedCredential, cfg *config.Config) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"format": "jwt_vc_json",
+		"credential_definition": map[string]interface{}{
+			"type": g.deriveTypes(parsed, cfg),
+		},
+		"display": formats.CredentialDisplay(parsed, cfg),
+	}, nil
 }
 
 // W3CCredentialSchema represents a W3C VC credential schema
@@ -150,30 +165,26 @@ type DisplayProperties struct {
 	TextColor       string `json:"textColor,omitempty"`
 }
 
-// CredentialSchema represents the JSON Schema for the credential
+// CredentialSchema references the JSON Schema document for the credential
 type CredentialSchema struct {
-	Type       string                 `json:"type"`
-	Properties map[string]interface{} `json:"properties,omitempty"`
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	DigestSRI string `json:"digestSRI,omitempty"`
 }
 
-// SchemaProperty represents a JSON Schema property
-type SchemaProperty struct {
-	Type            string                     `json:"type"`
-	Title           string                     `json:"title,omitempty"`
-	Description     string                     `json:"description,omitempty"`
-	Format          string                     `json:"format,omitempty"`
-	ContentEncoding string                     `json:"contentEncoding,omitempty"`
-	Items           *SchemaProperty            `json:"items,omitempty"`
-	Properties      map[string]*SchemaProperty `json:"properties,omitempty"`
-	Required        []string                   `json:"required,omitempty"`
+// JSONSchemaDocument is the standalone JSON Schema referenced by credentialSchema
+type JSONSchemaDocument struct {
+	Schema      string                     `json:"$schema"`
+	ID          string                     `json:"$id,omitempty"`
+	Title       string                     `json:"title,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Type        string                     `json:"type"`
+	Properties  map[string]*SchemaProperty `json:"properties"`
+	Required    []string                   `json:"required"`
 }
 
-// CredentialSubjectSchema represents the credentialSubject part of the schema
-type CredentialSubjectSchema struct {
-	Type       string                     `json:"type"`
-	Properties map[string]*SchemaProperty `json:"properties,omitempty"`
-	Required   []string                   `json:"required,omitempty"`
-}
+// SchemaProperty represents a JSON Schema property
+type SchemaProperty = jsonschema.Property
 
 // Generate produces the W3C VC schema output
 func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Config) ([]byte, error) {
@@ -192,73 +203,145 @@ func (g *Generator) Generate(parsed *formats.ParsedCredential, cfg *config.Confi
 		}
 	}
 
-	// Build credential schema
+	// Reference the standalone JSON Schema written alongside the output
 	if len(parsed.Claims) > 0 {
-		credSubject := &CredentialSubjectSchema{
-			Type:       "object",
-			Properties: make(map[string]*SchemaProperty),
+		schema.CredentialSchema = &CredentialSchema{
+			ID:   g.schemaID(parsed, cfg),
+			Type: "JsonSchema",
 		}
+	}
 
-		for _, claim := range parsed.Claims {
-			// Get claim name, applying format mapping if present
-			claimName := claim.Name
-			if mapping, ok := claim.FormatMappings["w3c"]; ok {
-				claimName = mapping
-			}
-			// Also check ClaimMappings from parsed credential
-			if mappings, ok := parsed.ClaimMappings["w3c"]; ok {
-				if mapped, ok := mappings[claim.Name]; ok {
-					claimName = mapped
-				}
-			}
+	// An external schema reference replaces the derived schema
+	if parsed.CredentialSchema != nil {
+		external, err := externalCredentialSchema(parsed)
+		if err != nil {
+			return nil, err
+		}
+		schema.CredentialSchema = external
+	}
 
-			prop := mapTypeToJSONSchema(claim.Type)
-			prop.Title = claim.DisplayName
-			if prop.Title == "" {
-				prop.Title = claim.Name
-			}
-			prop.Description = claim.Description
+	return formats.FormatJSON(schema, cfg)
+}
 
-			credSubject.Properties[claimName] = prop
+// Schema returns the standalone JSON Schema document referenced by credentialSchema.
+// It returns nil when the credential has no claims or references an external schema.
+func (g *Generator) Schema(parsed *formats.ParsedCredential, cfg *config.Config) ([]byte, error) {
+	if len(parsed.Claims) == 0 || parsed.CredentialSchema != nil {
+		return nil, nil
+	}
 
-			if claim.Mandatory {
-				credSubject.Required = append(credSubject.Required, claimName)
-			}
-		}
+	doc := &JSONSchemaDocument{
+		Schema:      jsonschema.Dialect,
+		ID:          g.schemaID(parsed, cfg),
+		Title:       parsed.Name,
+		Description: parsed.Description,
+		Type:        "object",
+		Properties: map[string]*SchemaProperty{
+			"credentialSubject": g.credentialSubjectSchema(parsed),
+		},
+		Required: []string{"credentialSubject"},
+	}
 
-		schema.CredentialSchema = &CredentialSchema{
+	return formats.FormatJSON(doc, cfg)
+}
+
+// ExampleCredential is a sample W3C verifiable credential
+type ExampleCredential struct {
+	Context           []string               `json:"@context"`
+	Type              []string               `json:"type"`
+	Issuer            string                 `json:"issuer"`
+	ValidFrom         string                 `json:"validFrom"`
+	CredentialSchema  *CredentialSchema      `json:"credentialSchema,omitempty"`
+	CredentialSubject map[string]interface{} `json:"credentialSubject"`
+}
+
+// exampleIssuer and exampleValidFrom are fixed so that examples are reproducible
+const (
+	exampleIssuer    = "did:example:issuer"
+	exampleValidFrom = "2024-01-01T00:00:00Z"
+)
+
+// Example returns a sample credential with the context, types and schema reference
+// of the generated output and a credentialSubject populated from claim examples,
+// constants and allowed values that validates against the standalone schema.
+// It returns nil when the credential has no claims.
+func (g *Generator) Example(parsed *formats.ParsedCredential, cfg *config.Config) ([]byte, error) {
+	if len(parsed.Claims) == 0 {
+		return nil, nil
+	}
+
+	example := &ExampleCredential{
+		Context:           g.deriveContext(parsed, cfg),
+		Type:              g.deriveTypes(parsed, cfg),
+		Issuer:            exampleIssuer,
+		ValidFrom:         exampleValidFrom,
+		CredentialSubject: jsonschema.ExampleInstance(parsed, "w3c"),
+	}
+	if parsed.CredentialSchema != nil {
+		external, err := externalCredentialSchema(parsed)
+		if err != nil {
+			return nil, err
+		}
+		example.CredentialSchema = external
+	} else {
+		example.CredentialSchema = &CredentialSchema{
+			ID:   g.schemaID(parsed, cfg),
 			Type: "JsonSchema",
-			Properties: map[string]interface{}{
-				"credentialSubject": credSubject,
-			},
 		}
 	}
 
-	return json.MarshalIndent(schema, "", "  ")
+	return formats.FormatJSON(example, cfg)
 }
 
-// mapTypeToJSONSchema maps markdown types to JSON Schema properties
-func mapTypeToJSONSchema(mdType string) *SchemaProperty {
-	switch strings.ToLower(mdType) {
-	case "string":
-		return &SchemaProperty{Type: "string"}
-	case "number":
-		return &SchemaProperty{Type: "number"}
-	case "integer":
-		return &SchemaProperty{Type: "integer"}
-	case "boolean", "bool":
-		return &SchemaProperty{Type: "boolean"}
-	case "date":
-		return &SchemaProperty{Type: "string", Format: "date"}
-	case "datetime":
-		return &SchemaProperty{Type: "string", Format: "date-time"}
-	case "image":
-		return &SchemaProperty{Type: "string", ContentEncoding: "base64"}
-	case "object":
-		return &SchemaProperty{Type: "object"}
-	case "array":
-		return &SchemaProperty{Type: "array", Items: &SchemaProperty{Type: "string"}}
-	default:
-		return &SchemaProperty{Type: "string"}
+// schemaID returns the URL of the standalone schema, <base_url>/<id>.schema.json,
+// or a relative reference when no base URL is configured
+func (g *Generator) schemaID(parsed *formats.ParsedCredential, cfg *config.Config) string {
+	name := parsed.ID + ".schema.json"
+	if cfg.BaseURL == "" {
+		return name
+	}
+	return strings.TrimSuffix(cfg.BaseURL, "/") + "/" + name
+}
+
+// credentialSubjectSchema builds the JSON Schema for the credentialSubject from the claims
+func (g *Generator) credentialSubjectSchema(parsed *formats.ParsedCredential) *SchemaProperty {
+	return jsonschema.ClaimsSchema(parsed, "w3c")
+}
+
+// externalCredentialSchema builds a credentialSchema reference, computing its
+// integrity from the local copy when no explicit integrity is given
+func externalCredentialSchema(parsed *formats.ParsedCredential) (*CredentialSchema, error) {
+	ref := parsed.CredentialSchema
+	external := &CredentialSchema{
+		ID:        ref.ID,
+		Type:      ref.Type,
+		DigestSRI: ref.Integrity,
+	}
+	if external.Type == "" {
+		external.Type = "JsonSchema"
+	}
+
+	if external.DigestSRI == "" && ref.Path != "" {
+		schemaPath := ref.Path
+		if !filepath.IsAbs(schemaPath) {
+			schemaPath = filepath.Join(parsed.SourceDir, schemaPath)
+		}
+		integrity, err := parsed.FileIntegrity(schemaPath)
+		if err != nil {
+			return nil, fmt.Errorf("w3c: failed to compute credential schema integrity: %w", err)
+		}
+		external.DigestSRI = integrity
+	}
+
+	return external, nil
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
 	}
+	return false
 }
@@ -1,11 +1,18 @@
 package w3c
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	validator "github.com/santhosh-tekuri/jsonschema/v6"
 	"github.com/sirosfoundation/mtcvctm/pkg/config"
 	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats/jsonschema"
 )
 
 func TestNewGenerator(t *testing.T) {
@@ -73,6 +80,14 @@ func TestGenerator_DeriveIdentifier(t *testing.T) {
 			cfg:  &config.Config{},
 			want: "Pid",
 		},
+		{
+			name: "skips trailing VerifiableCredential",
+			cred: &formats.ParsedCredential{
+				W3CTypes: []string{"DriverLicense", "VerifiableCredential"},
+			},
+			cfg:  &config.Config{},
+			want: "DriverLicense",
+		},
 	}
 
 	for _, tt := range tests {
@@ -162,6 +177,7 @@ func TestGenerator_deriveContext(t *testing.T) {
 		cfg     *config.Config
 		wantLen int
 		want0   string
+		want1   string
 	}{
 		{
 			name: "explicit context",
@@ -194,14 +210,29 @@ func TestGenerator_deriveContext(t *testing.T) {
 				BaseURL: "https://registry.example.com",
 			},
 			wantLen: 2,
-			want0:   "https://www.w3.org/2018/credentials/v1",
+			want0:   "https://www.w3.org/ns/credentials/v2",
+			want1:   "https://registry.example.com/contexts/identity/v1",
 		},
 		{
 			name:    "default without base URL",
 			cred:    &formats.ParsedCredential{},
 			cfg:     &config.Config{},
 			wantLen: 1,
+			want0:   "https://www.w3.org/ns/credentials/v2",
+		},
+		{
+			name: "configured base and path pattern",
+			cred: &formats.ParsedCredential{
+				ID: "identity",
+			},
+			cfg: &config.Config{
+				BaseURL:        "https://registry.example.com/",
+				W3CContextBase: "https://www.w3.org/2018/credentials/v1",
+				W3CContextPath: "{base_url}/ld/{id}.v2.jsonld",
+			},
+			wantLen: 2,
 			want0:   "https://www.w3.org/2018/credentials/v1",
+			want1:   "https://registry.example.com/ld/identity.v2.jsonld",
 		},
 	}
 
@@ -214,6 +245,9 @@ func TestGenerator_deriveContext(t *testing.T) {
 			if ctx[0] != tt.want0 {
 				t.Errorf("context[0] = %q, want %q", ctx[0], tt.want0)
 			}
+			if tt.want1 != "" && ctx[1] != tt.want1 {
+				t.Errorf("context[1] = %q, want %q", ctx[1], tt.want1)
+			}
 		})
 	}
 }
@@ -300,9 +334,10 @@ func TestGenerator_Generate_WithColors(t *testing.T) {
 
 func TestGenerator_Generate_WithClaims(t *testing.T) {
 	g := NewGenerator()
-	cfg := &config.Config{Language: "en-US"}
+	cfg := &config.Config{Language: "en-US", BaseURL: "https://registry.example.com/"}
 
 	cred := &formats.ParsedCredential{
+		ID:   "test",
 		Name: "Test",
 		Claims: []formats.ClaimDefinition{
 			{
@@ -330,63 +365,104 @@ func TestGenerator_Generate_WithClaims(t *testing.T) {
 	}
 
 	var parsed W3CCredentialSchema
-	json.Unmarshal(output, &parsed)
-
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
 	if parsed.CredentialSchema == nil {
 		t.Fatal("CredentialSchema should not be nil")
 	}
 	if parsed.CredentialSchema.Type != "JsonSchema" {
 		t.Errorf("CredentialSchema.Type = %q", parsed.CredentialSchema.Type)
 	}
+	if parsed.CredentialSchema.ID != "https://registry.example.com/test.schema.json" {
+		t.Errorf("CredentialSchema.ID = %q, want https://registry.example.com/test.schema.json", parsed.CredentialSchema.ID)
+	}
+	if strings.Contains(string(output), "given_name") {
+		t.Error("claims should only appear in the standalone schema")
+	}
 
-	// Check credentialSubject properties
-	csRaw, ok := parsed.CredentialSchema.Properties["credentialSubject"]
-	if !ok {
+	schemaData, err := g.Schema(cred, cfg)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	var doc JSONSchemaDocument
+	if err := json.Unmarshal(schemaData, &doc); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+	if doc.ID != parsed.CredentialSchema.ID {
+		t.Errorf("schema $id = %q, want %q", doc.ID, parsed.CredentialSchema.ID)
+	}
+	if doc.Schema != "https://json-schema.org/draft/2020-12/schema" {
+		t.Errorf("schema $schema = %q", doc.Schema)
+	}
+
+	cs := doc.Properties["credentialSubject"]
+	if cs == nil {
 		t.Fatal("Missing credentialSubject")
 	}
+	for _, name := range []string{"given_name", "birth_date", "is_adult"} {
+		if cs.Properties[name] == nil {
+			t.Errorf("Missing %s property", name)
+		}
+	}
+	if !containsString(cs.Required, "given_name") || !containsString(cs.Required, "is_adult") {
+		t.Errorf("required = %v, want given_name and is_adult", cs.Required)
+	}
 
-	cs, ok := csRaw.(*CredentialSubjectSchema)
-	if !ok {
-		// Parse from raw JSON
-		csJSON, _ := json.Marshal(csRaw)
-		var csMap map[string]interface{}
-		json.Unmarshal(csJSON, &csMap)
+	// The schema validates a conforming credential and rejects a non-conforming one
+	valid := map[string]interface{}{
+		"credentialSubject": map[string]interface{}{"given_name": "Alice", "birth_date": "2000-01-01", "is_adult": true},
+	}
+	if errs := validateInstance(doc.Properties, doc.Required, valid); len(errs) > 0 {
+		t.Errorf("valid subject rejected: %v", errs)
+	}
+	invalid := map[string]interface{}{
+		"credentialSubject": map[string]interface{}{"given_name": 42},
+	}
+	if errs := validateInstance(doc.Properties, doc.Required, invalid); len(errs) != 2 {
+		t.Errorf("invalid subject errors = %v, want wrong given_name type and missing is_adult", errs)
+	}
+}
 
-		// Check properties exist
-		props, ok := csMap["properties"].(map[string]interface{})
-		if !ok {
-			t.Fatal("Missing credentialSubject.properties")
-		}
-		if props["given_name"] == nil {
-			t.Error("Missing given_name property")
-		}
-		if props["birth_date"] == nil {
-			t.Error("Missing birth_date property")
+// validateInstance is a minimal JSON Schema validator covering the keywords the
+// generator emits for objects (type, properties, required)
+func validateInstance(props map[string]*SchemaProperty, required []string, instance map[string]interface{}) []string {
+	var errs []string
+	for _, name := range required {
+		if _, ok := instance[name]; !ok {
+			errs = append(errs, "missing "+name)
 		}
-
-		// Check required
-		required, ok := csMap["required"].([]interface{})
+	}
+	for name, value := range instance {
+		prop, ok := props[name]
 		if !ok {
-			t.Fatal("Missing credentialSubject.required")
+			continue
 		}
-		requiredSet := make(map[string]bool)
-		for _, r := range required {
-			if s, ok := r.(string); ok {
-				requiredSet[s] = true
+		switch prop.Type {
+		case "object":
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				errs = append(errs, name+": want object")
+				continue
+			}
+			errs = append(errs, validateInstance(prop.Properties, prop.Required, obj)...)
+		case "string":
+			if _, ok := value.(string); !ok {
+				errs = append(errs, name+": want string")
+			}
+		case "boolean":
+			if _, ok := value.(bool); !ok {
+				errs = append(errs, name+": want boolean")
+			}
+		case "number", "integer":
+			if _, ok := value.(float64); !ok {
+				if _, ok := value.(int); !ok {
+					errs = append(errs, name+": want number")
+				}
 			}
 		}
-		if !requiredSet["given_name"] {
-			t.Error("given_name should be required")
-		}
-		if !requiredSet["is_adult"] {
-			t.Error("is_adult should be required")
-		}
-		return
-	}
-
-	if cs.Properties["given_name"] == nil {
-		t.Error("Missing given_name property")
 	}
+	return errs
 }
 
 func TestGenerator_Generate_WithClaimMappings(t *testing.T) {
@@ -406,9 +482,9 @@ func TestGenerator_Generate_WithClaimMappings(t *testing.T) {
 		},
 	}
 
-	output, err := g.Generate(cred, cfg)
+	output, err := g.Schema(cred, cfg)
 	if err != nil {
-		t.Fatalf("Generate() error = %v", err)
+		t.Fatalf("Schema() error = %v", err)
 	}
 
 	// Should use mapped name "givenName" instead of "given_name"
@@ -417,53 +493,540 @@ func TestGenerator_Generate_WithClaimMappings(t *testing.T) {
 	}
 }
 
-func TestMapTypeToJSONSchema(t *testing.T) {
-	tests := []struct {
-		input    string
-		wantType string
-		wantFmt  string
-	}{
-		{"string", "string", ""},
-		{"STRING", "string", ""},
-		{"number", "number", ""},
-		{"integer", "integer", ""},
-		{"boolean", "boolean", ""},
-		{"bool", "boolean", ""},
-		{"date", "string", "date"},
-		{"datetime", "string", "date-time"},
-		{"image", "string", ""}, // has contentEncoding
-		{"object", "object", ""},
-		{"array", "array", ""},
-		{"unknown", "string", ""},
+func TestGenerator_Generate_WithConst(t *testing.T) {
+	g := NewGenerator()
+	cfg := &config.Config{Language: "en-US"}
+
+	cred := &formats.ParsedCredential{
+		Name: "Test",
+		Claims: []formats.ClaimDefinition{
+			{Name: "credential_type", Type: "string", Const: "StudentCard"},
+			{Name: "version", Type: "integer", Const: "2"},
+			{Name: "given_name", Type: "string"},
+		},
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			prop := mapTypeToJSONSchema(tt.input)
-			if prop.Type != tt.wantType {
-				t.Errorf("Type = %q, want %q", prop.Type, tt.wantType)
-			}
-			if prop.Format != tt.wantFmt {
-				t.Errorf("Format = %q, want %q", prop.Format, tt.wantFmt)
+	output, err := g.Schema(cred, cfg)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	props := parsed["properties"].(map[string]interface{})["credentialSubject"].(map[string]interface{})["properties"].(map[string]interface{})
+
+	if got := props["credential_type"].(map[string]interface{})["const"]; got != "StudentCard" {
+		t.Errorf("credential_type const = %v, want StudentCard", got)
+	}
+	if got := props["version"].(map[string]interface{})["const"]; got != float64(2) {
+		t.Errorf("version const = %v (%T), want numeric 2", got, got)
+	}
+	if _, ok := props["given_name"].(map[string]interface{})["const"]; ok {
+		t.Error("given_name should not have a const")
+	}
+}
+
+func TestGenerator_Generate_WithConstraints(t *testing.T) {
+	g := NewGenerator()
+	cfg := &config.Config{Language: "en-US"}
+
+	cred := &formats.ParsedCredential{
+		Name: "Test",
+		Claims: []formats.ClaimDefinition{
+			{Name: "age", Type: "integer", Min: "0", Max: "120"},
+			{Name: "country", Type: "string", Enum: []string{"SE", "DE", "FR"}},
+			{Name: "level", Type: "integer", Enum: []string{"1", "2"}},
+			{Name: "postal_code", Type: "string", Pattern: `^\d{5}$`},
+			{Name: "address.score", Path: []interface{}{"address", "score"}, Type: "number", Min: "0.5"},
+			{Name: "given_name", Type: "string"},
+		},
+	}
+
+	output, err := g.Schema(cred, cfg)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	props := parsed["properties"].(map[string]interface{})["credentialSubject"].(map[string]interface{})["properties"].(map[string]interface{})
+	prop := func(name string) map[string]interface{} { return props[name].(map[string]interface{}) }
+
+	if got := prop("age")["minimum"]; got != float64(0) {
+		t.Errorf("age minimum = %v, want 0", got)
+	}
+	if got := prop("age")["maximum"]; got != float64(120) {
+		t.Errorf("age maximum = %v, want 120", got)
+	}
+	if got := fmt.Sprint(prop("country")["enum"]); got != "[SE DE FR]" {
+		t.Errorf("country enum = %v, want [SE DE FR]", got)
+	}
+	if got := prop("level")["enum"].([]interface{}); got[0] != float64(1) {
+		t.Errorf("level enum = %v, want numeric values", got)
+	}
+	if got := prop("postal_code")["pattern"]; got != `^\d{5}$` {
+		t.Errorf("postal_code pattern = %v", got)
+	}
+	score := prop("address")["properties"].(map[string]interface{})["score"].(map[string]interface{})
+	if got := score["minimum"]; got != 0.5 {
+		t.Errorf("address.score minimum = %v, want 0.5", got)
+	}
+	for _, keyword := range []string{"enum", "minimum", "maximum", "pattern"} {
+		if _, ok := prop("given_name")[keyword]; ok {
+			t.Errorf("given_name should not have %s", keyword)
+		}
+	}
+}
+
+func TestGenerator_Generate_WithExamples(t *testing.T) {
+	g := NewGenerator()
+	cfg := &config.Config{Language: "en-US"}
+
+	cred := &formats.ParsedCredential{
+		Name: "Test",
+		Claims: []formats.ClaimDefinition{
+			{Name: "age", Type: "integer", Example: "42", Examples: []string{"18", "42", "65"}},
+			{Name: "given_name", Type: "string", Examples: []string{"Alice", "Bob"}},
+			{Name: "family_name", Type: "string"},
+		},
+	}
+
+	output, err := g.Schema(cred, cfg)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	props := parsed["properties"].(map[string]interface{})["credentialSubject"].(map[string]interface{})["properties"].(map[string]interface{})
+
+	age, ok := props["age"].(map[string]interface{})["examples"].([]interface{})
+	if !ok {
+		t.Fatalf("age examples should be an array, got %v", props["age"])
+	}
+	wantAge := []interface{}{float64(42), float64(18), float64(65)}
+	if len(age) != len(wantAge) {
+		t.Fatalf("age examples = %v, want %v", age, wantAge)
+	}
+	for i := range wantAge {
+		if age[i] != wantAge[i] {
+			t.Errorf("age examples[%d] = %v (%T), want %v", i, age[i], age[i], wantAge[i])
+		}
+	}
+
+	names := props["given_name"].(map[string]interface{})["examples"].([]interface{})
+	if len(names) != 2 || names[0] != "Alice" || names[1] != "Bob" {
+		t.Errorf("given_name examples = %v, want [Alice Bob]", names)
+	}
+	if _, ok := props["family_name"].(map[string]interface{})["examples"]; ok {
+		t.Error("family_name should not have examples")
+	}
+}
+
+func TestGenerator_Generate_ExternalCredentialSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	schemaPath := filepath.Join(tmpDir, "identity.schema.json")
+	if err := os.WriteFile(schemaPath, []byte(`{"type":"object"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wantIntegrity, err := formats.FileIntegrity(schemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGenerator()
+	cred := &formats.ParsedCredential{
+		Name:      "Identity",
+		SourceDir: tmpDir,
+		Claims: []formats.ClaimDefinition{
+			{Name: "given_name", Type: "string"},
+		},
+		CredentialSchema: &formats.CredentialSchemaRef{
+			ID:   "https://example.com/schemas/identity.json",
+			Path: "identity.schema.json",
+		},
+	}
+
+	output, err := g.Generate(cred, &config.Config{Language: "en-US"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	schema := parsed["credentialSchema"].(map[string]interface{})
+	if schema["id"] != "https://example.com/schemas/identity.json" {
+		t.Errorf("credentialSchema.id = %v", schema["id"])
+	}
+	if schema["type"] != "JsonSchema" {
+		t.Errorf("credentialSchema.type = %v, want JsonSchema", schema["type"])
+	}
+	if schema["digestSRI"] != wantIntegrity {
+		t.Errorf("credentialSchema.digestSRI = %v, want %s", schema["digestSRI"], wantIntegrity)
+	}
+	if standalone, err := g.Schema(cred, &config.Config{Language: "en-US"}); err != nil || standalone != nil {
+		t.Errorf("Schema() = %s, %v; want no standalone schema for an external reference", standalone, err)
+	}
+
+	// A missing local copy is an error
+	cred.CredentialSchema.Path = "missing.json"
+	if _, err := g.Generate(cred, &config.Config{Language: "en-US"}); err == nil {
+		t.Error("expected error for missing local schema copy")
+	}
+}
+
+func TestGenerator_DeriveIdentifier_StableWithOverrides(t *testing.T) {
+	g := NewGenerator()
+	cfg := &config.Config{}
+
+	newCred := func() *formats.ParsedCredential {
+		return &formats.ParsedCredential{
+			Name: "Ignored Name",
+			FormatOverrides: map[string]map[string]interface{}{
+				"w3c": {
+					"type":    []interface{}{"EuropeanCredential", "VerifiableCredential", "StudentCard", "StudentCard"},
+					"context": []interface{}{"https://www.w3.org/2018/credentials/v1"},
+				},
+			},
+		}
+	}
+
+	wantTypes := []string{"VerifiableCredential", "EuropeanCredential", "StudentCard"}
+	for i := 0; i < 50; i++ {
+		cred := newCred()
+		if got := g.DeriveIdentifier(cred, cfg); got != "StudentCard" {
+			t.Fatalf("derivation %d: DeriveIdentifier() = %q, want StudentCard", i, got)
+		}
+		types := g.deriveTypes(cred, cfg)
+		if len(types) != len(wantTypes) {
+			t.Fatalf("derivation %d: deriveTypes() = %v, want %v", i, types, wantTypes)
+		}
+		for j := range wantTypes {
+			if types[j] != wantTypes[j] {
+				t.Fatalf("derivation %d: deriveTypes() = %v, want %v", i, types, wantTypes)
 			}
-		})
+		}
+	}
+
+	// A single string override is accepted too
+	cred := &formats.ParsedCredential{
+		FormatOverrides: map[string]map[string]interface{}{"w3c": {"type": "StudentCard"}},
+	}
+	if got := g.DeriveIdentifier(cred, cfg); got != "StudentCard" {
+		t.Errorf("DeriveIdentifier() with string override = %q, want StudentCard", got)
+	}
+}
+
+func TestGenerator_Generate_IndexedClaims(t *testing.T) {
+	g := NewGenerator()
+	cfg := &config.Config{Language: "en-US"}
+
+	cred := &formats.ParsedCredential{
+		Name: "Test",
+		Claims: []formats.ClaimDefinition{
+			{Name: "addresses[1]", Path: formats.ParseClaimPath("addresses[1]"), Type: "string", DisplayName: "Secondary"},
+			{Name: "addresses[0]", Path: formats.ParseClaimPath("addresses[0]"), Type: "string", DisplayName: "Primary", Mandatory: true},
+		},
+	}
+
+	output, err := g.Schema(cred, cfg)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	subject := parsed["properties"].(map[string]interface{})["credentialSubject"].(map[string]interface{})
+	props := subject["properties"].(map[string]interface{})
+
+	if len(props) != 1 {
+		t.Errorf("len(properties) = %d, want 1 (addresses)", len(props))
+	}
+	addresses, ok := props["addresses"].(map[string]interface{})
+	if !ok {
+		t.Fatal("addresses property missing")
+	}
+	if addresses["type"] != "array" {
+		t.Errorf("addresses type = %v, want array", addresses["type"])
+	}
+	prefixItems, ok := addresses["prefixItems"].([]interface{})
+	if !ok || len(prefixItems) != 2 {
+		t.Fatalf("addresses prefixItems = %v, want 2 entries", addresses["prefixItems"])
+	}
+	for i, wantTitle := range []string{"Primary", "Secondary"} {
+		item := prefixItems[i].(map[string]interface{})
+		if item["type"] != "string" || item["title"] != wantTitle {
+			t.Errorf("prefixItems[%d] = %v, want string titled %s", i, item, wantTitle)
+		}
+	}
+
+	required, _ := subject["required"].([]interface{})
+	if len(required) != 1 || required[0] != "addresses" {
+		t.Errorf("required = %v, want [addresses]", required)
+	}
+}
+
+func TestGenerator_Generate_ArraySelectorClaims(t *testing.T) {
+	g := NewGenerator()
+	cfg := &config.Config{Language: "en-US"}
+
+	cred := &formats.ParsedCredential{
+		Name: "Test",
+		Claims: []formats.ClaimDefinition{
+			{Name: "nationalities[]", Path: formats.ParseClaimPath("nationalities[]"), Type: "string"},
+			{Name: "addresses[].street", Path: formats.ParseClaimPath("addresses[].street"), Type: "string"},
+		},
+	}
+
+	output, err := g.Schema(cred, cfg)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	props := parsed["properties"].(map[string]interface{})["credentialSubject"].(map[string]interface{})["properties"].(map[string]interface{})
+
+	nationalities := props["nationalities"].(map[string]interface{})
+	if nationalities["type"] != "array" {
+		t.Errorf("nationalities type = %v, want array", nationalities["type"])
+	}
+	if items, ok := nationalities["items"].(map[string]interface{}); !ok || items["type"] != "string" {
+		t.Errorf("nationalities items = %v, want string schema", nationalities["items"])
+	}
+
+	addressItems := props["addresses"].(map[string]interface{})["items"].(map[string]interface{})
+	if addressItems["type"] != "object" {
+		t.Fatalf("addresses items = %v, want object", addressItems)
+	}
+	street := addressItems["properties"].(map[string]interface{})["street"].(map[string]interface{})
+	if street["type"] != "string" {
+		t.Errorf("addresses[].street = %v, want string", street)
+	}
+}
+
+func TestGenerator_Generate_NestedClaims(t *testing.T) {
+	g := NewGenerator()
+	cfg := &config.Config{Language: "en-US"}
+
+	// Children are listed before their parent to check the parent does not discard them
+	cred := &formats.ParsedCredential{
+		Name: "Test",
+		Claims: []formats.ClaimDefinition{
+			{Name: "address.geo.lat", Path: formats.ParseClaimPath("address.geo.lat"), Type: "number"},
+			{Name: "address.street", Path: formats.ParseClaimPath("address.street"), Type: "string", Mandatory: true},
+			{Name: "address", Path: formats.ParseClaimPath("address"), Type: "object", DisplayName: "Address"},
+			{Name: "address.geo.lon", Path: formats.ParseClaimPath("address.geo.lon"), Type: "number"},
+		},
+	}
+
+	output, err := g.Schema(cred, cfg)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	subject := parsed["properties"].(map[string]interface{})["credentialSubject"].(map[string]interface{})
+	props := subject["properties"].(map[string]interface{})
+
+	if len(props) != 1 {
+		t.Errorf("len(properties) = %d, want 1 (address)", len(props))
+	}
+	address, ok := props["address"].(map[string]interface{})
+	if !ok {
+		t.Fatal("address property missing")
+	}
+	if address["type"] != "object" || address["title"] != "Address" {
+		t.Errorf("address = %v, want object titled Address", address)
+	}
+	addressProps := address["properties"].(map[string]interface{})
+	if street := addressProps["street"].(map[string]interface{}); street["type"] != "string" {
+		t.Errorf("address.street = %v, want string", street)
+	}
+	geo, ok := addressProps["geo"].(map[string]interface{})
+	if !ok || geo["type"] != "object" {
+		t.Fatalf("address.geo = %v, want object", addressProps["geo"])
+	}
+	geoProps := geo["properties"].(map[string]interface{})
+	for _, name := range []string{"lat", "lon"} {
+		if leaf, ok := geoProps[name].(map[string]interface{}); !ok || leaf["type"] != "number" {
+			t.Errorf("address.geo.%s = %v, want number", name, geoProps[name])
+		}
+	}
+
+	required, _ := subject["required"].([]interface{})
+	if len(required) != 1 || required[0] != "address" {
+		t.Errorf("required = %v, want [address]", required)
 	}
 }
 
-func TestMapTypeToJSONSchema_ImageEncoding(t *testing.T) {
-	prop := mapTypeToJSONSchema("image")
-	if prop.ContentEncoding != "base64" {
-		t.Errorf("ContentEncoding = %q, want 'base64'", prop.ContentEncoding)
+func TestGenerator_Schema_FormatHints(t *testing.T) {
+	g := NewGenerator()
+	cfg := &config.Config{Language: "en-US"}
+
+	cred := &formats.ParsedCredential{
+		Name: "Test",
+		Claims: []formats.ClaimDefinition{
+			{Name: "email", Type: "email"},
+			{Name: "homepage", Type: "uri"},
+			{Name: "subject_id", Type: "uuid"},
+			{Name: "phone", Type: "phone"},
+			{Name: "work_phone", Type: "phone", Pattern: `^\+46[0-9]+$`},
+		},
+	}
+
+	output, err := g.Schema(cred, cfg)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	props := parsed["properties"].(map[string]interface{})["credentialSubject"].(map[string]interface{})["properties"].(map[string]interface{})
+
+	for name, wantFormat := range map[string]string{"email": "email", "homepage": "uri", "subject_id": "uuid"} {
+		prop := props[name].(map[string]interface{})
+		if prop["type"] != "string" || prop["format"] != wantFormat {
+			t.Errorf("%s = %v, want type string with format %s", name, prop, wantFormat)
+		}
+	}
+
+	phone := props["phone"].(map[string]interface{})
+	if phone["type"] != "string" || phone["pattern"] != jsonschema.PhonePattern {
+		t.Errorf("phone = %v, want type string with pattern %s", phone, jsonschema.PhonePattern)
+	}
+	if _, ok := phone["format"]; ok {
+		t.Error("phone should not have a format")
+	}
+	if got := props["work_phone"].(map[string]interface{})["pattern"]; got != `^\+46[0-9]+$` {
+		t.Errorf("work_phone pattern = %v, want the claim's own pattern", got)
 	}
 }
 
-func TestMapTypeToJSONSchema_ArrayItems(t *testing.T) {
-	prop := mapTypeToJSONSchema("array")
-	if prop.Items == nil {
-		t.Fatal("Items should not be nil for array type")
+func TestGenerator_Schema_SvgID(t *testing.T) {
+	g := NewGenerator()
+	cfg := &config.Config{Language: "en-US"}
+
+	cred := &formats.ParsedCredential{
+		Name: "Test",
+		Claims: []formats.ClaimDefinition{
+			{Name: "given_name", Type: "string", SvgId: "givenNameField"},
+			{Name: "family_name", Type: "string"},
+		},
 	}
-	if prop.Items.Type != "string" {
-		t.Errorf("Items.Type = %q, want 'string'", prop.Items.Type)
+
+	output, err := g.Schema(cred, cfg)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	props := parsed["properties"].(map[string]interface{})["credentialSubject"].(map[string]interface{})["properties"].(map[string]interface{})
+
+	if got := props["given_name"].(map[string]interface{})["x-svg-id"]; got != "givenNameField" {
+		t.Errorf("given_name x-svg-id = %v, want givenNameField", got)
+	}
+	if _, ok := props["family_name"].(map[string]interface{})["x-svg-id"]; ok {
+		t.Error("family_name should not have an x-svg-id")
+	}
+}
+
+func TestGenerator_Example(t *testing.T) {
+	g := NewGenerator()
+	cfg := &config.Config{Language: "en-US", BaseURL: "https://registry.example.com"}
+
+	cred := &formats.ParsedCredential{
+		ID:   "identity",
+		Name: "Identity",
+		Claims: []formats.ClaimDefinition{
+			{Name: "given_name", Path: []interface{}{"given_name"}, Type: "string", Mandatory: true, Example: "Alice"},
+			{Name: "birth_date", Path: []interface{}{"birth_date"}, Type: "date", Mandatory: true},
+			{Name: "age", Path: []interface{}{"age"}, Type: "integer", Min: "18", Max: "150"},
+			{Name: "email", Path: []interface{}{"email"}, Type: "email"},
+			{Name: "level", Path: []interface{}{"level"}, Type: "string", Const: "high"},
+			{Name: "address.country", Path: []interface{}{"address", "country"}, Type: "string", Enum: []string{"SE", "DE"}, Mandatory: true},
+			{Name: "nationalities[]", Path: []interface{}{"nationalities", nil}, Type: "string", Examples: []string{"SE", "FI"}},
+		},
+	}
+
+	output, err := g.Example(cred, cfg)
+	if err != nil {
+		t.Fatalf("Example() error = %v", err)
+	}
+
+	var example ExampleCredential
+	if err := json.Unmarshal(output, &example); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if example.CredentialSchema == nil || example.CredentialSchema.ID != "https://registry.example.com/identity.schema.json" {
+		t.Errorf("CredentialSchema = %+v, want the standalone schema reference", example.CredentialSchema)
+	}
+	if got := example.Type; len(got) != 2 || got[1] != "Identity" {
+		t.Errorf("Type = %v", got)
+	}
+	subject := example.CredentialSubject
+	for name, want := range map[string]interface{}{
+		"given_name":    "Alice",
+		"birth_date":    "2000-01-01",
+		"age":           float64(18),
+		"email":         "user@example.com",
+		"level":         "high",
+		"address":       map[string]interface{}{"country": "SE"},
+		"nationalities": []interface{}{"SE"},
+	} {
+		if got := subject[name]; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("credentialSubject.%s = %v, want %v", name, got, want)
+		}
+	}
+
+	schemaData, err := g.Schema(cred, cfg)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	doc, err := validator.UnmarshalJSON(bytes.NewReader(schemaData))
+	if err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	compiler := validator.NewCompiler()
+	compiler.AssertFormat()
+	const url = "https://registry.example.com/identity.schema.json"
+	if err := compiler.AddResource(url, doc); err != nil {
+		t.Fatalf("AddResource() error = %v", err)
+	}
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		t.Fatalf("generated schema does not compile: %v", err)
+	}
+	instance, err := validator.UnmarshalJSON(bytes.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate(instance); err != nil {
+		t.Errorf("example does not validate against the schema: %v", err)
+	}
+
+	if output, err := g.Example(&formats.ParsedCredential{ID: "empty"}, cfg); err != nil || output != nil {
+		t.Errorf("Example() without claims = %s, %v, want nil", output, err)
 	}
 }
 
@@ -1,9 +1,13 @@
 package parser
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
 	"github.com/sirosfoundation/mtcvctm/pkg/formats"
 )
 
@@ -19,6 +23,10 @@ func (p *Parser) ToCredential(parsed *ParsedMarkdown) *formats.ParsedCredential
 		ClaimMappings:   make(map[string]map[string]string),
 		Metadata:        make(map[string]interface{}),
 		InlineImages:    p.config.InlineImages,
+		Warnings:        append([]string(nil), parsed.Warnings...),
+	}
+	if p.integrity != nil {
+		cred.Integrity = p.integrity
 	}
 
 	// Set source path info
@@ -52,6 +60,10 @@ func (p *Parser) ToCredential(parsed *ParsedMarkdown) *formats.ParsedCredential
 			cred.TextColor = strings.Trim(v, "\"")
 		case "logo":
 			cred.LogoPath = strings.Trim(v, "\"")
+		case "logo_alt_text":
+			cred.LogoAltText = strings.Trim(v, "\"")
+		case "background_image":
+			cred.BackgroundImagePath = strings.Trim(v, "\"")
 		case "svg_template":
 			cred.SVGTemplatePath = strings.Trim(v, "\"")
 		case "svg_template_uri":
@@ -61,6 +73,31 @@ func (p *Parser) ToCredential(parsed *ParsedMarkdown) *formats.ParsedCredential
 		}
 	}
 
+	cred.Profiles = parsed.Profiles
+
+	// Per-format overrides and claim renames from the formats and claim_mappings blocks
+	for format, overrides := range parsed.FormatOverrides {
+		cred.FormatOverrides[format] = overrides
+	}
+	for format, mappings := range parsed.ClaimMappings {
+		cred.ClaimMappings[format] = mappings
+	}
+
+	if ref := parsed.CredentialSchema; ref != nil && ref.ID != "" {
+		cred.CredentialSchema = &formats.CredentialSchemaRef{
+			ID:        ref.ID,
+			Type:      ref.Type,
+			Path:      ref.Path,
+			Integrity: ref.Integrity,
+		}
+	}
+
+	// Draft 12 allows a single parent type; additional parents are only recorded
+	cred.Extends = parsed.Extends
+	if len(parsed.Extends) > 1 {
+		cred.Warnings = append(cred.Warnings, fmt.Sprintf("extends declares %d parent types, only %s is emitted as extends", len(parsed.Extends), parsed.Extends[0]))
+	}
+
 	// Handle display localizations
 	for locale, loc := range parsed.DisplayLocalizations {
 		cred.Localizations[locale] = formats.DisplayLocalization{
@@ -69,8 +106,14 @@ func (p *Parser) ToCredential(parsed *ParsedMarkdown) *formats.ParsedCredential
 		}
 	}
 
-	// Convert claims
-	for name, claim := range parsed.Claims {
+	// Convert claims in display order
+	for _, name := range parsed.OrderedClaimNames() {
+		claim := parsed.Claims[name]
+		order, _ := claimOrder(claim.Order)
+		for _, flag := range claim.UnknownFlags {
+			cred.Warnings = append(cred.Warnings, fmt.Sprintf("claim %s has unknown flag [%s]", name, flag))
+		}
+
 		claimDef := formats.ClaimDefinition{
 			Name:           name,
 			DisplayName:    claim.DisplayName,
@@ -79,13 +122,22 @@ func (p *Parser) ToCredential(parsed *ParsedMarkdown) *formats.ParsedCredential
 			Mandatory:      claim.Mandatory,
 			SD:             claim.SD,
 			SvgId:          claim.SvgId,
+			Group:          claim.Group,
+			Const:          claim.Const,
+			Example:        claim.Example,
+			Examples:       claim.Examples,
+			Enum:           claim.Enum,
+			Min:            claim.Min,
+			Max:            claim.Max,
+			Pattern:        claim.Pattern,
+			Order:          order,
+			Hidden:         claim.Hidden,
 			Localizations:  make(map[string]formats.ClaimLocalization),
 			FormatMappings: make(map[string]string),
 		}
 
 		// Build path from name
-		parts := strings.Split(name, ".")
-		claimDef.Path = parts
+		claimDef.Path = formats.ParseClaimPath(name)
 
 		// Convert localizations
 		for locale, loc := range claim.Localizations {
@@ -104,18 +156,105 @@ func (p *Parser) ToCredential(parsed *ParsedMarkdown) *formats.ParsedCredential
 			Path:         img.Path,
 			AltText:      img.AltText,
 			AbsolutePath: img.AbsolutePath,
+			Orientation:  img.Orientation,
+			ColorScheme:  img.ColorScheme,
+			Contrast:     img.Contrast,
+			Role:         img.Role,
 		})
 	}
 
+	// A front matter logo naming one of the images takes its alt text and resolved
+	// path; without a front matter logo the image marked {role=logo} is used
+	for _, img := range cred.Images {
+		if cred.LogoPath == "" && img.Role == formats.ImageRoleLogo || cred.LogoPath != "" && img.Path == cred.LogoPath {
+			cred.LogoPath = img.Path
+			cred.LogoAbsPath = img.AbsolutePath
+			if cred.LogoAltText == "" {
+				cred.LogoAltText = img.AltText
+			}
+			break
+		}
+	}
+
+	// Color scheme variants; formats without color schemes use the light variant
+	// where the flat front matter keys leave a gap
+	for scheme, colors := range parsed.ColorSchemes {
+		if cred.ColorSchemes == nil {
+			cred.ColorSchemes = make(map[string]formats.ColorScheme)
+		}
+		cred.ColorSchemes[scheme] = formats.ColorScheme{
+			BackgroundColor: colors.BackgroundColor,
+			TextColor:       colors.TextColor,
+			LogoPath:        colors.Logo,
+			LogoAltText:     colors.LogoAltText,
+		}
+	}
+	if light, ok := cred.ColorSchemes[formats.ColorSchemeLight]; ok {
+		if cred.BackgroundColor == "" {
+			cred.BackgroundColor = light.BackgroundColor
+		}
+		if cred.TextColor == "" {
+			cred.TextColor = light.TextColor
+		}
+		if cred.LogoPath == "" && light.LogoPath != "" {
+			cred.LogoPath = light.LogoPath
+			cred.LogoAltText = light.LogoAltText
+		}
+	}
+
 	// If we have a logo path but no absolute path, try to resolve it
 	if cred.LogoPath != "" && cred.LogoAbsPath == "" && p.config.InputFile != "" {
 		baseDir := filepath.Dir(p.config.InputFile)
 		cred.LogoAbsPath = filepath.Join(baseDir, cred.LogoPath)
 	}
 
+	if cred.InlineImages {
+		p.warnOversizedImages(cred)
+	}
+
 	return cred
 }
 
+// warnOversizedImages warns about local images over the inline limit. Generators
+// reference them by URL when an asset base URL is set and inline them anyway
+// otherwise.
+func (p *Parser) warnOversizedImages(cred *formats.ParsedCredential) {
+	paths := []string{cred.LogoPath, cred.BackgroundImagePath, cred.SVGTemplatePath}
+	for _, img := range cred.Images {
+		paths = append(paths, img.Path)
+	}
+	schemes := make([]string, 0, len(cred.ColorSchemes))
+	for scheme := range cred.ColorSchemes {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	for _, scheme := range schemes {
+		paths = append(paths, cred.ColorSchemes[scheme].LogoPath)
+	}
+
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if path == "" || seen[path] || formats.IsRemoteURI(path) {
+			continue
+		}
+		seen[path] = true
+
+		absPath := path
+		if !filepath.IsAbs(absPath) {
+			absPath = filepath.Join(cred.SourceDir, path)
+		}
+		info, err := os.Stat(absPath)
+		if err != nil || !p.config.InlineTooLarge(info.Size()) {
+			continue
+		}
+		if p.config.AssetURL(path) != "" {
+			cred.Warnings = append(cred.Warnings, fmt.Sprintf("image %s is %d bytes, over the inline limit; referencing it by URL", path, info.Size()))
+		} else {
+			cred.Warnings = append(cred.Warnings, fmt.Sprintf("image %s is %d bytes, over the inline limit; inlining it anyway as no base URL is set", path, info.Size()))
+		}
+	}
+}
+
 // ParseToCredential parses a markdown file and returns a ParsedCredential
 func (p *Parser) ParseToCredential(inputPath string) (*formats.ParsedCredential, error) {
 	parsed, err := p.Parse(inputPath)
@@ -155,16 +294,60 @@ func (p *Parser) Generate(cred *formats.ParsedCredential, formatNames []string)
 	return results, nil
 }
 
+// FormatResult holds the outcome of generating a single format
+type FormatResult struct {
+	// Output is the generated document (nil on error or skip)
+	Output []byte
+
+	// Err is the generation error, if any
+	Err error
+
+	// Skipped indicates the format is not registered and was not attempted
+	Skipped bool
+}
+
+// GenerateEach generates each requested format independently, recording per-format
+// failures instead of aborting on the first error
+func (p *Parser) GenerateEach(cred *formats.ParsedCredential, formatNames []string) map[string]FormatResult {
+	results := make(map[string]FormatResult, len(formatNames))
+
+	for _, name := range formatNames {
+		gen, ok := formats.Get(name)
+		if !ok {
+			results[name] = FormatResult{Skipped: true}
+			continue
+		}
+
+		output, err := gen.Generate(cred, p.config)
+		results[name] = FormatResult{Output: output, Err: err}
+	}
+
+	return results
+}
+
 // GenerateAll generates output for all registered formats
 func (p *Parser) GenerateAll(cred *formats.ParsedCredential) (map[string][]byte, error) {
 	return p.Generate(cred, formats.List())
 }
 
-// OutputFileName returns the output filename for a given format
-func OutputFileName(baseName, formatName string) string {
+// OutputPath returns the output path of a format relative to the output
+// directory: the file name, inside a per-format subdirectory with the by-format layout
+func OutputPath(baseName, formatName string, cfg *config.Config) string {
+	if cfg != nil && cfg.Layout == config.LayoutByFormat {
+		return filepath.Join(formatName, OutputFileName(baseName, formatName, cfg))
+	}
+	return OutputFileName(baseName, formatName, cfg)
+}
+
+// OutputFileName returns the output filename for a given format. A nil config
+// uses each format's default file extension.
+func OutputFileName(baseName, formatName string, cfg *config.Config) string {
 	gen, ok := formats.Get(formatName)
 	if !ok {
 		return baseName + "." + formatName
 	}
+	if ext, ok := gen.(formats.ExtensionProvider); ok && cfg != nil {
+		return baseName + "." + ext.FileExtensionFor(cfg)
+	}
 	return baseName + "." + gen.FileExtension()
 }
@@ -1,15 +1,22 @@
 package parser
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/sirosfoundation/mtcvctm/pkg/config"
 	"github.com/sirosfoundation/mtcvctm/pkg/formats"
 
 	// Import format packages to trigger their init() registration
+	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/mddl"
 	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/vctmfmt"
+	_ "github.com/sirosfoundation/mtcvctm/pkg/formats/w3c"
 )
 
 func TestParser_ToCredential(t *testing.T) {
@@ -273,6 +280,927 @@ This is a test.
 	}
 }
 
+func TestParser_ParseContentToCredential_IndexedClaims(t *testing.T) {
+	p := NewParser(&config.Config{Language: "en-US"})
+
+	content := []byte("# Test\n\n## Claims\n\n" +
+		"- `addresses[0]` (string): Primary address\n" +
+		"- `addresses[1]` (string): Secondary address\n")
+
+	cred, err := p.ParseContentToCredential(content, "/test/cred.md")
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+
+	want := map[string][]interface{}{
+		"addresses[0]": {"addresses", 0},
+		"addresses[1]": {"addresses", 1},
+	}
+	if len(cred.Claims) != len(want) {
+		t.Fatalf("len(Claims) = %d, want %d", len(cred.Claims), len(want))
+	}
+	for _, claim := range cred.Claims {
+		wantPath, ok := want[claim.Name]
+		if !ok {
+			t.Errorf("unexpected claim %q", claim.Name)
+			continue
+		}
+		if len(claim.Path) != len(wantPath) || claim.Path[0] != wantPath[0] || claim.Path[1] != wantPath[1] {
+			t.Errorf("%s path = %#v, want %#v", claim.Name, claim.Path, wantPath)
+		}
+	}
+}
+
+func TestParser_ParseContentToCredential_ArraySelectorClaims(t *testing.T) {
+	p := NewParser(&config.Config{Language: "en-US"})
+
+	content := []byte("# Test\n\n## Claims\n\n" +
+		"- `nationalities[]` (string): All nationalities\n" +
+		"- `nationalities[0]` (string): Primary nationality\n" +
+		"- `addresses[]` (object): All addresses\n" +
+		"  - `street` (string): Street of each address\n" +
+		"- `degrees[1].subjects[]` (string): Subjects of the second degree\n")
+
+	cred, err := p.ParseContentToCredential(content, "/test/cred.md")
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+
+	want := map[string]string{
+		"nationalities[]":       `["nationalities",null]`,
+		"nationalities[0]":      `["nationalities",0]`,
+		"addresses[]":           `["addresses",null]`,
+		"addresses[].street":    `["addresses",null,"street"]`,
+		"degrees[1].subjects[]": `["degrees",1,"subjects",null]`,
+	}
+	if len(cred.Claims) != len(want) {
+		t.Fatalf("len(Claims) = %d, want %d", len(cred.Claims), len(want))
+	}
+	for _, claim := range cred.Claims {
+		wantPath, ok := want[claim.Name]
+		if !ok {
+			t.Errorf("unexpected claim %q", claim.Name)
+			continue
+		}
+		path, _ := json.Marshal(claim.Path)
+		if string(path) != wantPath {
+			t.Errorf("%s path = %s, want %s", claim.Name, path, wantPath)
+		}
+	}
+
+	// The vctm output serializes null selectors as JSON null and indices as numbers
+	outputs, err := p.Generate(cred, []string{"vctm"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	vctmJSON := compactJSON(t, outputs["vctm"])
+	for _, wantPath := range want {
+		if !strings.Contains(vctmJSON, `"path":`+wantPath) {
+			t.Errorf("vctm output missing path %s", wantPath)
+		}
+	}
+}
+
+// compactJSON returns data with insignificant whitespace removed
+func compactJSON(t *testing.T, data []byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	return buf.String()
+}
+
+func TestParser_ParseContentToCredential_NestedClaims(t *testing.T) {
+	p := NewParser(&config.Config{Language: "en-US"})
+
+	content := []byte("# Test\n\n## Claims\n\n" +
+		"- `address` \"Address\" (object): Postal address\n" +
+		"  - de-DE: \"Adresse\" - Postanschrift\n" +
+		"  - `street` (string): Street name [mandatory]\n" +
+		"  - `geo` (object): Coordinates\n" +
+		"    - `lat` (number): Latitude\n" +
+		"    - `lon` (number): Longitude\n" +
+		"- `given_name` (string): Given name\n")
+
+	cred, err := p.ParseContentToCredential(content, "/test/cred.md")
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+
+	want := map[string]string{
+		"address":         `["address"]`,
+		"address.street":  `["address","street"]`,
+		"address.geo":     `["address","geo"]`,
+		"address.geo.lat": `["address","geo","lat"]`,
+		"address.geo.lon": `["address","geo","lon"]`,
+		"given_name":      `["given_name"]`,
+	}
+	if len(cred.Claims) != len(want) {
+		t.Fatalf("len(Claims) = %d, want %d", len(cred.Claims), len(want))
+	}
+	for _, claim := range cred.Claims {
+		wantPath, ok := want[claim.Name]
+		if !ok {
+			t.Errorf("unexpected claim %q", claim.Name)
+			continue
+		}
+		path, _ := json.Marshal(claim.Path)
+		if string(path) != wantPath {
+			t.Errorf("%s path = %s, want %s", claim.Name, path, wantPath)
+		}
+		if claim.Name == "address" && claim.Localizations["de-DE"].Label != "Adresse" {
+			t.Errorf("address localizations = %v, want de-DE label", claim.Localizations)
+		}
+	}
+
+	outputs, err := p.Generate(cred, []string{"vctm"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var vctmDoc struct {
+		Claims []struct {
+			Path []interface{} `json:"path"`
+		} `json:"claims"`
+	}
+	if err := json.Unmarshal(outputs["vctm"], &vctmDoc); err != nil {
+		t.Fatalf("vctm output is not valid JSON: %v", err)
+	}
+	paths := make(map[string]bool)
+	for _, entry := range vctmDoc.Claims {
+		path, _ := json.Marshal(entry.Path)
+		paths[string(path)] = true
+	}
+	for _, wantPath := range want {
+		if !paths[wantPath] {
+			t.Errorf("vctm claims missing path %s, got %v", wantPath, paths)
+		}
+	}
+}
+
+func TestParser_ParseContentToCredential_GroupedClaimOrder(t *testing.T) {
+	p := NewParser(&config.Config{Language: "en-US"})
+
+	content := []byte("# Test\n\n## Claims\n\n" +
+		"- `given_name` (string): Given name\n\n" +
+		"### `vehicle` \"Vehicle\"\n\n" +
+		"- `registration` (string): Registration number\n" +
+		"- `make` (string): Manufacturer\n" +
+		"- `colour` (string): Colour\n\n" +
+		"### `address` \"Address\"\n\n" +
+		"- `street` (string): Street name\n" +
+		"- `postal_code` (string): Postal code\n" +
+		"- `country` (string): Country\n")
+
+	cred, err := p.ParseContentToCredential(content, "/test/cred.md")
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+
+	var names []string
+	for _, claim := range cred.Claims {
+		names = append(names, claim.Name)
+	}
+	wantNames := []string{
+		"given_name",
+		"vehicle", "vehicle.registration", "vehicle.make", "vehicle.colour",
+		"address", "address.street", "address.postal_code", "address.country",
+	}
+	if strings.Join(names, ",") != strings.Join(wantNames, ",") {
+		t.Fatalf("claim order = %v, want %v", names, wantNames)
+	}
+	for _, claim := range cred.Claims {
+		if (claim.Name == "vehicle" || claim.Name == "address") && claim.Type != "object" {
+			t.Errorf("%s type = %q, want object", claim.Name, claim.Type)
+		}
+	}
+
+	gen, ok := formats.Get("w3c")
+	if !ok {
+		t.Fatal("w3c format not registered")
+	}
+	schema, err := gen.(formats.SchemaProvider).Schema(cred, p.config)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	// Each group of keys must appear in the written order, not alphabetically
+	orders := [][]string{
+		{"given_name", "vehicle", "address"},
+		{"registration", "make", "colour"},
+		{"street", "postal_code", "country"},
+	}
+	for _, order := range orders {
+		last := -1
+		for _, key := range order {
+			idx := bytes.Index(schema, []byte(`"`+key+`":`))
+			if idx < 0 {
+				t.Fatalf("schema missing property %q:\n%s", key, schema)
+			}
+			if idx < last {
+				t.Errorf("property %q emitted out of order %v:\n%s", key, order, schema)
+			}
+			last = idx
+		}
+	}
+}
+
+func TestParser_ParseContentToCredential_FormatOverrides(t *testing.T) {
+	p := NewParser(&config.Config{Language: "en-US"})
+
+	content := []byte(`---
+formats:
+  mddl:
+    order: 3
+    doctype: org.iso.18013.5.1.mDL
+    namespace: org.iso.18013.5.1
+claim_mappings:
+  w3c:
+    place_of_birth: birthPlace
+claim_mapping:
+  mddl:
+    place_of_birth: birth_place
+---
+# Test
+
+## Claims
+
+- ` + "`place_of_birth`" + ` (string): Place of birth
+`)
+
+	cred, err := p.ParseContentToCredential(content, "/test/cred.md")
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+	if cred.FormatOverrides["mddl"]["namespace"] != "org.iso.18013.5.1" {
+		t.Errorf("FormatOverrides = %v, want mddl namespace", cred.FormatOverrides)
+	}
+	if cred.ClaimMappings["mddl"]["place_of_birth"] != "birth_place" {
+		t.Errorf("ClaimMappings = %v, want mddl mapping from claim_mapping", cred.ClaimMappings)
+	}
+
+	outputs, err := p.Generate(cred, []string{"mddl", "w3c"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var mddlDoc struct {
+		Order  *int                                  `json:"order"`
+		Claims map[string]map[string]json.RawMessage `json:"claims"`
+	}
+	if err := json.Unmarshal(outputs["mddl"], &mddlDoc); err != nil {
+		t.Fatalf("mddl output is not valid JSON: %v", err)
+	}
+	if mddlDoc.Order == nil || *mddlDoc.Order != 3 {
+		t.Errorf("mddl order = %v, want 3", mddlDoc.Order)
+	}
+	if _, ok := mddlDoc.Claims["org.iso.18013.5.1"]["birth_place"]; !ok {
+		t.Errorf("mddl claims = %v, want birth_place in org.iso.18013.5.1", mddlDoc.Claims)
+	}
+
+	// Claims are renamed in the standalone JSON Schema referenced by the w3c output
+	w3cGen, _ := formats.Get("w3c")
+	schema, err := w3cGen.(formats.SchemaProvider).Schema(cred, p.config)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	if !strings.Contains(string(schema), `"birthPlace"`) || strings.Contains(string(schema), `"place_of_birth":`) {
+		t.Errorf("w3c schema should rename place_of_birth to birthPlace:\n%s", schema)
+	}
+}
+
+func TestParser_Generate_ClaimOrder(t *testing.T) {
+	content := []byte("---\ndoctype: org.example.identity\n---\n# Identity\n\n## Claims\n\n" +
+		"- `document_number` \"Number\" (string): Document number\n" +
+		"- `family_name` \"Family Name\" (string): Family name [order=2]\n" +
+		"- `given_name` \"Given Name\" (string): Given name [mandatory, order=1]\n" +
+		"- `birth_date` \"Birth Date\" (date): Birth date\n")
+
+	p := NewParser(&config.Config{Language: "en-US"})
+	cred, err := p.ParseContentToCredential(content, "/test/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+	if len(cred.Warnings) != 0 {
+		t.Errorf("Warnings = %v, order should be a known flag", cred.Warnings)
+	}
+
+	outputs, err := p.Generate(cred, []string{"vctm", "mddl"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var vctmDoc struct {
+		Claims []struct {
+			Path []interface{} `json:"path"`
+		} `json:"claims"`
+	}
+	if err := json.Unmarshal(outputs["vctm"], &vctmDoc); err != nil {
+		t.Fatalf("vctm output is not valid JSON: %v", err)
+	}
+	var got []interface{}
+	for _, claim := range vctmDoc.Claims {
+		got = append(got, claim.Path[0])
+	}
+	want := []interface{}{"given_name", "family_name", "document_number", "birth_date"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("vctm claim order = %v, want %v", got, want)
+	}
+
+	var mddlDoc struct {
+		Claims map[string]map[string]struct {
+			Order int `json:"order"`
+		} `json:"claims"`
+	}
+	if err := json.Unmarshal(outputs["mddl"], &mddlDoc); err != nil {
+		t.Fatalf("mddl output is not valid JSON: %v", err)
+	}
+	for _, claims := range mddlDoc.Claims {
+		for name, wantOrder := range map[string]int{"given_name": 1, "family_name": 2, "document_number": 0} {
+			if got := claims[name].Order; got != wantOrder {
+				t.Errorf("mddl %s order = %d, want %d", name, got, wantOrder)
+			}
+		}
+	}
+}
+
+func TestParser_Generate_ClaimGroup(t *testing.T) {
+	content := []byte("# Identity\n\n## Claims\n\n" +
+		"- `given_name` \"Given Name\" (string): Given name [mandatory, group=Personal]\n" +
+		"- `address.street` \"Street\" (string): Street [group=Address]\n" +
+		"- `document_number` \"Number\" (string): Document number\n")
+
+	p := NewParser(&config.Config{Language: "en-US"})
+	cred, err := p.ParseContentToCredential(content, "/test/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+	if len(cred.Warnings) != 0 {
+		t.Errorf("Warnings = %v, group should be a known flag", cred.Warnings)
+	}
+
+	outputs, err := p.Generate(cred, []string{"vctm"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var vctmDoc struct {
+		Claims []map[string]interface{} `json:"claims"`
+	}
+	if err := json.Unmarshal(outputs["vctm"], &vctmDoc); err != nil {
+		t.Fatalf("vctm output is not valid JSON: %v", err)
+	}
+	wantGroups := []interface{}{"Personal", "Address", nil}
+	if len(vctmDoc.Claims) != len(wantGroups) {
+		t.Fatalf("vctm claims = %v, want %d", vctmDoc.Claims, len(wantGroups))
+	}
+	for i, want := range wantGroups {
+		if got := vctmDoc.Claims[i]["x-group"]; got != want {
+			t.Errorf("vctm claim %v x-group = %v, want %v", vctmDoc.Claims[i]["path"], got, want)
+		}
+	}
+
+	w3cGen, _ := formats.Get("w3c")
+	schema, err := w3cGen.(formats.SchemaProvider).Schema(cred, p.config)
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	var schemaDoc struct {
+		Properties map[string]struct {
+			Properties map[string]struct {
+				Group      string `json:"x-group"`
+				Properties map[string]struct {
+					Group string `json:"x-group"`
+				} `json:"properties"`
+			} `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(schema, &schemaDoc); err != nil {
+		t.Fatalf("w3c schema is not valid JSON: %v", err)
+	}
+	subject := schemaDoc.Properties["credentialSubject"].Properties
+	if subject["given_name"].Group != "Personal" {
+		t.Errorf("w3c given_name x-group = %q, want Personal", subject["given_name"].Group)
+	}
+	if subject["address"].Properties["street"].Group != "Address" {
+		t.Errorf("w3c address.street x-group = %q, want Address", subject["address"].Properties["street"].Group)
+	}
+	if subject["document_number"].Group != "" {
+		t.Errorf("w3c document_number x-group = %q, want none", subject["document_number"].Group)
+	}
+}
+
+func TestParser_Generate_SVGTemplateProperties(t *testing.T) {
+	content := []byte("# Identity\n\nAn identity credential.\n\n" +
+		"![Card](card-light.svg){orientation=portrait color_scheme=light}\n\n" +
+		"![Card](card-dark.svg){orientation=portrait color_scheme=dark contrast=high}\n")
+
+	p := NewParser(&config.Config{Language: "en-US", BaseURL: "https://registry.example.com"})
+	parsed, err := p.ParseContent(content, "/test/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+
+	// Typed VCTM path
+	vctmDoc, err := p.ToVCTM(parsed)
+	if err != nil {
+		t.Fatalf("ToVCTM() error = %v", err)
+	}
+	templates := vctmDoc.Display[0].Rendering.SVGTemplates
+	if len(templates) != 2 {
+		t.Fatalf("SVGTemplates = %+v, want 2", templates)
+	}
+	if props := templates[0].Properties; props == nil || props.Orientation != "portrait" || props.ColorScheme != "light" || props.Contrast != "" {
+		t.Errorf("light template properties = %+v", props)
+	}
+	if props := templates[1].Properties; props == nil || props.ColorScheme != "dark" || props.Contrast != "high" {
+		t.Errorf("dark template properties = %+v", props)
+	}
+
+	// vctm generator path
+	cred := p.ToCredential(parsed)
+	outputs, err := p.Generate(cred, []string{"vctm"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Display []struct {
+			Rendering struct {
+				SVGTemplates []struct {
+					URI        string            `json:"uri"`
+					Properties map[string]string `json:"properties"`
+				} `json:"svg_templates"`
+			} `json:"rendering"`
+		} `json:"display"`
+	}
+	if err := json.Unmarshal(outputs["vctm"], &doc); err != nil {
+		t.Fatalf("vctm output is not valid JSON: %v", err)
+	}
+	generated := doc.Display[0].Rendering.SVGTemplates
+	if len(generated) != 2 {
+		t.Fatalf("svg_templates = %+v, want 2", generated)
+	}
+	if !strings.HasSuffix(generated[0].URI, "card-light.svg") || generated[0].Properties["color_scheme"] != "light" || generated[0].Properties["orientation"] != "portrait" {
+		t.Errorf("light template = %+v", generated[0])
+	}
+	if !strings.HasSuffix(generated[1].URI, "card-dark.svg") || generated[1].Properties["color_scheme"] != "dark" || generated[1].Properties["contrast"] != "high" {
+		t.Errorf("dark template = %+v", generated[1])
+	}
+}
+
+func TestParser_Generate_MarkedLogo(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"banner.png", "seal.png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantIntegrity, err := formats.FileIntegrity(filepath.Join(dir, "seal.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("# Identity\n\nAn identity credential.\n\n" +
+		"![Banner](banner.png)\n\n" +
+		"![Issuer seal](seal.png){role=logo}\n")
+
+	p := NewParser(&config.Config{Language: "en-US", BaseURL: "https://registry.example.com", InputFile: filepath.Join(dir, "identity.md")})
+	parsed, err := p.ParseContent(content, filepath.Join(dir, "identity.md"))
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+
+	// Typed VCTM path
+	vctmDoc, err := p.ToVCTM(parsed)
+	if err != nil {
+		t.Fatalf("ToVCTM() error = %v", err)
+	}
+	if logo := vctmDoc.Display[0].Rendering.Simple.Logo; logo == nil || logo.URI != "https://registry.example.com/seal.png" || logo.AltText != "Issuer seal" {
+		t.Errorf("ToVCTM logo = %+v, want the marked seal", logo)
+	}
+
+	// vctm and mddl generator paths
+	cred := p.ToCredential(parsed)
+	outputs, err := p.Generate(cred, []string{"vctm", "mddl"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var vctmOut struct {
+		Display []struct {
+			Rendering struct {
+				Simple struct {
+					Logo map[string]string `json:"logo"`
+				} `json:"simple"`
+			} `json:"rendering"`
+		} `json:"display"`
+	}
+	if err := json.Unmarshal(outputs["vctm"], &vctmOut); err != nil {
+		t.Fatalf("vctm output is not valid JSON: %v", err)
+	}
+	logo := vctmOut.Display[0].Rendering.Simple.Logo
+	if logo["uri"] != "https://registry.example.com/seal.png" || logo["alt_text"] != "Issuer seal" || logo["uri#integrity"] != wantIntegrity {
+		t.Errorf("vctm logo = %v, want the marked seal with integrity %s", logo, wantIntegrity)
+	}
+
+	var mddlOut struct {
+		Display []struct {
+			Logo map[string]string `json:"logo"`
+		} `json:"display"`
+	}
+	if err := json.Unmarshal(outputs["mddl"], &mddlOut); err != nil {
+		t.Fatalf("mddl output is not valid JSON: %v", err)
+	}
+	if len(mddlOut.Display) == 0 || mddlOut.Display[0].Logo["uri"] != "https://registry.example.com/seal.png" || mddlOut.Display[0].Logo["alt_text"] != "Issuer seal" {
+		t.Errorf("mddl display = %+v, want the marked seal as logo", mddlOut.Display)
+	}
+
+	// A front matter logo naming an image takes the image's alt text
+	content = []byte("---\nlogo: banner.png\n---\n\n# Identity\n\n![Banner](banner.png)\n\n![Issuer seal](seal.png){role=logo}\n")
+	cred, err = p.ParseContentToCredential(content, filepath.Join(dir, "identity.md"))
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+	if cred.LogoPath != "banner.png" || cred.LogoAltText != "Banner" || cred.LogoAbsPath != filepath.Join(dir, "banner.png") {
+		t.Errorf("front matter logo = %q %q %q, want banner.png with its alt text", cred.LogoPath, cred.LogoAltText, cred.LogoAbsPath)
+	}
+
+	// A front matter logo naming the second image is the logo on both VCTM paths
+	content = []byte("---\nlogo: seal.png\n---\n\n# Identity\n\n![Banner](banner.png)\n\n![Issuer seal](seal.png)\n")
+	parsed, err = p.ParseContent(content, filepath.Join(dir, "identity.md"))
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+	vctmDoc, err = p.ToVCTM(parsed)
+	if err != nil {
+		t.Fatalf("ToVCTM() error = %v", err)
+	}
+	if logo := vctmDoc.Display[0].Rendering.Simple.Logo; logo == nil || logo.URI != "https://registry.example.com/seal.png" || logo.AltText != "Issuer seal" || logo.URIIntegrity != wantIntegrity {
+		t.Errorf("ToVCTM logo = %+v, want the front matter seal", logo)
+	}
+	outputs, err = p.Generate(p.ToCredential(parsed), []string{"vctm"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if err := json.Unmarshal(outputs["vctm"], &vctmOut); err != nil {
+		t.Fatalf("vctm output is not valid JSON: %v", err)
+	}
+	if logo := vctmOut.Display[0].Rendering.Simple.Logo; logo["uri"] != "https://registry.example.com/seal.png" || logo["alt_text"] != "Issuer seal" {
+		t.Errorf("vctm logo = %v, want the front matter seal", logo)
+	}
+}
+
+func TestParser_Generate_ColorSchemes(t *testing.T) {
+	content := []byte(`---
+colors:
+  light:
+    background_color: "#ffffff"
+    text_color: "#1a365d"
+    logo: logo-light.png
+    logo_alt_text: Issuer logo
+  dark:
+    background_color: "#1a365d"
+    text_color: "#ffffff"
+    logo: https://cdn.example.com/logo-dark.png
+---
+
+# Identity
+
+An identity credential.
+`)
+
+	p := NewParser(&config.Config{Language: "en-US", BaseURL: "https://registry.example.com", InputFile: "/test/identity.md"})
+	parsed, err := p.ParseContent(content, "/test/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+	if len(parsed.Warnings) != 0 {
+		t.Errorf("Warnings = %v, want none", parsed.Warnings)
+	}
+
+	// Typed VCTM path
+	vctmDoc, err := p.ToVCTM(parsed)
+	if err != nil {
+		t.Fatalf("ToVCTM() error = %v", err)
+	}
+	rendering := vctmDoc.Display[0].Rendering
+	if rendering == nil || len(rendering.ColorSchemes) != 2 {
+		t.Fatalf("Rendering = %+v, want light and dark color schemes", rendering)
+	}
+	if light := rendering.ColorSchemes["light"]; light.BackgroundColor != "#ffffff" || light.Logo == nil || light.Logo.URI != "https://registry.example.com/logo-light.png" || light.Logo.AltText != "Issuer logo" {
+		t.Errorf("light variant = %+v", light)
+	}
+	if dark := rendering.ColorSchemes["dark"]; dark.TextColor != "#ffffff" || dark.Logo == nil || dark.Logo.URI != "https://cdn.example.com/logo-dark.png" {
+		t.Errorf("dark variant = %+v", dark)
+	}
+	if simple := rendering.Simple; simple == nil || simple.BackgroundColor != "#ffffff" || simple.Logo == nil || simple.Logo.AltText != "Issuer logo" {
+		t.Errorf("simple = %+v, want the light variant", simple)
+	}
+
+	// vctm and mddl generator paths
+	cred := p.ToCredential(parsed)
+	outputs, err := p.Generate(cred, []string{"vctm", "mddl"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	type variant struct {
+		BackgroundColor string            `json:"background_color"`
+		TextColor       string            `json:"text_color"`
+		Logo            map[string]string `json:"logo"`
+	}
+	var vctmOut struct {
+		Display []struct {
+			Rendering struct {
+				Simple       variant            `json:"simple"`
+				ColorSchemes map[string]variant `json:"x-color_schemes"`
+			} `json:"rendering"`
+		} `json:"display"`
+	}
+	if err := json.Unmarshal(outputs["vctm"], &vctmOut); err != nil {
+		t.Fatalf("vctm output is not valid JSON: %v", err)
+	}
+	generated := vctmOut.Display[0].Rendering
+	if light := generated.ColorSchemes["light"]; light.BackgroundColor != "#ffffff" || light.TextColor != "#1a365d" || light.Logo["uri"] != "https://registry.example.com/logo-light.png" || light.Logo["alt_text"] != "Issuer logo" {
+		t.Errorf("vctm light variant = %+v", light)
+	}
+	if dark := generated.ColorSchemes["dark"]; dark.BackgroundColor != "#1a365d" || dark.Logo["uri"] != "https://cdn.example.com/logo-dark.png" {
+		t.Errorf("vctm dark variant = %+v", dark)
+	}
+	if generated.Simple.BackgroundColor != "#ffffff" || generated.Simple.Logo["uri"] != "https://registry.example.com/logo-light.png" {
+		t.Errorf("vctm simple = %+v, want the light variant", generated.Simple)
+	}
+
+	var mddlOut struct {
+		Display []struct {
+			BackgroundColor string `json:"background_color"`
+		} `json:"display"`
+	}
+	if err := json.Unmarshal(outputs["mddl"], &mddlOut); err != nil {
+		t.Fatalf("mddl output is not valid JSON: %v", err)
+	}
+	if len(mddlOut.Display) == 0 || mddlOut.Display[0].BackgroundColor != "#ffffff" {
+		t.Errorf("mddl display = %+v, want the light background color", mddlOut.Display)
+	}
+}
+
+func TestParser_Generate_OversizedInlineImage(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "identity.md")
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), bytes.Repeat([]byte("x"), 200), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(input, []byte("# Identity\n\n![Logo](logo.png)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		baseURL     string
+		wantURI     string
+		wantWarning string
+	}{
+		{"falls back to URL", "https://registry.example.com", "https://registry.example.com/logo.png", "referencing it by URL"},
+		{"inlined without base URL", "", "data:", "inlining it anyway"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(&config.Config{
+				InputFile:      input,
+				BaseURL:        tt.baseURL,
+				Language:       "en-US",
+				InlineImages:   true,
+				MaxInlineBytes: 100,
+			})
+			parsed, err := p.Parse(input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			cred := p.ToCredential(parsed)
+			if len(cred.Warnings) != 1 || !strings.Contains(cred.Warnings[0], "logo.png is 200 bytes") || !strings.Contains(cred.Warnings[0], tt.wantWarning) {
+				t.Errorf("Warnings = %v, want an oversized logo.png warning", cred.Warnings)
+			}
+
+			outputs, err := p.Generate(cred, []string{"vctm"})
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			var doc struct {
+				Display []struct {
+					Rendering struct {
+						Simple struct {
+							Logo map[string]string `json:"logo"`
+						} `json:"simple"`
+					} `json:"rendering"`
+				} `json:"display"`
+			}
+			if err := json.Unmarshal(outputs["vctm"], &doc); err != nil {
+				t.Fatalf("vctm output is not valid JSON: %v", err)
+			}
+			if uri := doc.Display[0].Rendering.Simple.Logo["uri"]; !strings.HasPrefix(uri, tt.wantURI) {
+				t.Errorf("vctm logo uri = %.40q, want prefix %q", uri, tt.wantURI)
+			}
+
+			vctmDoc, err := p.ToVCTM(parsed)
+			if err != nil {
+				t.Fatalf("ToVCTM() error = %v", err)
+			}
+			if uri := vctmDoc.Display[0].Rendering.Simple.Logo.URI; !strings.HasPrefix(uri, tt.wantURI) {
+				t.Errorf("ToVCTM logo uri = %.40q, want prefix %q", uri, tt.wantURI)
+			}
+		})
+	}
+}
+
+func TestParser_Generate_BackgroundImageIntegrity(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "background.png"), []byte("background image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	inputFile := filepath.Join(dir, "identity.md")
+	content := []byte("---\nbackground_image: background.png\n---\n\n# Identity\n\nAn identity credential.\n")
+	wantIntegrity, err := formats.FileIntegrity(filepath.Join(dir, "background.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewParser(&config.Config{Language: "en-US", BaseURL: "https://registry.example.com", InputFile: inputFile})
+	parsed, err := p.ParseContent(content, inputFile)
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+
+	outputs, err := p.Generate(p.ToCredential(parsed), []string{"vctm"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Display []struct {
+			Rendering struct {
+				Simple struct {
+					BackgroundImage map[string]string `json:"background_image"`
+				} `json:"simple"`
+			} `json:"rendering"`
+		} `json:"display"`
+	}
+	if err := json.Unmarshal(outputs["vctm"], &doc); err != nil {
+		t.Fatalf("vctm output is not valid JSON: %v", err)
+	}
+	background := doc.Display[0].Rendering.Simple.BackgroundImage
+	if background["uri"] != "https://registry.example.com/background.png" || background["uri#integrity"] != wantIntegrity {
+		t.Errorf("vctm background_image = %v, want URL with integrity %s", background, wantIntegrity)
+	}
+
+	vctmDoc, err := p.ToVCTM(parsed)
+	if err != nil {
+		t.Fatalf("ToVCTM() error = %v", err)
+	}
+	typed := vctmDoc.Display[0].Rendering.Simple.BackgroundImage
+	if typed == nil || typed.URI != "https://registry.example.com/background.png" || typed.URIIntegrity != wantIntegrity {
+		t.Errorf("ToVCTM background image = %+v, want URL with integrity %s", typed, wantIntegrity)
+	}
+}
+
+func TestParser_ParseContentToCredential_MultipleExtends(t *testing.T) {
+	content := []byte(`---
+extends:
+  - https://example.com/base
+  - https://example.com/other
+---
+
+# Test Credential
+`)
+
+	p := NewParser(&config.Config{Language: "en-US"})
+	cred, err := p.ParseContentToCredential(content, "/test/cred.md")
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+	if len(cred.Extends) != 2 {
+		t.Fatalf("Extends = %v, want 2 parents", cred.Extends)
+	}
+	if len(cred.Warnings) != 1 || !strings.Contains(cred.Warnings[0], "https://example.com/base") {
+		t.Errorf("Warnings = %v, want one warning naming the emitted parent", cred.Warnings)
+	}
+
+	outputs, err := p.Generate(cred, []string{"vctm"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var vctm map[string]interface{}
+	if err := json.Unmarshal(outputs["vctm"], &vctm); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if vctm["extends"] != "https://example.com/base" {
+		t.Errorf("extends = %v, want first parent", vctm["extends"])
+	}
+	additional, ok := vctm["x-additional_extends"].([]interface{})
+	if !ok || len(additional) != 1 || additional[0] != "https://example.com/other" {
+		t.Errorf("x-additional_extends = %v", vctm["x-additional_extends"])
+	}
+
+	// Strict mode rejects multiple parents
+	strict := NewParser(&config.Config{Language: "en-US", Strict: true})
+	cred, err = strict.ParseContentToCredential(content, "/test/cred.md")
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+	if _, err := strict.Generate(cred, []string{"vctm"}); err == nil {
+		t.Error("expected error for multiple extends in strict mode")
+	}
+}
+
+func TestParser_ParseContentToCredential_UnknownClaimFlag(t *testing.T) {
+	content := []byte("# Test\n\n## Claims\n\n" +
+		"- `given_name` (string): Given name [mandatroy]\n" +
+		"- `family_name` (string): Family name [mandatory, sd=always]\n")
+
+	p := NewParser(&config.Config{Language: "en-US"})
+	cred, err := p.ParseContentToCredential(content, "/test/cred.md")
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+	if len(cred.Warnings) != 1 || cred.Warnings[0] != "claim given_name has unknown flag [mandatroy]" {
+		t.Errorf("Warnings = %v, want one warning for [mandatroy]", cred.Warnings)
+	}
+	for _, claim := range cred.Claims {
+		if claim.Name == "given_name" && claim.Mandatory {
+			t.Error("given_name should not be mandatory")
+		}
+	}
+
+	// Strict mode rejects unknown flags
+	strict := NewParser(&config.Config{Language: "en-US", Strict: true})
+	if _, err := strict.ParseContentToCredential(content, "/test/cred.md"); err == nil || !strings.Contains(err.Error(), "mandatroy") {
+		t.Errorf("ParseContentToCredential() error = %v, want unknown flag error in strict mode", err)
+	}
+}
+
+func TestParser_Generate_LocaleFallbackOrder(t *testing.T) {
+	content := []byte(`---
+doctype: org.example.license
+display:
+  de-DE:
+    name: Führerschein
+  sv:
+    name: Körkort
+---
+
+# Fahrerlaubnis
+
+Ein Führerschein.
+
+## Claims
+
+- ` + "`klasse`" + ` "Klasse" (string): Fahrzeugklasse
+`)
+
+	p := NewParser(&config.Config{Language: "en-US", LocaleFallbackOrder: []string{"fr-FR", "de-DE", "en-US"}})
+	cred, err := p.ParseContentToCredential(content, "/test/license.md")
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+	outputs, err := p.Generate(cred, []string{"vctm", "mddl"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var vctmDoc struct {
+		Display []struct {
+			Locale string `json:"locale"`
+			Name   string `json:"name"`
+		} `json:"display"`
+		Claims []struct {
+			Display []struct {
+				Locale string `json:"locale"`
+			} `json:"display"`
+		} `json:"claims"`
+	}
+	if err := json.Unmarshal(outputs["vctm"], &vctmDoc); err != nil {
+		t.Fatalf("vctm output is not valid JSON: %v", err)
+	}
+	if len(vctmDoc.Display) == 0 || vctmDoc.Display[0].Locale != "de-DE" || vctmDoc.Display[0].Name != "Fahrerlaubnis" {
+		t.Errorf("vctm display = %+v, want body-derived de-DE base display", vctmDoc.Display)
+	}
+	if len(vctmDoc.Claims) != 1 || vctmDoc.Claims[0].Display[0].Locale != "de-DE" {
+		t.Errorf("vctm claim display = %+v, want de-DE", vctmDoc.Claims)
+	}
+
+	var mddlDoc struct {
+		Display []struct {
+			Locale string `json:"locale"`
+			Name   string `json:"name"`
+		} `json:"display"`
+	}
+	if err := json.Unmarshal(outputs["mddl"], &mddlDoc); err != nil {
+		t.Fatalf("mddl output is not valid JSON: %v", err)
+	}
+	locales := make(map[string]string)
+	for _, d := range mddlDoc.Display {
+		locales[d.Locale] = d.Name
+	}
+	if len(mddlDoc.Display) != 2 || mddlDoc.Display[0].Locale != "de-DE" || locales["de-DE"] != "Fahrerlaubnis" || locales["sv"] != "Körkort" {
+		t.Errorf("mddl display = %+v, want de-DE base display plus sv", mddlDoc.Display)
+	}
+}
+
 func TestParser_ParseContentToCredential_InvalidMarkdown(t *testing.T) {
 	cfg := &config.Config{}
 	p := NewParser(cfg)
@@ -377,6 +1305,81 @@ func TestParser_Generate(t *testing.T) {
 	}
 }
 
+func TestParser_Generate_HiddenClaim(t *testing.T) {
+	content := []byte("---\ndoctype: org.example.identity\n---\n# Identity\n\n## Claims\n\n" +
+		"- `given_name` \"Given Name\" (string): Given name [mandatory, svg_id=given]\n" +
+		"- `internal_id` \"Internal ID\" (string): Issuer reference [hidden, svg_id=internal]\n" +
+		"  - de-DE: \"Interne ID\"\n")
+
+	p := NewParser(&config.Config{Language: "en-US"})
+	cred, err := p.ParseContentToCredential(content, "/test/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContentToCredential() error = %v", err)
+	}
+
+	outputs, err := p.Generate(cred, []string{"vctm", "mddl", "w3c"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var vctmDoc struct {
+		Claims []map[string]interface{} `json:"claims"`
+	}
+	if err := json.Unmarshal(outputs["vctm"], &vctmDoc); err != nil {
+		t.Fatalf("vctm output is not valid JSON: %v", err)
+	}
+	if len(vctmDoc.Claims) != 2 {
+		t.Fatalf("vctm claims = %v, want 2", vctmDoc.Claims)
+	}
+	for _, claim := range vctmDoc.Claims {
+		_, hasDisplay := claim["display"]
+		hidden := fmt.Sprint(claim["path"]) == "[internal_id]"
+		if hasDisplay == hidden {
+			t.Errorf("vctm claim %v: display present = %v, want %v", claim["path"], hasDisplay, !hidden)
+		}
+		if _, hasSvgID := claim["svg_id"]; hasSvgID == hidden {
+			t.Errorf("vctm claim %v: svg_id present = %v, want %v", claim["path"], hasSvgID, !hidden)
+		}
+	}
+
+	var mddlDoc struct {
+		Claims map[string]map[string]map[string]interface{} `json:"claims"`
+	}
+	if err := json.Unmarshal(outputs["mddl"], &mddlDoc); err != nil {
+		t.Fatalf("mddl output is not valid JSON: %v", err)
+	}
+	for _, claims := range mddlDoc.Claims {
+		internal, ok := claims["internal_id"]
+		if !ok {
+			t.Fatal("hidden internal_id should still be an mddl claim")
+		}
+		if display, ok := internal["display"]; ok {
+			t.Errorf("hidden internal_id has mddl display %v", display)
+		}
+		if svgID, ok := internal["svg_id"]; ok {
+			t.Errorf("hidden internal_id has mddl svg_id %v", svgID)
+		}
+		if _, ok := claims["given_name"]["svg_id"]; !ok {
+			t.Error("given_name should keep its mddl svg_id")
+		}
+	}
+
+	parsed, err := p.ParseContent(content, "/test/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+	v, err := p.ToVCTM(parsed)
+	if err != nil {
+		t.Fatalf("ToVCTM() error = %v", err)
+	}
+	for _, claim := range v.Claims {
+		hidden := fmt.Sprint(claim.Path) == "[internal_id]"
+		if (claim.SvgId != "") == hidden {
+			t.Errorf("ToVCTM claim %v: svg_id = %q", claim.Path, claim.SvgId)
+		}
+	}
+}
+
 func TestParser_Generate_UnknownFormat(t *testing.T) {
 	cfg := &config.Config{}
 	p := NewParser(cfg)
@@ -406,6 +1409,32 @@ func TestParser_Generate_UnknownFormat(t *testing.T) {
 	}
 }
 
+func TestParser_GenerateEach(t *testing.T) {
+	cfg := &config.Config{Language: "en-US"}
+	p := NewParser(cfg)
+
+	// No name makes the vctm generator fail
+	cred := &formats.ParsedCredential{ID: "test"}
+
+	results := p.GenerateEach(cred, []string{"vctm", "unknown-format"})
+
+	if results["vctm"].Err == nil {
+		t.Error("vctm should fail without a name")
+	}
+	if results["vctm"].Output != nil {
+		t.Error("failed format should have no output")
+	}
+	if !results["unknown-format"].Skipped {
+		t.Error("unknown format should be marked skipped")
+	}
+
+	cred.Name = "Test"
+	results = p.GenerateEach(cred, []string{"vctm"})
+	if results["vctm"].Err != nil || len(results["vctm"].Output) == 0 {
+		t.Errorf("vctm should succeed, got err = %v", results["vctm"].Err)
+	}
+}
+
 func TestParser_GenerateAll(t *testing.T) {
 	cfg := &config.Config{
 		Language: "en-US",
@@ -445,19 +1474,39 @@ func TestParser_GenerateAll(t *testing.T) {
 	}
 }
 
+func TestOutputPath(t *testing.T) {
+	tests := []struct {
+		layout string
+		want   string
+	}{
+		{"", "credential.vctm.json"},
+		{config.LayoutFlat, "credential.vctm.json"},
+		{config.LayoutByFormat, filepath.Join("vctm", "credential.vctm.json")},
+	}
+
+	for _, tt := range tests {
+		if got := OutputPath("credential", "vctm", &config.Config{Layout: tt.layout}); got != tt.want {
+			t.Errorf("OutputPath(%q) = %q, want %q", tt.layout, got, tt.want)
+		}
+	}
+}
+
 func TestOutputFileName(t *testing.T) {
 	tests := []struct {
 		baseName   string
 		formatName string
+		cfg        *config.Config
 		want       string
 	}{
-		{"credential", "vctm", "credential.vctm.json"},
-		{"my-cred", "unknown", "my-cred.unknown"},
+		{"credential", "vctm", nil, "credential.vctm.json"},
+		{"my-cred", "unknown", nil, "my-cred.unknown"},
+		{"credential", "mddl", nil, "credential.mdoc.json"},
+		{"credential", "mddl", &config.Config{MDDLEncoding: config.MDDLEncodingCBOR}, "credential.mdoc.cbor"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.baseName+"_"+tt.formatName, func(t *testing.T) {
-			got := OutputFileName(tt.baseName, tt.formatName)
+			got := OutputFileName(tt.baseName, tt.formatName, tt.cfg)
 			// For known formats, check exact match; for unknown, check suffix pattern
 			if tt.formatName == "vctm" {
 				// vctm format uses "vctm.json" extension
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/vctm"
+)
+
+// extendsTimeout bounds fetching a parent type metadata document
+const extendsTimeout = 30 * time.Second
+
+// ResolveExtendsIntegrity fetches the type metadata document at uri, checks that
+// it parses as VCTM and returns the SRI integrity hash (sha256) of the exact
+// bytes served
+func ResolveExtendsIntegrity(client *http.Client, uri string) (string, error) {
+	data, _, err := fetchParent(client, uri)
+	if err != nil {
+		return "", err
+	}
+
+	return CalculateIntegrityBytes(data), nil
+}
+
+// fetchParent downloads the parent type metadata at uri and returns the exact
+// bytes served together with the parsed document
+func fetchParent(client *http.Client, uri string) ([]byte, *vctm.VCTM, error) {
+	if !isHTTPURI(uri) {
+		return nil, nil, fmt.Errorf("parser: cannot resolve extends %s: not an http(s) URI", uri)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: extendsTimeout}
+	}
+
+	resp, err := client.Get(uri)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parser: failed to fetch extends %s: %w", uri, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("parser: failed to fetch extends %s: HTTP %d", uri, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parser: failed to read extends %s: %w", uri, err)
+	}
+	parent, err := vctm.FromJSON(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parser: extends %s is not valid type metadata: %w", uri, err)
+	}
+	return data, parent, nil
+}
+
+// isHTTPURI reports whether uri is an absolute http(s) URL
+func isHTTPURI(uri string) bool {
+	return strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://")
+}
+
+// extendsURI returns the first parent type declared in front matter, if any
+func extendsURI(parsed *ParsedMarkdown) string {
+	if len(parsed.Extends) > 0 {
+		return parsed.Extends[0]
+	}
+	return strings.TrimSpace(parsed.Metadata["extends"])
+}
+
+// resolveExtends fills in extends#integrity for a parent type declared without
+// one by fetching the parent document
+func (p *Parser) resolveExtends(parsed *ParsedMarkdown) error {
+	if _, ok := parsed.Metadata["extends#integrity"]; ok {
+		return nil
+	}
+
+	uri := extendsURI(parsed)
+	if uri == "" {
+		return nil
+	}
+
+	integrity, err := ResolveExtendsIntegrity(nil, uri)
+	if err != nil {
+		return err
+	}
+	parsed.Metadata["extends#integrity"] = integrity
+	return nil
+}
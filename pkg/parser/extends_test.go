@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+)
+
+const testParentVCTM = `{"vct": "https://example.com/credentials/base", "name": "Base Credential"}`
+
+func TestParseContent_ResolveExtends(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/base" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testParentVCTM))
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256([]byte(testParentVCTM))
+	want := "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+
+	cfg := config.DefaultConfig()
+	cfg.ResolveExtends = true
+	p := NewParser(cfg)
+
+	content := "---\nextends: " + server.URL + "/base\n---\n\n# Derived Credential\n"
+	parsed, err := p.ParseContent([]byte(content), "/test/derived.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+	if got := parsed.Metadata["extends#integrity"]; got != want {
+		t.Errorf("extends#integrity = %q, want %q", got, want)
+	}
+
+	// An explicit integrity is kept and the parent is not fetched
+	content = "---\nextends: " + server.URL + "/missing\nextends#integrity: sha256-explicit\n---\n\n# Derived Credential\n"
+	parsed, err = p.ParseContent([]byte(content), "/test/derived.md")
+	if err != nil {
+		t.Fatalf("ParseContent() with explicit integrity error = %v", err)
+	}
+	if got := parsed.Metadata["extends#integrity"]; got != "sha256-explicit" {
+		t.Errorf("extends#integrity = %q, want explicit value", got)
+	}
+
+	// Without --resolve-extends nothing is fetched
+	parsed, err = NewParser(config.DefaultConfig()).ParseContent([]byte("---\nextends: "+server.URL+"/missing\n---\n\n# Derived\n"), "/test/derived.md")
+	if err != nil {
+		t.Fatalf("ParseContent() without resolve error = %v", err)
+	}
+	if _, ok := parsed.Metadata["extends#integrity"]; ok {
+		t.Error("extends#integrity should not be set without ResolveExtends")
+	}
+}
+
+func TestResolveExtendsIntegrity_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/invalid" {
+			w.Write([]byte("not json"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	for _, uri := range []string{server.URL + "/missing", server.URL + "/invalid", "base.vctm.json"} {
+		if _, err := ResolveExtendsIntegrity(server.Client(), uri); err == nil {
+			t.Errorf("ResolveExtendsIntegrity(%s) should fail", uri)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
+	"github.com/sirosfoundation/mtcvctm/pkg/vctm"
+)
+
+// inheritClaims merges the claims of the extends parent into parsed. Inherited
+// claims come first in parent order; claims the child defines itself override
+// inherited claims of the same name. visited holds the parents already being
+// resolved and detects extends cycles.
+func (p *Parser) inheritClaims(parsed *ParsedMarkdown, basePath string, visited map[string]bool) error {
+	uri := extendsURI(parsed)
+	if uri == "" {
+		return nil
+	}
+
+	claims, order, err := p.parentClaims(uri, filepath.Dir(basePath), visited)
+	if err != nil {
+		return err
+	}
+
+	childOrder := parsed.OrderedClaimNames()
+	parsed.ClaimOrder = nil
+	for _, name := range order {
+		if _, ok := parsed.Claims[name]; !ok {
+			parsed.Claims[name] = claims[name]
+		}
+		parsed.addClaimName(name)
+	}
+	for _, name := range childOrder {
+		parsed.addClaimName(name)
+	}
+	return nil
+}
+
+// parentClaims loads the claims of a parent type. An http(s) URI whose last
+// path segment names a sibling markdown file (as batch derives type identifiers
+// from file names) uses that file, otherwise it is fetched as VCTM. A local path
+// relative to the child's directory may be a markdown credential, whose own
+// parents are resolved in turn, or a VCTM JSON file.
+func (p *Parser) parentClaims(uri, baseDir string, visited map[string]bool) (map[string]ClaimDef, []string, error) {
+	key := uri
+	if isHTTPURI(uri) {
+		if u, err := url.Parse(uri); err == nil {
+			sibling := filepath.Join(baseDir, path.Base(u.Path)+".md")
+			if _, err := os.Stat(sibling); err == nil {
+				key = sibling
+			}
+		}
+	} else {
+		key = strings.TrimPrefix(uri, "file://")
+		if !filepath.IsAbs(key) {
+			key = filepath.Join(baseDir, key)
+		}
+	}
+	if visited[key] {
+		return nil, nil, fmt.Errorf("parser: extends cycle through %s", uri)
+	}
+	visited[key] = true
+
+	if isHTTPURI(key) {
+		_, parent, err := fetchParent(nil, uri)
+		if err != nil {
+			return nil, nil, err
+		}
+		claims, order := p.vctmClaims(parent)
+		return claims, order, nil
+	}
+
+	data, err := os.ReadFile(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parser: failed to read extends %s: %w", uri, err)
+	}
+
+	if ext := strings.ToLower(filepath.Ext(key)); ext == ".md" || ext == ".markdown" {
+		parent, err := p.parseContent(data, key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parser: failed to parse extends %s: %w", uri, err)
+		}
+		if err := p.inheritClaims(parent, key, visited); err != nil {
+			return nil, nil, err
+		}
+		return parent.Claims, parent.OrderedClaimNames(), nil
+	}
+
+	parent, err := vctm.FromJSON(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parser: extends %s is not valid type metadata: %w", uri, err)
+	}
+	claims, order := p.vctmClaims(parent)
+	return claims, order, nil
+}
+
+// vctmClaims converts the claim metadata of a VCTM document into claim
+// definitions. The display in the configured language becomes the label and
+// description, other locales become localizations.
+func (p *Parser) vctmClaims(v *vctm.VCTM) (map[string]ClaimDef, []string) {
+	claims := make(map[string]ClaimDef, len(v.Claims))
+	order := make([]string, 0, len(v.Claims))
+
+	for _, entry := range v.Claims {
+		claim := ClaimDef{
+			Name:          formats.ClaimPathName(entry.Path),
+			Type:          "string",
+			Description:   entry.Description,
+			Mandatory:     entry.Mandatory,
+			SD:            entry.SD,
+			SvgId:         entry.SvgId,
+			Group:         entry.Group,
+			Localizations: make(map[string]ClaimLocalization),
+		}
+		for _, display := range entry.Display {
+			if strings.EqualFold(display.Locale, p.config.Language) {
+				claim.DisplayName = display.Label
+				if display.Description != "" {
+					claim.Description = display.Description
+				}
+				continue
+			}
+			claim.Localizations[display.Locale] = ClaimLocalization{
+				Label:       display.Label,
+				Description: display.Description,
+			}
+		}
+
+		if _, ok := claims[claim.Name]; !ok {
+			order = append(order, claim.Name)
+		}
+		claims[claim.Name] = claim
+	}
+
+	return claims, order
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+)
+
+func writeInheritTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParse_InheritClaims(t *testing.T) {
+	dir := t.TempDir()
+	writeInheritTestFile(t, dir, "base.md", "# Base\n\n## Claims\n\n"+
+		"- `given_name` \"Given Name\" (string): Given name [mandatory]\n"+
+		"- `family_name` \"Family Name\" (string): Family name\n")
+	child := writeInheritTestFile(t, dir, "employee.md", "---\nextends: https://registry.example.com/base\n---\n\n# Employee\n\n## Claims\n\n"+
+		"- `employee_id` \"Employee ID\" (string): Employee number [mandatory]\n")
+
+	cfg := config.DefaultConfig()
+	cfg.InheritClaims = true
+	parsed, err := NewParser(cfg).Parse(child)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []string{"given_name", "family_name", "employee_id"}
+	if got := parsed.OrderedClaimNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("claims = %v, want %v", got, want)
+	}
+	if claim := parsed.Claims["given_name"]; claim.DisplayName != "Given Name" || !claim.Mandatory {
+		t.Errorf("inherited given_name = %+v", claim)
+	}
+
+	// Without the option only the child's own claims are used
+	parsed, err = NewParser(config.DefaultConfig()).Parse(child)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(parsed.Claims) != 1 {
+		t.Errorf("claims = %v, want only employee_id", parsed.OrderedClaimNames())
+	}
+}
+
+func TestParse_InheritClaims_OverrideFromVCTM(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+  "vct": "https://example.com/base",
+  "claims": [
+    {"path": ["given_name"], "display": [{"locale": "en-US", "label": "Given Name"}, {"locale": "de-DE", "label": "Vorname"}]},
+    {"path": ["addresses", 0, "street"], "sd": "always"}
+  ]
+}`))
+	}))
+	defer server.Close()
+
+	content := "---\nextends: " + server.URL + "/base\n---\n\n# Child\n\n## Claims\n\n" +
+		"- `given_name` \"First Name\" (string): Overridden label\n"
+	cfg := config.DefaultConfig()
+	cfg.InheritClaims = true
+	parsed, err := NewParser(cfg).ParseContent([]byte(content), filepath.Join(t.TempDir(), "child.md"))
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+
+	want := []string{"given_name", "addresses[0].street"}
+	if got := parsed.OrderedClaimNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("claims = %v, want %v", got, want)
+	}
+	if label := parsed.Claims["given_name"].DisplayName; label != "First Name" {
+		t.Errorf("given_name label = %q, child definition should override the parent", label)
+	}
+	if sd := parsed.Claims["addresses[0].street"].SD; sd != "always" {
+		t.Errorf("inherited sd = %q, want always", sd)
+	}
+}
+
+func TestParse_InheritClaims_Cycle(t *testing.T) {
+	dir := t.TempDir()
+	writeInheritTestFile(t, dir, "a.md", "---\nextends: b.md\n---\n\n# A\n")
+	writeInheritTestFile(t, dir, "b.md", "---\nextends: a.md\n---\n\n# B\n")
+
+	cfg := config.DefaultConfig()
+	cfg.InheritClaims = true
+	if _, err := NewParser(cfg).Parse(filepath.Join(dir, "a.md")); err == nil {
+		t.Error("Parse() should fail on an extends cycle")
+	}
+}
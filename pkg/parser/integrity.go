@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+)
+
+// IntegrityCache caches SRI integrity hashes of local files so that an image
+// referenced by several credentials in a batch run is hashed only once. Entries
+// are keyed by absolute path and invalidated when the file's size or
+// modification time changes. With EnableRemote, http(s) URLs are downloaded and
+// hashed as well, once per URL.
+type IntegrityCache struct {
+	mu      sync.Mutex
+	entries map[string]integrityEntry
+
+	// calculate hashes a file on a cache miss (CalculateIntegrity by default)
+	calculate func(path string) (string, error)
+
+	// remote downloads http(s) URLs, when enabled
+	remote *remoteImages
+}
+
+// remoteImages downloads remote images and remembers the outcome per URL
+type remoteImages struct {
+	client   *http.Client
+	maxBytes int64
+	results  map[string]remoteResult
+}
+
+// remoteResult is the integrity hash of a remote image, or why it has none
+type remoteResult struct {
+	integrity string
+	err       error
+}
+
+// integrityEntry is a cached hash together with the file state it was computed from
+type integrityEntry struct {
+	size      int64
+	modTime   time.Time
+	integrity string
+}
+
+// NewIntegrityCache creates an empty integrity cache
+func NewIntegrityCache() *IntegrityCache {
+	return &IntegrityCache{
+		entries:   make(map[string]integrityEntry),
+		calculate: CalculateIntegrity,
+	}
+}
+
+// EnableRemote makes Get download and hash http(s) URLs, giving up after timeout
+// or when an image exceeds maxBytes. Zero values select
+// config.DefaultRemoteImageTimeout and config.DefaultRemoteImageMaxBytes.
+func (c *IntegrityCache) EnableRemote(timeout time.Duration, maxBytes int64) {
+	if timeout <= 0 {
+		timeout = config.DefaultRemoteImageTimeout
+	}
+	if maxBytes <= 0 {
+		maxBytes = config.DefaultRemoteImageMaxBytes
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remote = &remoteImages{
+		client:   &http.Client{Timeout: timeout},
+		maxBytes: maxBytes,
+		results:  make(map[string]remoteResult),
+	}
+}
+
+// Get returns the SRI integrity hash of the file at path, hashing it only if it
+// is not cached or has changed since it was hashed. Remote http(s) URLs are
+// downloaded when EnableRemote was called and are an error otherwise.
+func (c *IntegrityCache) Get(path string) (string, error) {
+	if isHTTPURI(path) {
+		return c.getRemote(path)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("parser: failed to resolve %s: %w", path, err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[absPath]; ok && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+		return entry.integrity, nil
+	}
+
+	integrity, err := c.calculate(absPath)
+	if err != nil {
+		return "", err
+	}
+	c.entries[absPath] = integrityEntry{size: info.Size(), modTime: info.ModTime(), integrity: integrity}
+	return integrity, nil
+}
+
+// getRemote returns the SRI integrity hash of the image at uri, downloading it on
+// first use
+func (c *IntegrityCache) getRemote(uri string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.remote == nil {
+		return "", fmt.Errorf("parser: remote image %s is not resolved", uri)
+	}
+	if result, ok := c.remote.results[uri]; ok {
+		return result.integrity, result.err
+	}
+
+	integrity, err := fetchImageIntegrity(c.remote.client, uri, c.remote.maxBytes)
+	c.remote.results[uri] = remoteResult{integrity: integrity, err: err}
+	return integrity, err
+}
+
+// fetchImageIntegrity downloads the image at uri and returns the SRI integrity
+// hash (sha256) of the bytes served, failing for images larger than maxBytes
+func fetchImageIntegrity(client *http.Client, uri string, maxBytes int64) (string, error) {
+	resp, err := client.Get(uri)
+	if err != nil {
+		return "", fmt.Errorf("parser: failed to fetch image %s: %w", uri, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("parser: failed to fetch image %s: HTTP %d", uri, resp.StatusCode)
+	}
+	if resp.ContentLength > maxBytes {
+		return "", fmt.Errorf("parser: image %s is larger than %d bytes", uri, maxBytes)
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("parser: failed to read image %s: %w", uri, err)
+	}
+	if n > maxBytes {
+		return "", fmt.Errorf("parser: image %s is larger than %d bytes", uri, maxBytes)
+	}
+	return "sha256-" + base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirosfoundation/mtcvctm/pkg/config"
+)
+
+// countingIntegrityCache returns a cache that counts how often files are hashed
+func countingIntegrityCache(reads map[string]int) *IntegrityCache {
+	cache := NewIntegrityCache()
+	cache.calculate = func(path string) (string, error) {
+		reads[path]++
+		return CalculateIntegrity(path)
+	}
+	return cache
+}
+
+func TestIntegrityCache_Get(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logo.png")
+	if err := os.WriteFile(path, []byte("logo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reads := make(map[string]int)
+	cache := countingIntegrityCache(reads)
+
+	first, err := cache.Get(path)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	want, _ := CalculateIntegrity(path)
+	if first != want {
+		t.Errorf("Get() = %q, want %q", first, want)
+	}
+	if second, _ := cache.Get(filepath.Join(dir, ".", "logo.png")); second != first || reads[path] != 1 {
+		t.Errorf("second Get() = %q after %d reads, want cached %q", second, reads[path], first)
+	}
+
+	// A changed file is hashed again
+	if err := os.WriteFile(path, []byte("new logo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := cache.Get(path)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if changed == first || reads[path] != 2 {
+		t.Errorf("Get() after change = %q with %d reads, want a new hash", changed, reads[path])
+	}
+
+	if _, err := cache.Get(filepath.Join(dir, "missing.png")); err == nil {
+		t.Error("Get() of missing file should fail")
+	}
+}
+
+func TestIntegrityCache_SharedAcrossCredentials(t *testing.T) {
+	dir := t.TempDir()
+	logo := filepath.Join(dir, "logo.png")
+	if err := os.WriteFile(logo, []byte("shared logo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reads := make(map[string]int)
+	cache := countingIntegrityCache(reads)
+
+	for _, name := range []string{"identity.md", "diploma.md"} {
+		input := filepath.Join(dir, name)
+		if err := os.WriteFile(input, []byte("# Credential\n\n![Logo](logo.png)\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		p := NewParser(&config.Config{
+			InputFile: input,
+			BaseURL:   "https://registry.example.com",
+			Language:  "en-US",
+		})
+		p.SetIntegrityCache(cache)
+
+		cred, err := p.ParseToCredential(input)
+		if err != nil {
+			t.Fatalf("ParseToCredential() error = %v", err)
+		}
+		outputs, err := p.Generate(cred, []string{"vctm"})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		var doc struct {
+			Display []struct {
+				Rendering struct {
+					Simple struct {
+						Logo map[string]string `json:"logo"`
+					} `json:"simple"`
+				} `json:"rendering"`
+			} `json:"display"`
+		}
+		if err := json.Unmarshal(outputs["vctm"], &doc); err != nil {
+			t.Fatalf("invalid vctm JSON: %v", err)
+		}
+		if len(doc.Display) == 0 || doc.Display[0].Rendering.Simple.Logo["uri#integrity"] == "" {
+			t.Errorf("%s: logo missing uri#integrity: %s", name, outputs["vctm"])
+		}
+
+		// The legacy VCTM conversion shares the same cache
+		parsed, err := p.Parse(input)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if _, err := p.ToVCTM(parsed); err != nil {
+			t.Fatalf("ToVCTM() error = %v", err)
+		}
+	}
+
+	if reads[logo] != 1 {
+		t.Errorf("logo hashed %d times, want 1", reads[logo])
+	}
+}
+
+func TestIntegrityCache_Remote(t *testing.T) {
+	image := []byte("remote logo bytes")
+	hits := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits[r.URL.Path]++
+		switch r.URL.Path {
+		case "/logo.png":
+			_, _ = w.Write(image)
+		case "/large.png":
+			_, _ = w.Write(make([]byte, 64))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cache := NewIntegrityCache()
+	if _, err := cache.Get(server.URL + "/logo.png"); err == nil {
+		t.Error("Get() of a remote image without EnableRemote should fail")
+	}
+
+	cache.EnableRemote(time.Second, 32)
+	for i := 0; i < 2; i++ {
+		got, err := cache.Get(server.URL + "/logo.png")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if want := CalculateIntegrityBytes(image); got != want {
+			t.Errorf("Get() = %q, want %q", got, want)
+		}
+	}
+	if hits["/logo.png"] != 1 {
+		t.Errorf("logo fetched %d times, want 1", hits["/logo.png"])
+	}
+
+	if _, err := cache.Get(server.URL + "/large.png"); err == nil || !strings.Contains(err.Error(), "larger than 32 bytes") {
+		t.Errorf("Get() of an oversized image error = %v, want size limit error", err)
+	}
+	if _, err := cache.Get(server.URL + "/missing.png"); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("Get() of a missing image error = %v, want HTTP 404", err)
+	}
+}
+
+func TestParser_Generate_RemoteImageIntegrity(t *testing.T) {
+	image := []byte("remote logo bytes")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(image)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "identity.md")
+	if err := os.WriteFile(input, []byte("# Identity\n\n![Logo]("+server.URL+"/logo.png)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, resolve := range []bool{false, true} {
+		p := NewParser(&config.Config{
+			InputFile:           input,
+			BaseURL:             "https://registry.example.com",
+			Language:            "en-US",
+			InlineImages:        true,
+			ResolveRemoteImages: resolve,
+		})
+		cred, err := p.ParseToCredential(input)
+		if err != nil {
+			t.Fatalf("ParseToCredential() error = %v", err)
+		}
+		outputs, err := p.Generate(cred, []string{"vctm"})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		var doc struct {
+			Display []struct {
+				Rendering struct {
+					Simple struct {
+						Logo map[string]string `json:"logo"`
+					} `json:"simple"`
+				} `json:"rendering"`
+			} `json:"display"`
+		}
+		if err := json.Unmarshal(outputs["vctm"], &doc); err != nil {
+			t.Fatalf("invalid vctm JSON: %v", err)
+		}
+		logo := doc.Display[0].Rendering.Simple.Logo
+		if logo["uri"] != server.URL+"/logo.png" {
+			t.Errorf("resolve=%v: logo uri = %q, want the remote URL", resolve, logo["uri"])
+		}
+		wantIntegrity := ""
+		if resolve {
+			wantIntegrity = CalculateIntegrityBytes(image)
+		}
+		if logo["uri#integrity"] != wantIntegrity {
+			t.Errorf("resolve=%v: logo uri#integrity = %q, want %q", resolve, logo["uri#integrity"], wantIntegrity)
+		}
+	}
+}
@@ -5,41 +5,61 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
-	"io"
+	"html"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/formats"
 	"github.com/sirosfoundation/mtcvctm/pkg/vctm"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
 	"github.com/yuin/goldmark/text"
 	"gopkg.in/yaml.v3"
 )
 
 // Parser parses markdown files and generates VCTM
 type Parser struct {
-	config *config.Config
-	md     goldmark.Markdown
+	config    *config.Config
+	md        goldmark.Markdown
+	integrity *IntegrityCache
 }
 
 // NewParser creates a new parser with the given configuration
 func NewParser(cfg *config.Config) *Parser {
+	integrity := NewIntegrityCache()
+	if cfg.ResolveRemoteImages {
+		integrity.EnableRemote(cfg.RemoteImageTimeout, cfg.RemoteImageMaxBytes)
+	}
 	return &Parser{
-		config: cfg,
-		md:     goldmark.New(),
+		config:    cfg,
+		md:        goldmark.New(goldmark.WithExtensions(extension.Table)),
+		integrity: integrity,
 	}
 }
 
+// SetIntegrityCache shares an integrity cache with the parser, e.g. one cache
+// for all credentials of a batch run
+func (p *Parser) SetIntegrityCache(cache *IntegrityCache) {
+	p.integrity = cache
+}
+
 // ParsedMarkdown represents the parsed structure from a markdown file
 type ParsedMarkdown struct {
 	// Title is extracted from the first H1 heading
 	Title string
 
-	// Description is extracted from the first paragraph after the title
+	// Description is extracted from the paragraphs between the title and the first
+	// section heading, separated by blank lines
 	Description string
 
 	// Sections contains content organized by section headings
@@ -51,11 +71,60 @@ type ParsedMarkdown struct {
 	// Claims contains claim definitions extracted from the markdown
 	Claims map[string]ClaimDef
 
+	// ClaimOrder lists claim names in the order they appear in the markdown
+	ClaimOrder []string
+
 	// Metadata contains front matter or metadata extracted from the markdown
 	Metadata map[string]string
 
 	// DisplayLocalizations contains locale-specific display properties for the credential
 	DisplayLocalizations map[string]DisplayLocalization
+
+	// ColorSchemes contains the light and dark display variants from the front matter
+	// colors block
+	ColorSchemes map[string]ColorScheme
+
+	// Extends lists the parent types declared in front matter, in declaration order
+	Extends []string
+
+	// Profiles lists the deployment profiles the credential is tagged with
+	Profiles []string
+
+	// CredentialSchema references an external JSON Schema for the credential
+	CredentialSchema *CredentialSchemaRef
+
+	// FormatOverrides contains per-format settings from the front matter formats block
+	FormatOverrides map[string]map[string]interface{}
+
+	// ClaimMappings maps format name to claim renames from the front matter claim_mappings block
+	ClaimMappings map[string]map[string]string
+
+	// Warnings collects problems that did not stop parsing, such as duplicate claims,
+	// claims list items that are not claims, unknown front matter keys and missing images
+	Warnings []string
+
+	// claimLines records the source line of each claim definition for duplicate reports
+	claimLines map[string]int
+}
+
+// warnf records a parser warning
+func (pm *ParsedMarkdown) warnf(format string, args ...interface{}) {
+	pm.Warnings = append(pm.Warnings, fmt.Sprintf(format, args...))
+}
+
+// CredentialSchemaRef references an external credential schema declared in front matter
+type CredentialSchemaRef struct {
+	// ID is the URL of the schema
+	ID string `yaml:"id"`
+
+	// Type is the schema type (default: JsonSchema)
+	Type string `yaml:"type"`
+
+	// Path is an optional local copy of the schema used to compute integrity
+	Path string `yaml:"path"`
+
+	// Integrity is an explicit SRI integrity hash of the schema
+	Integrity string `yaml:"integrity"`
 }
 
 // DisplayLocalization contains localized display properties for the credential
@@ -67,6 +136,17 @@ type DisplayLocalization struct {
 	Description string `yaml:"description"`
 }
 
+// ColorScheme contains the display colors and logo of a light or dark variant
+type ColorScheme struct {
+	// BackgroundColor and TextColor are CSS colors for this variant
+	BackgroundColor string `yaml:"background_color"`
+	TextColor       string `yaml:"text_color"`
+
+	// Logo is a local path or remote URL of the logo for this variant
+	Logo        string `yaml:"logo"`
+	LogoAltText string `yaml:"logo_alt_text"`
+}
+
 // ImageRef represents a reference to an image
 type ImageRef struct {
 	// Path is the original path in the markdown
@@ -77,6 +157,111 @@ type ImageRef struct {
 
 	// AbsolutePath is the resolved absolute path
 	AbsolutePath string
+
+	// Orientation, ColorScheme and Contrast are SVG template properties from an
+	// attribute block after the image, e.g. {orientation=portrait color_scheme=dark}
+	Orientation string
+	ColorScheme string
+	Contrast    string
+
+	// Role marks the purpose of the image, e.g. {role=logo} for the credential logo
+	Role string
+}
+
+// resolveImage returns the image reference for a path from the markdown, resolving
+// relative paths against baseDir and warning about local images that do not exist
+func (pm *ParsedMarkdown) resolveImage(imgPath, altText, baseDir string) ImageRef {
+	absPath := imgPath
+	if !filepath.IsAbs(imgPath) && !strings.HasPrefix(imgPath, "http") {
+		absPath = filepath.Join(baseDir, imgPath)
+	}
+
+	if !strings.HasPrefix(imgPath, "http") && !strings.HasPrefix(imgPath, "data:") {
+		if _, err := os.Stat(absPath); err != nil {
+			pm.warnf("image %s not found", imgPath)
+		}
+	}
+	return ImageRef{
+		Path:         imgPath,
+		AltText:      altText,
+		AbsolutePath: absPath,
+	}
+}
+
+var (
+	// htmlImagePattern matches an HTML <img> tag
+	htmlImagePattern = regexp.MustCompile(`(?is)<img\b[^>]*>`)
+	// htmlAttributePattern matches the src and alt attributes of an HTML tag
+	htmlAttributePattern = regexp.MustCompile(`(?is)\s(src|alt)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// htmlImages returns the src and alt text of the <img> tags in raw HTML. Tags
+// without a src are skipped.
+func htmlImages(raw []byte) []ImageRef {
+	var images []ImageRef
+	for _, tag := range htmlImagePattern.FindAll(raw, -1) {
+		var img ImageRef
+		for _, attr := range htmlAttributePattern.FindAllSubmatch(tag, -1) {
+			value := html.UnescapeString(string(attr[2]) + string(attr[3]) + string(attr[4]))
+			if strings.EqualFold(string(attr[1]), "src") {
+				img.Path = value
+			} else {
+				img.AltText = value
+			}
+		}
+		if img.Path != "" {
+			images = append(images, img)
+		}
+	}
+	return images
+}
+
+// segmentBytes concatenates the source text of segments
+func segmentBytes(segments *text.Segments, source []byte) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < segments.Len(); i++ {
+		seg := segments.At(i)
+		buf.Write(seg.Value(source))
+	}
+	return buf.Bytes()
+}
+
+// imageAttributePattern matches an attribute block directly following an image
+var imageAttributePattern = regexp.MustCompile(`^\{([^}]*)\}`)
+
+// parseImageAttributes reads the attribute block written directly after an image,
+// e.g. ![Card](card-dark.svg){orientation=portrait color_scheme=dark}, into img
+func parseImageAttributes(node *ast.Image, source []byte, img *ImageRef) {
+	var text bytes.Buffer
+	for sibling := node.NextSibling(); sibling != nil; sibling = sibling.NextSibling() {
+		t, ok := sibling.(*ast.Text)
+		if !ok {
+			break
+		}
+		text.Write(t.Segment.Value(source))
+		if bytes.Contains(text.Bytes(), []byte("}")) || t.SoftLineBreak() || t.HardLineBreak() {
+			break
+		}
+	}
+
+	matches := imageAttributePattern.FindSubmatch(text.Bytes())
+	if matches == nil {
+		return
+	}
+	for _, attribute := range strings.Fields(string(matches[1])) {
+		key, value, _ := strings.Cut(attribute, "=")
+		value = strings.Trim(value, "\"'")
+		switch strings.ToLower(key) {
+		case "orientation":
+			img.Orientation = value
+		case "color_scheme":
+			img.ColorScheme = value
+		case "contrast":
+			img.Contrast = value
+		case "role":
+			img.Role = strings.ToLower(value)
+		}
+	}
 }
 
 // ClaimDef represents a claim definition
@@ -99,9 +284,40 @@ type ClaimDef struct {
 	// SvgId is the ID for SVG template reference
 	SvgId string
 
+	// Group is a UI grouping hint such as "Personal"
+	Group string
+
 	// DisplayName is the friendly display label for the claim
 	DisplayName string
 
+	// Const is a fixed value the claim always has
+	Const string
+
+	// Example is a single example value for the claim
+	Example string
+
+	// Examples are additional example values for the claim
+	Examples []string
+
+	// Enum lists the allowed values of the claim
+	Enum []string
+
+	// Min and Max bound numeric claim values (inclusive)
+	Min string
+	Max string
+
+	// Pattern is a regular expression string claim values must match
+	Pattern string
+
+	// Order is the claim's display position from [order=N], a positive integer
+	Order string
+
+	// Hidden keeps the claim out of display metadata while leaving it in schemas
+	Hidden bool
+
+	// UnknownFlags lists bracket flags that were not recognized, e.g. a misspelled [mandatroy]
+	UnknownFlags []string
+
 	// Localizations contains locale-specific display names and descriptions
 	Localizations map[string]ClaimLocalization
 }
@@ -127,6 +343,33 @@ func (p *Parser) Parse(inputPath string) (*ParsedMarkdown, error) {
 
 // ParseContent parses markdown content and returns the parsed structure
 func (p *Parser) ParseContent(content []byte, basePath string) (*ParsedMarkdown, error) {
+	parsed, err := p.parseContent(content, basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.config.InheritClaims {
+		if err := p.inheritClaims(parsed, basePath, make(map[string]bool)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Claims without an explicit sd flag follow the configured policy
+	if policy := p.config.SDPolicy; policy != nil {
+		for name, claim := range parsed.Claims {
+			if claim.SD == "" {
+				claim.SD = policy.SD(claim.Mandatory)
+				parsed.Claims[name] = claim
+			}
+		}
+	}
+
+	return parsed, nil
+}
+
+// parseContent parses markdown content without resolving claims inherited from
+// a parent type
+func (p *Parser) parseContent(content []byte, basePath string) (*ParsedMarkdown, error) {
 	reader := text.NewReader(content)
 	doc := p.md.Parser().Parse(reader)
 
@@ -141,11 +384,56 @@ func (p *Parser) ParseContent(content []byte, basePath string) (*ParsedMarkdown,
 
 	// Extract front matter if present
 	parsed.Metadata, parsed.DisplayLocalizations = extractFrontMatter(content)
+	fmData := parseFrontMatterData(content)
+	parsed.Extends = fmData.Extends
+	parsed.Profiles = fmData.Profiles
+	parsed.CredentialSchema = fmData.CredentialSchema
+	parsed.FormatOverrides = fmData.Formats
+	parsed.ClaimMappings = fmData.ClaimMappings
+	for format, mappings := range fmData.ClaimMapping {
+		if parsed.ClaimMappings == nil {
+			parsed.ClaimMappings = make(map[string]map[string]string)
+		}
+		if _, ok := parsed.ClaimMappings[format]; !ok {
+			parsed.ClaimMappings[format] = mappings
+		}
+	}
+	for _, key := range unknownFrontMatterKeys(content) {
+		parsed.warnf("unknown front matter key %s", key)
+	}
+	for scheme, colors := range fmData.Colors {
+		if scheme != formats.ColorSchemeLight && scheme != formats.ColorSchemeDark {
+			parsed.warnf("unknown color scheme %s (want light or dark)", scheme)
+			continue
+		}
+		if parsed.ColorSchemes == nil {
+			parsed.ColorSchemes = make(map[string]ColorScheme)
+		}
+		parsed.ColorSchemes[scheme] = colors
+	}
 
 	// Walk the AST to extract content
 	var currentSection string
 	var sectionContent bytes.Buffer
 
+	// Lists and tables are collected with whether they appear under a claims heading;
+	// once a document has a claims section, only lists and tables inside it define
+	// claims. Sub-headings in the claims section written as claims group the lists
+	// below them into an object claim and are kept in the same slice to preserve
+	// document order.
+	type sectionList struct {
+		list     *ast.List
+		table    *extast.Table
+		group    *ClaimDef
+		line     int
+		parent   string
+		inClaims bool
+	}
+	var lists []sectionList
+	var groups []claimGroup
+	hasClaimsSection := false
+	claimsLevel := 0
+
 	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
 			return ast.WalkContinue, nil
@@ -167,34 +455,66 @@ func (p *Parser) ParseContent(content []byte, basePath string) (*ParsedMarkdown,
 				currentSection = headingText
 			}
 
+			// Sub-headings stay inside the claims section, same or higher levels end it
+			if claimsLevel > 0 && node.Level <= claimsLevel {
+				claimsLevel = 0
+			}
+			for len(groups) > 0 && groups[len(groups)-1].level >= node.Level {
+				groups = groups[:len(groups)-1]
+			}
+			if claimsLevel == 0 && currentSection != "_title" && isClaimsHeading(headingText) {
+				claimsLevel = node.Level
+				hasClaimsSection = true
+			} else if claimsLevel > 0 {
+				if group := parseClaimGroupHeading(headingText); group != nil {
+					parent := currentClaimGroup(groups)
+					if parent != "" {
+						group.Name = parent + "." + group.Name
+					}
+					lists = append(lists, sectionList{group: group, line: sourceLine(node, content), inClaims: true})
+					groups = append(groups, claimGroup{level: node.Level, name: group.Name})
+				}
+			}
+
 		case *ast.Paragraph:
 			paragraphText := extractText(node, content)
-			if currentSection == "_title" && parsed.Description == "" {
-				parsed.Description = paragraphText
+			if currentSection == "_title" && !isImageParagraph(node, content) {
+				if parsed.Description != "" {
+					parsed.Description += "\n\n"
+				}
+				parsed.Description += paragraphText
 			} else {
 				sectionContent.WriteString(paragraphText)
 				sectionContent.WriteString("\n\n")
 			}
 
 		case *ast.Image:
-			imgPath := string(node.Destination)
-			altText := extractText(node, content)
-
-			absPath := imgPath
-			if !filepath.IsAbs(imgPath) && !strings.HasPrefix(imgPath, "http") {
-				absPath = filepath.Join(baseDir, imgPath)
+			// Reference-style images arrive here with their destination resolved
+			img := parsed.resolveImage(string(node.Destination), extractText(node, content), baseDir)
+			parseImageAttributes(node, content, &img)
+			parsed.Images = append(parsed.Images, img)
+
+		case *ast.RawHTML:
+			for _, img := range htmlImages(segmentBytes(node.Segments, content)) {
+				parsed.Images = append(parsed.Images, parsed.resolveImage(img.Path, img.AltText, baseDir))
 			}
 
-			parsed.Images = append(parsed.Images, ImageRef{
-				Path:         imgPath,
-				AltText:      altText,
-				AbsolutePath: absPath,
-			})
+		case *ast.HTMLBlock:
+			raw := segmentBytes(node.Lines(), content)
+			if node.HasClosure() {
+				raw = append(raw, node.ClosureLine.Value(content)...)
+			}
+			for _, img := range htmlImages(raw) {
+				parsed.Images = append(parsed.Images, parsed.resolveImage(img.Path, img.AltText, baseDir))
+			}
 
 		case *ast.List:
 			// Handle lists specially to capture claim localizations
-			parseClaimsList(node, content, parsed)
+			lists = append(lists, sectionList{list: node, parent: currentClaimGroup(groups), inClaims: claimsLevel > 0})
 			return ast.WalkSkipChildren, nil
+
+		case *extast.Table:
+			lists = append(lists, sectionList{table: node, parent: currentClaimGroup(groups), inClaims: claimsLevel > 0})
 		}
 
 		return ast.WalkContinue, nil
@@ -209,11 +529,232 @@ func (p *Parser) ParseContent(content []byte, basePath string) (*ParsedMarkdown,
 		return nil, fmt.Errorf("parser: failed to walk AST: %w", err)
 	}
 
+	// Without a claims section, the lenient policy reads claims from every list
+	unsectioned := !hasClaimsSection && p.config.ClaimsSection != config.ClaimsSectionStrict
+	for _, l := range lists {
+		switch {
+		case l.group != nil:
+			parsed.addClaimName(l.group.Name)
+			parsed.addClaim(*l.group, l.line)
+		case !l.inClaims && !unsectioned:
+			// Lists and tables outside the claims section are not claims
+		case l.table != nil:
+			parseClaimsTable(l.table, content, parsed, l.parent)
+		default:
+			parseNestedClaimsList(l.list, content, parsed, l.parent, l.inClaims)
+		}
+	}
+
+	if unsectioned && len(parsed.Claims) > 0 {
+		parsed.warnf("claims outside a Claims section are deprecated; add a \"## Claims\" heading (claims_section: strict ignores them)")
+	}
+
+	if p.config.ResolveExtends {
+		if err := p.resolveExtends(parsed); err != nil {
+			return nil, err
+		}
+	}
+
+	// A misspelled sd value would produce metadata that wallets reject
+	for _, name := range parsed.OrderedClaimNames() {
+		if sd := parsed.Claims[name].SD; sd != "" && !vctm.IsValidSD(sd) {
+			return nil, fmt.Errorf("parser: claim %s has invalid sd value %q (want always, allowed or never)", name, sd)
+		}
+		if err := validateClaimConstraints(name, parsed.Claims[name]); err != nil {
+			return nil, err
+		}
+		if order := parsed.Claims[name].Order; order != "" {
+			if _, ok := claimOrder(order); !ok {
+				return nil, fmt.Errorf("parser: claim %s has invalid order value %q (want a positive integer)", name, order)
+			}
+		}
+	}
+
+	if p.config.Strict {
+		for _, name := range parsed.OrderedClaimNames() {
+			if flags := parsed.Claims[name].UnknownFlags; len(flags) > 0 {
+				return nil, fmt.Errorf("parser: claim %s has unknown flag [%s]", name, flags[0])
+			}
+		}
+	}
+
 	return parsed, nil
 }
 
-// parseClaimsList parses a list to extract claims with potential localizations
-func parseClaimsList(list *ast.List, content []byte, parsed *ParsedMarkdown) {
+// claimsHeadings are the normalized section headings that introduce claim definitions
+var claimsHeadings = map[string]bool{
+	"claims":            true,
+	"claim definitions": true,
+	"attributes":        true,
+	"data elements":     true,
+}
+
+// trailingParenthetical matches a parenthetical at the end of a heading, e.g. "(v2)"
+var trailingParenthetical = regexp.MustCompile(`\s*\([^()]*\)\s*$`)
+
+// normalizeHeading case-folds a heading and strips trailing parentheticals and punctuation
+func normalizeHeading(heading string) string {
+	h := strings.ToLower(strings.TrimSpace(heading))
+	for {
+		stripped := strings.TrimRight(trailingParenthetical.ReplaceAllString(h, ""), " \t:;.,!?-–—")
+		if stripped == h {
+			return h
+		}
+		h = stripped
+	}
+}
+
+// isClaimsHeading reports whether a section heading introduces the claims section
+func isClaimsHeading(heading string) bool {
+	return claimsHeadings[normalizeHeading(heading)]
+}
+
+// isImageParagraph reports whether a paragraph holds only images, such as a logo
+// placed below the title, so that it is not mistaken for description text
+func isImageParagraph(para *ast.Paragraph, source []byte) bool {
+	hasImage := false
+	for c := para.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Image:
+			hasImage = true
+		case *ast.RawHTML:
+			raw := segmentBytes(c.Segments, source)
+			if !htmlImagePattern.Match(raw) || len(bytes.TrimSpace(htmlImagePattern.ReplaceAll(raw, nil))) > 0 {
+				return false
+			}
+			hasImage = true
+		case *ast.Text:
+			if len(bytes.TrimSpace(c.Segment.Value(source))) > 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return hasImage
+}
+
+// claimGroup is an open sub-heading in the claims section that defines an object claim
+type claimGroup struct {
+	level int
+	name  string
+}
+
+// currentClaimGroup returns the name of the innermost open claim group, if any
+func currentClaimGroup(groups []claimGroup) string {
+	if len(groups) == 0 {
+		return ""
+	}
+	return groups[len(groups)-1].name
+}
+
+// parseClaimGroupHeading parses a claims sub-heading written in claim syntax, e.g.
+// "`address` (object)", as an object claim. Other headings return nil.
+func parseClaimGroupHeading(text string) *ClaimDef {
+	claim := parseClaimFromListItem(text)
+	if claim == nil {
+		return nil
+	}
+	if matches := claimPattern.FindStringSubmatch(text); matches[3] == "" {
+		claim.Type = "object"
+	}
+	return claim
+}
+
+// addClaimName records a claim name in document order the first time it is seen
+func (pm *ParsedMarkdown) addClaimName(name string) {
+	for _, existing := range pm.ClaimOrder {
+		if existing == name {
+			return
+		}
+	}
+	pm.ClaimOrder = append(pm.ClaimOrder, name)
+}
+
+// addClaim stores a claim definition found at the given source line. A repeated
+// name, including nested names such as address.street, is reported and the first
+// definition is kept.
+func (pm *ParsedMarkdown) addClaim(claim ClaimDef, line int) {
+	if _, exists := pm.Claims[claim.Name]; exists {
+		pm.warnf("duplicate claim %s at line %d, keeping the definition at line %d", claim.Name, line, pm.claimLines[claim.Name])
+		return
+	}
+	if pm.claimLines == nil {
+		pm.claimLines = make(map[string]int)
+	}
+	pm.Claims[claim.Name] = claim
+	pm.claimLines[claim.Name] = line
+}
+
+// sourceLine returns the 1-based line of the first source text within node, or 0
+func sourceLine(node ast.Node, content []byte) int {
+	line := 0
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		start := -1
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			start = n.Lines().At(0).Start
+		} else if t, ok := n.(*ast.Text); ok {
+			start = t.Segment.Start
+		}
+		if start < 0 {
+			return ast.WalkContinue, nil
+		}
+		line = bytes.Count(content[:start], []byte("\n")) + 1
+		return ast.WalkStop, nil
+	})
+	return line
+}
+
+// OrderedClaimNames returns claim names in display order: claims with an [order=N]
+// annotation first, ascending, then the others in document order, followed by any
+// claims not recorded in ClaimOrder sorted by name
+func (pm *ParsedMarkdown) OrderedClaimNames() []string {
+	names := make([]string, 0, len(pm.Claims))
+	seen := make(map[string]bool, len(pm.Claims))
+	for _, name := range pm.ClaimOrder {
+		if _, ok := pm.Claims[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+	var rest []string
+	for name := range pm.Claims {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	names = append(names, rest...)
+
+	sort.SliceStable(names, func(i, j int) bool {
+		a, aOK := claimOrder(pm.Claims[names[i]].Order)
+		b, bOK := claimOrder(pm.Claims[names[j]].Order)
+		if aOK && bOK {
+			return a < b
+		}
+		return aOK && !bOK
+	})
+	return names
+}
+
+// claimOrder parses an [order=N] annotation, reporting false when it is empty or
+// not a positive integer
+func claimOrder(value string) (int, bool) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n, true
+}
+
+// parseNestedClaimsList parses claims below the given parent claim name. Nested
+// list items using the backtick claim syntax become child claims named parent.child,
+// items using the locale: syntax become localizations of the enclosing claim. Inside
+// a claims section, items that are neither are reported as warnings.
+func parseNestedClaimsList(list *ast.List, content []byte, parsed *ParsedMarkdown, parent string, inClaims bool) {
 	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
 		listItem, ok := item.(*ast.ListItem)
 		if !ok {
@@ -222,20 +763,43 @@ func parseClaimsList(list *ast.List, content []byte, parsed *ParsedMarkdown) {
 
 		// Extract the first text content (the claim definition)
 		var claimText string
+		var claimNode ast.Node
 		for child := listItem.FirstChild(); child != nil; child = child.NextSibling() {
 			if para, ok := child.(*ast.Paragraph); ok {
 				claimText = extractText(para, content)
+				claimNode = child
 				break
 			} else if txt, ok := child.(*ast.TextBlock); ok {
 				claimText = extractText(txt, content)
+				claimNode = child
 				break
 			}
 		}
 
 		claim := parseClaimFromListItem(claimText)
 		if claim == nil {
+			if _, _, isLocalization := parseLocalizationFromListItem(claimText); inClaims && claimText != "" && !isLocalization {
+				parsed.warnf("list item %q in the claims section is not a claim definition", claimText)
+			}
 			continue
 		}
+		if parent != "" {
+			claim.Name = parent + "." + claim.Name
+		}
+		parsed.addClaimName(claim.Name)
+
+		// Paragraphs directly following the definition describe the claim when it has no inline description
+		if claim.Description == "" {
+			var paragraphs []string
+			for next := claimNode.NextSibling(); next != nil; next = next.NextSibling() {
+				para, ok := next.(*ast.Paragraph)
+				if !ok {
+					break
+				}
+				paragraphs = append(paragraphs, extractText(para, content))
+			}
+			claim.Description = strings.Join(paragraphs, "\n\n")
+		}
 
 		// Look for nested list with localizations
 		for child := listItem.FirstChild(); child != nil; child = child.NextSibling() {
@@ -248,11 +812,135 @@ func parseClaimsList(list *ast.List, content []byte, parsed *ParsedMarkdown) {
 						}
 					}
 				}
+				// Items that are not localizations may define child claims
+				parseNestedClaimsList(nestedList, content, parsed, claim.Name, inClaims)
 			}
 		}
 
-		parsed.Claims[claim.Name] = *claim
+		parsed.addClaim(*claim, sourceLine(claimNode, content))
+	}
+}
+
+// claimTableColumns maps normalized claims table headers to ClaimDef fields
+var claimTableColumns = map[string]string{
+	"claim":        "name",
+	"name":         "name",
+	"type":         "type",
+	"label":        "label",
+	"display name": "label",
+	"description":  "description",
+	"mandatory":    "mandatory",
+	"sd":           "sd",
+	"svg id":       "svg_id",
+	"svg_id":       "svg_id",
+	"group":        "group",
+	"order":        "order",
+}
+
+// parseClaimsTable parses a claims table with a Claim column and optional Type,
+// Label, Description, Mandatory, SD, SVG ID, Group and Order columns. Missing or empty cells
+// behave like the list syntax defaults. Tables without a Claim column are ignored.
+func parseClaimsTable(table *extast.Table, content []byte, parsed *ParsedMarkdown, parent string) {
+	var columns []string
+	hasName := false
+	for child := table.FirstChild(); child != nil; child = child.NextSibling() {
+		header, ok := child.(*extast.TableHeader)
+		if !ok {
+			continue
+		}
+		for cell := header.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			column := claimTableColumns[strings.ToLower(extractText(cell, content))]
+			columns = append(columns, column)
+			hasName = hasName || column == "name"
+		}
+	}
+	if !hasName {
+		return
+	}
+
+	for child := table.FirstChild(); child != nil; child = child.NextSibling() {
+		row, ok := child.(*extast.TableRow)
+		if !ok {
+			continue
+		}
+
+		claim := &ClaimDef{Localizations: make(map[string]ClaimLocalization)}
+		i := 0
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			if i >= len(columns) {
+				break
+			}
+			value := extractText(cell, content)
+			switch columns[i] {
+			case "name":
+				claim.Name = strings.Trim(value, "` ")
+			case "type":
+				claim.Type = value
+			case "label":
+				claim.DisplayName = value
+			case "description":
+				claim.Description = value
+			case "mandatory":
+				claim.Mandatory = isTableFlagSet(value)
+			case "sd":
+				claim.SD = strings.ToLower(value)
+			case "svg_id":
+				claim.SvgId = strings.Trim(value, "` ")
+			case "group":
+				claim.Group = value
+			case "order":
+				claim.Order = value
+			}
+			i++
+		}
+		if claim.Name == "" {
+			continue
+		}
+		if claim.Type == "" {
+			claim.Type = "string"
+		}
+		if parent != "" {
+			claim.Name = parent + "." + claim.Name
+		}
+
+		parsed.addClaimName(claim.Name)
+		parsed.addClaim(*claim, sourceLine(row, content))
+	}
+}
+
+// validateClaimConstraints checks that the min and max constraints of a claim are
+// numbers, that min does not exceed max and that a const is not combined with an enum
+func validateClaimConstraints(name string, claim ClaimDef) error {
+	if claim.Const != "" && len(claim.Enum) > 0 {
+		return fmt.Errorf("parser: claim %s has both const and enum values (want one of them)", name)
+	}
+
+	bounds := make(map[string]float64, 2)
+	for key, value := range map[string]string{"min": claim.Min, "max": claim.Max} {
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("parser: claim %s has invalid %s value %q (want a number)", name, key, value)
+		}
+		bounds[key] = n
+	}
+	lower, hasMin := bounds["min"]
+	upper, hasMax := bounds["max"]
+	if hasMin && hasMax && lower > upper {
+		return fmt.Errorf("parser: claim %s has min %s greater than max %s", name, claim.Min, claim.Max)
+	}
+	return nil
+}
+
+// isTableFlagSet reports whether a boolean table cell such as Mandatory is set
+func isTableFlagSet(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "y", "true", "x", "✓", "✔", "mandatory":
+		return true
 	}
+	return false
 }
 
 // ToVCTM converts parsed markdown to a VCTM document
@@ -263,10 +951,17 @@ func (p *Parser) ToVCTM(parsed *ParsedMarkdown) (*vctm.VCTM, error) {
 		Description: parsed.Description,
 	}
 
+	locales := make([]string, 0, len(parsed.DisplayLocalizations))
+	for locale := range parsed.DisplayLocalizations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	language := p.config.DefaultLocale(locales)
+
 	// Add display properties
 	if parsed.Title != "" || parsed.Description != "" {
 		display := vctm.DisplayProperties{
-			Locale:      p.config.Language,
+			Locale:      language,
 			Name:        parsed.Title,
 			Description: parsed.Description,
 		}
@@ -282,7 +977,7 @@ func (p *Parser) ToVCTM(parsed *ParsedMarkdown) (*vctm.VCTM, error) {
 		// Add localized display properties from front matter
 		for locale, loc := range parsed.DisplayLocalizations {
 			// Skip if this is the same as default locale (already added)
-			if locale == p.config.Language {
+			if locale == language {
 				continue
 			}
 			localizedDisplay := vctm.DisplayProperties{
@@ -297,12 +992,16 @@ func (p *Parser) ToVCTM(parsed *ParsedMarkdown) (*vctm.VCTM, error) {
 	// Add claims as array with path (draft 12 format)
 	if len(parsed.Claims) > 0 {
 		v.Claims = make([]vctm.ClaimMetadataEntry, 0, len(parsed.Claims))
-		for name, claim := range parsed.Claims {
+		for _, name := range parsed.OrderedClaimNames() {
+			claim := parsed.Claims[name]
 			entry := vctm.ClaimMetadataEntry{
-				Path:      []interface{}{name},
+				Path:      formats.ParseClaimPath(name),
 				Mandatory: claim.Mandatory,
 				SD:        claim.SD,
-				SvgId:     claim.SvgId,
+				Group:     claim.Group,
+			}
+			if !claim.Hidden {
+				entry.SvgId = claim.SvgId
 			}
 
 			// Build display array with localizations
@@ -311,7 +1010,7 @@ func (p *Parser) ToVCTM(parsed *ParsedMarkdown) (*vctm.VCTM, error) {
 			// Add default locale display (from claim definition)
 			if claim.Description != "" || claim.DisplayName != "" {
 				defaultDisplay := vctm.ClaimDisplay{
-					Locale:      p.config.Language,
+					Locale:      language,
 					Description: claim.Description,
 				}
 				// Use display name if provided, otherwise fall back to claim name
@@ -323,25 +1022,37 @@ func (p *Parser) ToVCTM(parsed *ParsedMarkdown) (*vctm.VCTM, error) {
 				displays = append(displays, defaultDisplay)
 			}
 
-			// Add additional localizations from nested list items
-			for locale, loc := range claim.Localizations {
+			// Add additional localizations from nested list items in locale order
+			claimLocales := make([]string, 0, len(claim.Localizations))
+			for locale := range claim.Localizations {
+				claimLocales = append(claimLocales, locale)
+			}
+			sort.Strings(claimLocales)
+			for _, locale := range claimLocales {
 				// Skip if this is the same as default locale (already added)
-				if locale == p.config.Language {
+				if locale == language {
 					continue
 				}
+				loc := claim.Localizations[locale]
 				display := vctm.ClaimDisplay{
 					Locale:      locale,
 					Label:       loc.Label,
 					Description: loc.Description,
 				}
-				// If label is empty but we have one, use the display name
-				if display.Label == "" && claim.DisplayName != "" {
+				// Untranslated fields fall back to the default display
+				if display.Label == "" {
 					display.Label = claim.DisplayName
+					if display.Label == "" {
+						display.Label = claim.Name
+					}
+				}
+				if display.Description == "" {
+					display.Description = claim.Description
 				}
 				displays = append(displays, display)
 			}
 
-			if len(displays) > 0 {
+			if len(displays) > 0 && !claim.Hidden {
 				entry.Display = displays
 			}
 
@@ -354,25 +1065,89 @@ func (p *Parser) ToVCTM(parsed *ParsedMarkdown) (*vctm.VCTM, error) {
 		v.VCT = vctVal
 	}
 
-	// Override from extends metadata (now single URI in draft 12)
-	if extends, ok := parsed.Metadata["extends"]; ok {
-		v.Extends = strings.TrimSpace(extends)
+	// Override from extends metadata (single URI in draft 12, additional parents are dropped)
+	if len(parsed.Extends) > 0 {
+		v.Extends = parsed.Extends[0]
 	}
 	if extendsIntegrity, ok := parsed.Metadata["extends#integrity"]; ok {
 		v.ExtendsIntegrity = strings.TrimSpace(extendsIntegrity)
 	}
 
+	// Schema references; an embedded schema is given as a JSON string
+	if schemaURI, ok := parsed.Metadata["schema_uri"]; ok {
+		v.SchemaURI = strings.TrimSpace(schemaURI)
+	}
+	if schemaIntegrity, ok := parsed.Metadata["schema_uri#integrity"]; ok {
+		v.SchemaURIIntegrity = strings.TrimSpace(schemaIntegrity)
+	}
+	if schema, ok := parsed.Metadata["schema"]; ok {
+		if !json.Valid([]byte(schema)) {
+			return nil, fmt.Errorf("parser: schema in front matter is not valid JSON")
+		}
+		v.Schema = json.RawMessage(schema)
+	}
+
 	return v, nil
 }
 
+// hasLocalImages reports whether any image is a local file rather than a remote URL
+func hasLocalImages(images []ImageRef) bool {
+	for _, img := range images {
+		if !formats.IsRemoteURI(img.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// logoImage returns the logo: the image named by the front matter logo key,
+// else the image marked {role=logo}, else the first image. A front matter logo
+// that is not one of the images is resolved against the input file.
+func (p *Parser) logoImage(parsed *ParsedMarkdown) (ImageRef, bool) {
+	if path := strings.Trim(parsed.Metadata["logo"], "\""); path != "" {
+		logo, found := ImageRef{Path: path, AbsolutePath: path}, false
+		for _, img := range parsed.Images {
+			if img.Path == path {
+				logo, found = img, true
+				break
+			}
+		}
+		if !found && !formats.IsRemoteURI(path) && !filepath.IsAbs(path) {
+			logo.AbsolutePath = filepath.Join(filepath.Dir(p.config.InputFile), path)
+		}
+		if altText := strings.Trim(parsed.Metadata["logo_alt_text"], "\""); altText != "" {
+			logo.AltText = altText
+		}
+		return logo, true
+	}
+	for _, img := range parsed.Images {
+		if img.Role == formats.ImageRoleLogo {
+			return img, true
+		}
+	}
+	if len(parsed.Images) > 0 {
+		return parsed.Images[0], true
+	}
+	return ImageRef{}, false
+}
+
 // imageToLogo converts an ImageRef to a Logo with URL and integrity
 func (p *Parser) imageToLogo(img ImageRef) *vctm.Logo {
 	logo := &vctm.Logo{
 		AltText: img.AltText,
 	}
 
+	// Remote images keep their URL; integrity is only known when they are resolved
+	if formats.IsRemoteURI(img.Path) {
+		logo.URI = img.Path
+		if integrity, err := p.calculateIntegrity(img.Path); err == nil {
+			logo.URIIntegrity = integrity
+		}
+		return logo
+	}
+
 	// If inline images is enabled, convert to data URL
-	if p.config.InlineImages {
+	if p.inlines(img) {
 		if dataURL, err := p.imageToDataURL(img.AbsolutePath); err == nil {
 			logo.URI = dataURL
 			// No integrity needed for inline data URLs
@@ -381,8 +1156,8 @@ func (p *Parser) imageToLogo(img ImageRef) *vctm.Logo {
 		// Fall through to URL-based approach on error
 	}
 
-	if p.config.BaseURL != "" {
-		logo.URI = p.buildImageURL(img.Path)
+	if p.config.GetAssetBaseURL() != "" {
+		logo.URI = p.buildImageURL(img.Path, img.AbsolutePath)
 		if integrity, err := p.calculateIntegrity(img.AbsolutePath); err == nil {
 			logo.URIIntegrity = integrity
 		}
@@ -393,66 +1168,80 @@ func (p *Parser) imageToLogo(img ImageRef) *vctm.Logo {
 	return logo
 }
 
-// imageToDataURL reads an image file and converts it to a base64 data URL
+// colorSchemeRendering builds the simple rendering of a color scheme variant
+func (p *Parser) colorSchemeRendering(colors ColorScheme) *vctm.SimpleRendering {
+	simple := &vctm.SimpleRendering{
+		BackgroundColor: colors.BackgroundColor,
+		TextColor:       colors.TextColor,
+	}
+	if colors.Logo != "" {
+		absPath := colors.Logo
+		if !formats.IsRemoteURI(absPath) && !filepath.IsAbs(absPath) {
+			absPath = filepath.Join(filepath.Dir(p.config.InputFile), colors.Logo)
+		}
+		simple.Logo = p.imageToLogo(ImageRef{Path: colors.Logo, AltText: colors.LogoAltText, AbsolutePath: absPath})
+	}
+	return simple
+}
+
+// inlines reports whether a local image is inlined as a data URL. Images over the
+// inline limit are referenced by URL instead when an asset base URL is set.
+func (p *Parser) inlines(img ImageRef) bool {
+	if !p.config.InlineImages || p.config.GetAssetBaseURL() == "" {
+		return p.config.InlineImages
+	}
+	info, err := os.Stat(img.AbsolutePath)
+	return err != nil || !p.config.InlineTooLarge(info.Size())
+}
+
+// backgroundImage builds the background image from a local path, inlined or
+// referenced with integrity like a logo, or from a remote URL used as-is
+func (p *Parser) backgroundImage(path string) *vctm.BackgroundImage {
+	absPath := path
+	if !formats.IsRemoteURI(path) && !filepath.IsAbs(absPath) {
+		absPath = filepath.Join(filepath.Dir(p.config.InputFile), path)
+	}
+	image := p.imageToLogo(ImageRef{Path: path, AbsolutePath: absPath})
+	return &vctm.BackgroundImage{URI: image.URI, URIIntegrity: image.URIIntegrity}
+}
+
+// imageToDataURL reads an image file and converts it to a base64 data URL,
+// minifying SVG images unless disabled
 func (p *Parser) imageToDataURL(path string) (string, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return "", err
 	}
 
-	mimeType := getMimeType(path)
+	mimeType := formats.ImageMIMEType(path, data)
+	if mimeType == "image/svg+xml" && !p.config.NoSVGMinify {
+		data = formats.MinifySVG(data)
+	}
 	encoded := base64.StdEncoding.EncodeToString(data)
 	return fmt.Sprintf("data:%s;base64,%s", mimeType, encoded), nil
 }
 
-// getMimeType returns the MIME type for an image file based on extension
-func getMimeType(path string) string {
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".svg":
-		return "image/svg+xml"
-	case ".png":
-		return "image/png"
-	case ".jpg", ".jpeg":
-		return "image/jpeg"
-	case ".gif":
-		return "image/gif"
-	case ".webp":
-		return "image/webp"
-	case ".ico":
-		return "image/x-icon"
-	default:
-		return "application/octet-stream"
+// buildImageURL builds a full URL for an image whose local copy is at absolutePath
+func (p *Parser) buildImageURL(path, absolutePath string) string {
+	if formats.IsRemoteURI(path) {
+		return path
 	}
+	return p.config.AssetURL(formats.AssetPath(path, absolutePath, p.config))
 }
 
-// buildImageURL builds a full URL for an image
-func (p *Parser) buildImageURL(path string) string {
-	baseURL := strings.TrimSuffix(p.config.BaseURL, "/")
-	path = strings.TrimPrefix(path, "./")
-	return baseURL + "/" + path
-}
-
-// calculateIntegrity calculates SRI integrity hash for a file
+// calculateIntegrity calculates SRI integrity hash for a file, using the
+// parser's integrity cache when it has one
 func (p *Parser) calculateIntegrity(path string) (string, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return "", err
+	if p.integrity != nil {
+		return p.integrity.Get(path)
 	}
-
-	return "sha256-" + base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
+	return formats.FileIntegrity(path)
 }
 
 // buildRendering builds rendering information from parsed markdown
 func (p *Parser) buildRendering(parsed *ParsedMarkdown) *vctm.Rendering {
-	// Skip rendering if no base URL configured
-	if p.config.BaseURL == "" && len(parsed.Images) > 0 {
+	// Local images need either inlining or a base URL to be referenced
+	if p.config.GetAssetBaseURL() == "" && !p.config.InlineImages && hasLocalImages(parsed.Images) {
 		return nil
 	}
 
@@ -462,9 +1251,9 @@ func (p *Parser) buildRendering(parsed *ParsedMarkdown) *vctm.Rendering {
 	// Build simple rendering
 	simple := &vctm.SimpleRendering{}
 
-	// First image as logo
-	if len(parsed.Images) > 0 {
-		simple.Logo = p.imageToLogo(parsed.Images[0])
+	// The front matter logo, the image marked {role=logo} or the first image
+	if logo, ok := p.logoImage(parsed); ok {
+		simple.Logo = p.imageToLogo(logo)
 		hasContent = true
 	}
 
@@ -480,8 +1269,26 @@ func (p *Parser) buildRendering(parsed *ParsedMarkdown) *vctm.Rendering {
 
 	// Check for background image in metadata
 	if bgImg, ok := parsed.Metadata["background_image"]; ok {
-		simple.BackgroundImage = &vctm.BackgroundImage{
-			URI: strings.Trim(bgImg, "\""),
+		simple.BackgroundImage = p.backgroundImage(strings.Trim(bgImg, "\""))
+		hasContent = true
+	}
+
+	// Color scheme variants; the light variant fills what the flat keys leave unset
+	if len(parsed.ColorSchemes) > 0 {
+		rendering.ColorSchemes = make(map[string]*vctm.SimpleRendering, len(parsed.ColorSchemes))
+		for scheme, colors := range parsed.ColorSchemes {
+			rendering.ColorSchemes[scheme] = p.colorSchemeRendering(colors)
+		}
+		if light, ok := rendering.ColorSchemes[formats.ColorSchemeLight]; ok {
+			if simple.Logo == nil {
+				simple.Logo = light.Logo
+			}
+			if simple.BackgroundColor == "" {
+				simple.BackgroundColor = light.BackgroundColor
+			}
+			if simple.TextColor == "" {
+				simple.TextColor = light.TextColor
+			}
 		}
 		hasContent = true
 	}
@@ -493,11 +1300,18 @@ func (p *Parser) buildRendering(parsed *ParsedMarkdown) *vctm.Rendering {
 	// SVG templates
 	var svgTemplates []vctm.SVGTemplate
 	for _, img := range parsed.Images {
-		if strings.HasSuffix(strings.ToLower(img.Path), ".svg") {
+		if img.Role != formats.ImageRoleLogo && strings.HasSuffix(strings.ToLower(img.Path), ".svg") {
 			var tmpl vctm.SVGTemplate
+			if img.Orientation != "" || img.ColorScheme != "" || img.Contrast != "" {
+				tmpl.Properties = &vctm.SVGTemplateProperties{
+					Orientation: img.Orientation,
+					ColorScheme: img.ColorScheme,
+					Contrast:    img.Contrast,
+				}
+			}
 
-			// If inline images is enabled, convert to data URL
-			if p.config.InlineImages {
+			// If inline images is enabled, convert local files to data URLs
+			if !formats.IsRemoteURI(img.Path) && p.inlines(img) {
 				if dataURL, err := p.imageToDataURL(img.AbsolutePath); err == nil {
 					tmpl.URI = dataURL
 					// No integrity needed for inline data URLs
@@ -507,7 +1321,7 @@ func (p *Parser) buildRendering(parsed *ParsedMarkdown) *vctm.Rendering {
 				// Fall through to URL-based approach on error
 			}
 
-			tmpl.URI = p.buildImageURL(img.Path)
+			tmpl.URI = p.buildImageURL(img.Path, img.AbsolutePath)
 			if integrity, err := p.calculateIntegrity(img.AbsolutePath); err == nil {
 				tmpl.URIIntegrity = integrity
 			}
@@ -527,52 +1341,178 @@ func (p *Parser) buildRendering(parsed *ParsedMarkdown) *vctm.Rendering {
 	return rendering
 }
 
-// extractText extracts text content from an AST node
+// extractText extracts the plain text content of an AST node. Emphasis is unwrapped,
+// links become "text (url)", code spans keep their backticks for claim parsing and
+// whitespace runs outside code spans collapse to a single space.
 func extractText(node ast.Node, source []byte) string {
 	var buf bytes.Buffer
+	writeText(&buf, node, source)
+	return strings.TrimSpace(buf.String())
+}
+
+// writeText writes the plain text of the children of node to buf
+func writeText(buf *bytes.Buffer, node ast.Node, source []byte) {
 	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
-		if t, ok := c.(*ast.Text); ok {
-			buf.Write(t.Segment.Value(source))
-			if t.HardLineBreak() || t.SoftLineBreak() {
-				buf.WriteString(" ")
+		switch c := c.(type) {
+		case *ast.Text:
+			writeCollapsed(buf, c.Segment.Value(source))
+			if c.HardLineBreak() || c.SoftLineBreak() {
+				writeCollapsed(buf, []byte(" "))
 			}
-		} else if cs, ok := c.(*ast.CodeSpan); ok {
+		case *ast.String:
+			writeCollapsed(buf, c.Value)
+		case *ast.CodeSpan:
 			// Preserve code spans with backticks for claim parsing
 			buf.WriteString("`")
-			for seg := cs.FirstChild(); seg != nil; seg = seg.NextSibling() {
+			for seg := c.FirstChild(); seg != nil; seg = seg.NextSibling() {
 				if t, ok := seg.(*ast.Text); ok {
 					buf.Write(t.Segment.Value(source))
 				}
 			}
 			buf.WriteString("`")
-		} else {
-			buf.WriteString(extractText(c, source))
+		case *ast.Link:
+			start := buf.Len()
+			writeText(buf, c, source)
+			label := strings.TrimSpace(buf.String()[start:])
+			dest := string(c.Destination)
+			switch {
+			case dest == "" || dest == label:
+			case label == "":
+				writeCollapsed(buf, []byte(dest))
+			default:
+				writeCollapsed(buf, []byte(" ("+dest+")"))
+			}
+		case *ast.AutoLink:
+			writeCollapsed(buf, c.Label(source))
+		default:
+			writeText(buf, c, source)
 		}
 	}
-	return strings.TrimSpace(buf.String())
+}
+
+// writeCollapsed writes text to buf with whitespace runs collapsed to one space,
+// dropping leading whitespace that would follow a space already written
+func writeCollapsed(buf *bytes.Buffer, text []byte) {
+	for _, r := range string(text) {
+		if unicode.IsSpace(r) {
+			if buf.Len() == 0 || buf.Bytes()[buf.Len()-1] == ' ' {
+				continue
+			}
+			buf.WriteByte(' ')
+			continue
+		}
+		buf.WriteRune(r)
+	}
 }
 
 // frontMatterData represents the YAML front matter structure
 type frontMatterData struct {
-	Display map[string]DisplayLocalization `yaml:"display"`
+	Display  map[string]DisplayLocalization `yaml:"display"`
+	Colors   map[string]ColorScheme         `yaml:"colors"`
+	Extends  stringList                     `yaml:"extends"`
+	Profiles stringList                     `yaml:"profiles"`
+
+	CredentialSchema *CredentialSchemaRef `yaml:"credential_schema"`
+
+	Formats       map[string]map[string]interface{} `yaml:"formats"`
+	ClaimMappings map[string]map[string]string      `yaml:"claim_mappings"`
+	// ClaimMapping is the singular spelling used in the multi-format proposal
+	ClaimMapping map[string]map[string]string `yaml:"claim_mapping"`
 }
 
-// extractFrontMatter extracts YAML front matter from markdown
-func extractFrontMatter(content []byte) (map[string]string, map[string]DisplayLocalization) {
-	metadata := make(map[string]string)
-	displayLocs := make(map[string]DisplayLocalization)
+// stringList is a front matter value given either as a YAML sequence or as a comma-separated string
+type stringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (l *stringList) UnmarshalYAML(value *yaml.Node) error {
+	var items []string
+	switch value.Kind {
+	case yaml.SequenceNode:
+		if err := value.Decode(&items); err != nil {
+			return err
+		}
+	case yaml.ScalarNode:
+		items = strings.Split(value.Value, ",")
+	default:
+		// Ignore unsupported shapes rather than failing the whole front matter
+		return nil
+	}
+
+	*l = nil
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
 
+// frontMatterBytes returns the raw YAML front matter block of a markdown document, if present
+func frontMatterBytes(content []byte) ([]byte, bool) {
 	// Check for YAML front matter (--- ... ---)
 	if !bytes.HasPrefix(content, []byte("---")) {
-		return metadata, displayLocs
+		return nil, false
 	}
 
 	endIndex := bytes.Index(content[3:], []byte("---"))
 	if endIndex == -1 {
-		return metadata, displayLocs
+		return nil, false
+	}
+
+	return content[3 : endIndex+3], true
+}
+
+// parseFrontMatterData parses the structured (non-string) front matter fields
+func parseFrontMatterData(content []byte) frontMatterData {
+	var fmData frontMatterData
+	if frontMatter, ok := frontMatterBytes(content); ok {
+		_ = yaml.Unmarshal(frontMatter, &fmData)
 	}
+	return fmData
+}
 
-	frontMatter := content[3 : endIndex+3]
+// knownFrontMatterKeys lists the front matter keys read by the parser and generators
+var knownFrontMatterKeys = map[string]bool{
+	"id": true, "vct": true, "doctype": true, "namespace": true, "version": true,
+	"language": true, "locale": true,
+	"background_color": true, "text_color": true, "background_image": true,
+	"logo": true, "logo_alt_text": true,
+	"svg_template": true, "svg_template_uri": true, "svg_template_integrity": true,
+	"extends": true, "extends#integrity": true,
+	"schema": true, "schema_uri": true, "schema_uri#integrity": true,
+	"display": true, "colors": true, "profiles": true, "credential_schema": true,
+	"formats": true, "claim_mappings": true, "claim_mapping": true,
+}
+
+// unknownFrontMatterKeys returns the sorted front matter keys that nothing reads
+func unknownFrontMatterKeys(content []byte) []string {
+	frontMatter, ok := frontMatterBytes(content)
+	if !ok {
+		return nil
+	}
+	var genericMap map[string]interface{}
+	if err := yaml.Unmarshal(frontMatter, &genericMap); err != nil {
+		return nil
+	}
+	var unknown []string
+	for key := range genericMap {
+		if !knownFrontMatterKeys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// extractFrontMatter extracts YAML front matter from markdown
+func extractFrontMatter(content []byte) (map[string]string, map[string]DisplayLocalization) {
+	metadata := make(map[string]string)
+	displayLocs := make(map[string]DisplayLocalization)
+
+	frontMatter, ok := frontMatterBytes(content)
+	if !ok {
+		return metadata, displayLocs
+	}
 
 	// First, parse nested structures like display localizations
 	var fmData frontMatterData
@@ -594,10 +1534,22 @@ func extractFrontMatter(content []byte) (map[string]string, map[string]DisplayLo
 	return metadata, displayLocs
 }
 
+// FrontMatterLanguage returns the default locale a markdown document declares with
+// a language or locale front matter key, or "" when it declares none
+func FrontMatterLanguage(content []byte) string {
+	metadata, _ := extractFrontMatter(content)
+	for _, key := range []string{"language", "locale"} {
+		if language := strings.TrimSpace(metadata[key]); language != "" {
+			return language
+		}
+	}
+	return ""
+}
+
 // parseClaimFromListItem parses a claim definition from a list item
 // Expected formats:
-//   - `claim_name` (type): Description [mandatory] [sd=always|never]
-//   - `claim_name` "Display Name" (type): Description [mandatory] [sd=always|never]
+//   - `claim_name` (type): Description [mandatory] [sd=always|allowed|never]
+//   - `claim_name` "Display Name" (type): Description [mandatory] [sd=always|allowed|never]
 //
 // For localized claims (sub-list items under a claim):
 //   - en-US: "Display Name" - Description
@@ -629,24 +1581,59 @@ func parseClaimFromListItem(text string) *ClaimDef {
 	// Flags can appear as [flag1, flag2, ...] or individually as [flag]
 	desc := claim.Description
 
-	// Pattern to match bracketed flag groups: [mandatory, svg_id=foo, sd=always]
-	bracketPattern := regexp.MustCompile(`\[([^\]]+)\]`)
+	// Pattern to match bracketed flag groups: [mandatory, svg_id=foo, sd=always]. One
+	// level of nested brackets is allowed for character classes in [pattern=^[A-Z]{2}$]
+	bracketPattern := regexp.MustCompile(`\[((?:[^\[\]]|\[[^\[\]]*\])+)\]`)
 	bracketMatches := bracketPattern.FindAllStringSubmatch(desc, -1)
 
 	for _, match := range bracketMatches {
 		flagContent := match[1]
 		flags := strings.Split(flagContent, ",")
 
-		for _, flag := range flags {
-			flag = strings.TrimSpace(flag)
+		for i := 0; i < len(flags); i++ {
+			flag := strings.TrimSpace(flags[i])
 			flagLower := strings.ToLower(flag)
 
 			if flagLower == "mandatory" {
 				claim.Mandatory = true
+			} else if flagLower == "hidden" {
+				claim.Hidden = true
 			} else if strings.HasPrefix(flagLower, "sd=") {
 				claim.SD = strings.TrimPrefix(flagLower, "sd=")
 			} else if strings.HasPrefix(flagLower, "svg_id=") {
 				claim.SvgId = strings.TrimPrefix(flag, "svg_id=")
+			} else if strings.HasPrefix(flagLower, "group=") {
+				claim.Group = strings.TrimSpace(flag[len("group="):])
+			} else if strings.HasPrefix(flagLower, "const=") {
+				claim.Const = strings.TrimSpace(flag[len("const="):])
+			} else if strings.HasPrefix(flagLower, "examples=") {
+				// Multiple examples are separated by pipes: [examples=a|b|c]
+				for _, example := range strings.Split(flag[len("examples="):], "|") {
+					if example = strings.TrimSpace(example); example != "" {
+						claim.Examples = append(claim.Examples, example)
+					}
+				}
+			} else if strings.HasPrefix(flagLower, "example=") {
+				claim.Example = strings.TrimSpace(flag[len("example="):])
+			} else if strings.HasPrefix(flagLower, "enum=") {
+				// Allowed values are separated by pipes: [enum=SE|DE|FR]
+				for _, value := range strings.Split(flag[len("enum="):], "|") {
+					if value = strings.TrimSpace(value); value != "" {
+						claim.Enum = append(claim.Enum, value)
+					}
+				}
+			} else if strings.HasPrefix(flagLower, "min=") {
+				claim.Min = strings.TrimSpace(flag[len("min="):])
+			} else if strings.HasPrefix(flagLower, "max=") {
+				claim.Max = strings.TrimSpace(flag[len("max="):])
+			} else if strings.HasPrefix(flagLower, "order=") {
+				claim.Order = strings.TrimSpace(flag[len("order="):])
+			} else if strings.HasPrefix(flagLower, "pattern=") {
+				// A pattern may contain commas, e.g. \d{1,3}, so it takes the rest of the group
+				claim.Pattern = strings.TrimSpace(strings.Join(flags[i:], ","))[len("pattern="):]
+				break
+			} else if flag != "" {
+				claim.UnknownFlags = append(claim.UnknownFlags, flag)
 			}
 		}
 	}
@@ -686,3 +1673,10 @@ func CalculateIntegrity(path string) (string, error) {
 	p := &Parser{}
 	return p.calculateIntegrity(path)
 }
+
+// CalculateIntegrityBytes calculates the SRI integrity hash (sha256) of data, such
+// as a generated document before or after it is written
+func CalculateIntegrityBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -1,11 +1,15 @@
 package parser
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/sirosfoundation/mtcvctm/pkg/config"
+	"github.com/sirosfoundation/mtcvctm/pkg/vctm"
 )
 
 func TestParser_ParseContent(t *testing.T) {
@@ -59,6 +63,77 @@ A detailed description of the identity credential.
 	}
 }
 
+func TestParser_ParseContent_MultiParagraphDescription(t *testing.T) {
+	cfg := &config.Config{
+		Language: "en-US",
+		BaseURL:  "https://example.com",
+	}
+	p := NewParser(cfg)
+
+	content := []byte(`# Identity Credential
+
+![Logo](https://example.com/logo.png)
+
+This is a credential for identity verification.
+
+It is issued by the national registry
+to adult residents.
+
+- ` + "`nickname`" + ` (string): A nickname
+
+## Claims
+
+- ` + "`given_name`" + ` (string): The given name
+`)
+
+	parsed, err := p.ParseContent(content, "/test/credential.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+
+	want := "This is a credential for identity verification.\n\nIt is issued by the national registry to adult residents."
+	if parsed.Description != want {
+		t.Errorf("Description = %q, want %q", parsed.Description, want)
+	}
+	if len(parsed.Images) != 1 || parsed.Images[0].AltText != "Logo" {
+		t.Errorf("Images = %+v, want the logo", parsed.Images)
+	}
+	if _, ok := parsed.Claims["given_name"]; !ok {
+		t.Error("Missing given_name claim")
+	}
+	if _, ok := parsed.Claims["nickname"]; ok {
+		t.Error("list in the preamble parsed as a claim")
+	}
+}
+
+func TestParser_ParseContent_InlineMarkupDescription(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"emphasis", "See *our* **very *own*** rules.", "See our very own rules."},
+		{"link", "See *our* [privacy policy](https://example.com/policy).", "See our privacy policy (https://example.com/policy)."},
+		{"link with URL text", "See [https://example.com](https://example.com).", "See https://example.com."},
+		{"autolink", "Details at <https://example.com/docs> and <help@example.com>.", "Details at https://example.com/docs and help@example.com."},
+		{"code span", "Uses the `given_name`  claim.", "Uses the `given_name` claim."},
+		{"line break", "Issued by *the\nregistry*   office.", "Issued by the registry office."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(&config.Config{Language: "en-US"})
+			parsed, err := p.ParseContent([]byte("# Credential\n\n"+tt.markdown+"\n"), "/test/credential.md")
+			if err != nil {
+				t.Fatalf("ParseContent() error = %v", err)
+			}
+			if parsed.Description != tt.want {
+				t.Errorf("Description = %q, want %q", parsed.Description, tt.want)
+			}
+		})
+	}
+}
+
 func TestParser_ParseContent_WithFrontMatter(t *testing.T) {
 	cfg := &config.Config{
 		Language: "en-US",
@@ -92,6 +167,38 @@ This is a test credential.
 	}
 }
 
+func TestParser_ParseContent_CredentialSchema(t *testing.T) {
+	p := NewParser(&config.Config{Language: "en-US"})
+
+	content := []byte(`---
+credential_schema:
+  id: https://example.com/schemas/identity.json
+  type: JsonSchema
+  path: ./identity.schema.json
+---
+
+# Identity Credential
+`)
+
+	parsed, err := p.ParseContent(content, "/test/credential.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+
+	ref := parsed.CredentialSchema
+	if ref == nil {
+		t.Fatal("CredentialSchema should be parsed from front matter")
+	}
+	if ref.ID != "https://example.com/schemas/identity.json" || ref.Type != "JsonSchema" || ref.Path != "./identity.schema.json" {
+		t.Errorf("CredentialSchema = %+v", ref)
+	}
+
+	cred := p.ToCredential(parsed)
+	if cred.CredentialSchema == nil || cred.CredentialSchema.ID != ref.ID {
+		t.Errorf("ToCredential() CredentialSchema = %+v", cred.CredentialSchema)
+	}
+}
+
 func TestParser_ToVCTM(t *testing.T) {
 	cfg := &config.Config{
 		Language:  "en-US",
@@ -153,6 +260,213 @@ func TestParser_ToVCTM(t *testing.T) {
 	}
 }
 
+func TestParser_ToVCTM_SchemaURI(t *testing.T) {
+	p := NewParser(&config.Config{Language: "en-US"})
+
+	content := "---\nvct: urn:test\nschema_uri: https://example.com/identity.schema.json\nschema_uri#integrity: sha256-abc\n---\n\n# Identity\n"
+	parsed, err := p.ParseContent([]byte(content), "/test/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+	vctmDoc, err := p.ToVCTM(parsed)
+	if err != nil {
+		t.Fatalf("ToVCTM() error = %v", err)
+	}
+	if vctmDoc.SchemaURI != "https://example.com/identity.schema.json" || vctmDoc.SchemaURIIntegrity != "sha256-abc" {
+		t.Errorf("SchemaURI = %q, SchemaURIIntegrity = %q", vctmDoc.SchemaURI, vctmDoc.SchemaURIIntegrity)
+	}
+
+	content = "---\nvct: urn:test\nschema: '{\"type\": \"object\"}'\nschema_uri: https://example.com/identity.schema.json\n---\n\n# Identity\n"
+	parsed, err = p.ParseContent([]byte(content), "/test/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+	vctmDoc, err = p.ToVCTM(parsed)
+	if err != nil {
+		t.Fatalf("ToVCTM() error = %v", err)
+	}
+	if string(vctmDoc.Schema) != `{"type": "object"}` {
+		t.Errorf("Schema = %s", vctmDoc.Schema)
+	}
+	if _, err := vctmDoc.ToJSON(); err == nil {
+		t.Error("ToJSON() should reject schema together with schema_uri")
+	}
+}
+
+func TestParser_ParseContent_SDValues(t *testing.T) {
+	p := NewParser(&config.Config{Language: "en-US"})
+
+	tests := []struct {
+		name    string
+		flag    string
+		want    string
+		wantErr bool
+	}{
+		{name: "allowed", flag: "sd=allowed", want: "allowed"},
+		{name: "case-insensitive", flag: "sd=Never", want: "never"},
+		{name: "invalid", flag: "sd=alway", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "# Identity\n\n## Claims\n\n- `given_name` (string): Given name [" + tt.flag + "]\n"
+			parsed, err := p.ParseContent([]byte(content), "/test/identity.md")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ParseContent() should reject an invalid sd value")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseContent() error = %v", err)
+			}
+			vctmDoc, err := p.ToVCTM(parsed)
+			if err != nil {
+				t.Fatalf("ToVCTM() error = %v", err)
+			}
+			if len(vctmDoc.Claims) != 1 || vctmDoc.Claims[0].SD != tt.want {
+				t.Errorf("Claims = %+v, want sd %q", vctmDoc.Claims, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_ParseContent_Warnings(t *testing.T) {
+	p := NewParser(&config.Config{Language: "en-US"})
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "duplicate claim",
+			content: "# Identity\n\n## Claims\n\n- `given_name` (string): Given name\n- `given_name` (string): First name\n",
+			want:    "duplicate claim given_name at line 6, keeping the definition at line 5",
+		},
+		{
+			name:    "unmatched list item",
+			content: "# Identity\n\n## Claims\n\n- `given_name` (string): Given name\n- family_name (string): Family name\n",
+			want:    `list item "family_name (string): Family name" in the claims section is not a claim definition`,
+		},
+		{
+			name:    "unknown front matter key",
+			content: "---\nvct: urn:test\nbackgroud_color: \"#fff\"\n---\n\n# Identity\n",
+			want:    "unknown front matter key backgroud_color",
+		},
+		{
+			name:    "missing image",
+			content: "# Identity\n\n![Logo](missing.png)\n",
+			want:    "image missing.png not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := p.ParseContent([]byte(tt.content), "/nonexistent/identity.md")
+			if err != nil {
+				t.Fatalf("ParseContent() error = %v", err)
+			}
+			if !reflect.DeepEqual(parsed.Warnings, []string{tt.want}) {
+				t.Errorf("Warnings = %q, want [%q]", parsed.Warnings, tt.want)
+			}
+			if cred := p.ToCredential(parsed); len(cred.Warnings) == 0 {
+				t.Error("ToCredential() should carry the parser warnings")
+			}
+		})
+	}
+
+	// Localization sub-items are not reported
+	content := "# Identity\n\n## Claims\n\n- `given_name` (string): Given name\n  - de-DE: \"Vorname\" - Der Vorname\n"
+	parsed, err := p.ParseContent([]byte(content), "/nonexistent/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+	if len(parsed.Warnings) != 0 {
+		t.Errorf("Warnings = %q, want none", parsed.Warnings)
+	}
+}
+
+func TestParser_ParseContent_DuplicateClaims(t *testing.T) {
+	p := NewParser(&config.Config{Language: "en-US"})
+
+	content := "# Identity\n\n## Claims\n\n" +
+		"- `address` (object): Address\n" +
+		"  - `street` (string): Street name [mandatory]\n" +
+		"  - `street` (string): Street line\n" +
+		"\n" +
+		"| Claim | Type | Description |\n" +
+		"|-------|------|-------------|\n" +
+		"| `given_name` | string | Given name |\n" +
+		"| `given_name` | string | First name |\n"
+	parsed, err := p.ParseContent([]byte(content), "/test/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+
+	want := []string{
+		"duplicate claim address.street at line 7, keeping the definition at line 6",
+		"duplicate claim given_name at line 12, keeping the definition at line 11",
+	}
+	if !reflect.DeepEqual(parsed.Warnings, want) {
+		t.Errorf("Warnings = %q, want %q", parsed.Warnings, want)
+	}
+	if street := parsed.Claims["address.street"]; street.Description != "Street name" || !street.Mandatory {
+		t.Errorf("address.street = %+v, want the first definition", street)
+	}
+
+	// A claim group heading repeating a list claim is reported the same way
+	content = "# Identity\n\n## Claims\n\n" +
+		"- `address` (object): Postal address\n\n" +
+		"### `address` (object)\n\n" +
+		"- `street` (string): Street\n"
+	parsed, err = p.ParseContent([]byte(content), "/test/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+	want = []string{"duplicate claim address at line 7, keeping the definition at line 5"}
+	if !reflect.DeepEqual(parsed.Warnings, want) {
+		t.Errorf("Warnings = %q, want %q", parsed.Warnings, want)
+	}
+	if address := parsed.Claims["address"]; address.Description != "Postal address" {
+		t.Errorf("address = %+v, want the list definition", address)
+	}
+	if _, ok := parsed.Claims["address.street"]; !ok {
+		t.Error("address.street from the group heading is missing")
+	}
+}
+
+func TestParser_ToVCTM_SDPolicy(t *testing.T) {
+	p := NewParser(&config.Config{
+		Language: "en-US",
+		SDPolicy: &config.SDPolicy{Mandatory: "never", Optional: "allowed"},
+	})
+
+	content := "---\nvct: urn:test\n---\n\n# Identity\n\n## Claims\n\n" +
+		"- `given_name` (string): Given name [mandatory]\n" +
+		"- `nickname` (string): Nickname\n" +
+		"- `birth_date` (date): Date of birth [mandatory, sd=always]\n"
+	parsed, err := p.ParseContent([]byte(content), "/test/identity.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+	vctmDoc, err := p.ToVCTM(parsed)
+	if err != nil {
+		t.Fatalf("ToVCTM() error = %v", err)
+	}
+
+	want := map[string]string{"given_name": "never", "nickname": "allowed", "birth_date": "always"}
+	for _, claim := range vctmDoc.Claims {
+		name, _ := claim.Path[0].(string)
+		if claim.SD != want[name] {
+			t.Errorf("claim %s sd = %q, want %q", name, claim.SD, want[name])
+		}
+	}
+	if len(vctmDoc.Claims) != len(want) {
+		t.Errorf("got %d claims, want %d", len(vctmDoc.Claims), len(want))
+	}
+}
+
 func TestParser_ToVCTM_WithCredentialLocalizations(t *testing.T) {
 	cfg := &config.Config{
 		Language:  "en-US",
@@ -353,6 +667,314 @@ func TestParseClaimFromListItem(t *testing.T) {
 	}
 }
 
+func TestParseClaimFromListItem_Const(t *testing.T) {
+	claim := parseClaimFromListItem("`credential_type` (string): Type of credential [mandatory, const=StudentCard]")
+	if claim == nil {
+		t.Fatal("Expected match but got nil")
+	}
+	if claim.Const != "StudentCard" {
+		t.Errorf("Const = %q, want StudentCard", claim.Const)
+	}
+	if !claim.Mandatory {
+		t.Error("Mandatory should still be parsed alongside const")
+	}
+	if claim.Description != "Type of credential" {
+		t.Errorf("Description = %q", claim.Description)
+	}
+}
+
+func TestParseClaimFromListItem_Examples(t *testing.T) {
+	claim := parseClaimFromListItem("`age` (integer): Age in years [example=42, examples=18|21 | 65]")
+	if claim == nil {
+		t.Fatal("Expected match but got nil")
+	}
+	if claim.Example != "42" {
+		t.Errorf("Example = %q, want 42", claim.Example)
+	}
+	want := []string{"18", "21", "65"}
+	if len(claim.Examples) != len(want) {
+		t.Fatalf("Examples = %v, want %v", claim.Examples, want)
+	}
+	for i := range want {
+		if claim.Examples[i] != want[i] {
+			t.Errorf("Examples[%d] = %q, want %q", i, claim.Examples[i], want[i])
+		}
+	}
+	if claim.Description != "Age in years" {
+		t.Errorf("Description = %q", claim.Description)
+	}
+}
+
+func TestParseClaimFromListItem_Constraints(t *testing.T) {
+	tests := []struct {
+		text        string
+		wantEnum    []string
+		wantMin     string
+		wantMax     string
+		wantPattern string
+	}{
+		{text: "`age` (integer): Age [min=0, max=120]", wantMin: "0", wantMax: "120"},
+		{text: "`country` (string): Country [mandatory, enum=SE|DE | FR]", wantEnum: []string{"SE", "DE", "FR"}},
+		{text: "`postal_code` (string): [pattern=^\\d{5}$]", wantPattern: `^\d{5}$`},
+		{text: "`code` (string): Code [sd=always, pattern=^[A-Z]{2}\\d{1,3}$]", wantPattern: `^[A-Z]{2}\d{1,3}$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			claim := parseClaimFromListItem(tt.text)
+			if claim == nil {
+				t.Fatal("Expected match but got nil")
+			}
+			if strings.Join(claim.Enum, "|") != strings.Join(tt.wantEnum, "|") {
+				t.Errorf("Enum = %v, want %v", claim.Enum, tt.wantEnum)
+			}
+			if claim.Min != tt.wantMin || claim.Max != tt.wantMax {
+				t.Errorf("Min, Max = %q, %q, want %q, %q", claim.Min, claim.Max, tt.wantMin, tt.wantMax)
+			}
+			if claim.Pattern != tt.wantPattern {
+				t.Errorf("Pattern = %q, want %q", claim.Pattern, tt.wantPattern)
+			}
+			if len(claim.UnknownFlags) > 0 {
+				t.Errorf("UnknownFlags = %v", claim.UnknownFlags)
+			}
+			if strings.Contains(claim.Description, "[") {
+				t.Errorf("Description = %q, flags not stripped", claim.Description)
+			}
+		})
+	}
+}
+
+func TestParser_ParseContent_InvalidConstraints(t *testing.T) {
+	tests := []struct {
+		flags   string
+		wantErr string
+	}{
+		{"[min=zero]", `invalid min value "zero"`},
+		{"[max=]", ""},
+		{"[min=10, max=5]", "min 10 greater than max 5"},
+		{"[const=18, enum=18|21]", "both const and enum values"},
+		{"[const=18, min=10]", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flags, func(t *testing.T) {
+			content := "# Test\n\n## Claims\n\n- `age` (integer): Age " + tt.flags + "\n"
+			_, err := NewParser(config.DefaultConfig()).ParseContent([]byte(content), "test.md")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ParseContent() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseContent() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParser_ParseContent_InvalidOrder(t *testing.T) {
+	for _, order := range []string{"first", "0", "-1"} {
+		t.Run(order, func(t *testing.T) {
+			content := "# Test\n\n## Claims\n\n- `age` (integer): Age [order=" + order + "]\n"
+			_, err := NewParser(config.DefaultConfig()).ParseContent([]byte(content), "test.md")
+			want := fmt.Sprintf("invalid order value %q", order)
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("ParseContent() error = %v, want %q", err, want)
+			}
+		})
+	}
+}
+
+func TestIsClaimsHeading(t *testing.T) {
+	tests := []struct {
+		heading string
+		want    bool
+	}{
+		{"Claims", true},
+		{"Claims:", true},
+		{"Claims (v2)", true},
+		{"CLAIMS", true},
+		{"  Claims (draft) : ", true},
+		{"Data Elements", true},
+		{"Claims Structure Comparison", false},
+		{"Usage", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.heading, func(t *testing.T) {
+			if got := isClaimsHeading(tt.heading); got != tt.want {
+				t.Errorf("isClaimsHeading(%q) = %v, want %v", tt.heading, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseContent_ClaimsHeadingVariants(t *testing.T) {
+	for _, heading := range []string{"## Claims:", "## Claims (v2)", "## CLAIMS"} {
+		t.Run(heading, func(t *testing.T) {
+			p := NewParser(config.DefaultConfig())
+			content := []byte("# Test\n\nA test.\n\n" + heading + `
+
+- ` + "`given_name`" + ` (string): Given name
+
+### Address
+
+- ` + "`address.street`" + ` (string): Street
+
+## Usage
+
+- ` + "`not_a_claim`" + ` (string): Listed outside the claims section
+`)
+
+			parsed, err := p.ParseContent(content, "/test/credential.md")
+			if err != nil {
+				t.Fatalf("ParseContent() error = %v", err)
+			}
+			if _, ok := parsed.Claims["given_name"]; !ok {
+				t.Errorf("given_name not recognized under %q", heading)
+			}
+			if _, ok := parsed.Claims["address.street"]; !ok {
+				t.Error("claims under a sub-heading of the claims section should be recognized")
+			}
+			if _, ok := parsed.Claims["not_a_claim"]; ok {
+				t.Error("lists outside the claims section should be ignored")
+			}
+		})
+	}
+}
+
+func TestParseContent_ClaimsSectionPolicy(t *testing.T) {
+	content := []byte("# Test\n\nA test.\n\n- `given_name` (string): Given name\n\n## Usage\n\n- `birth_date` (date): Birth date\n")
+
+	tests := []struct {
+		policy     string
+		wantClaims int
+		wantWarn   bool
+	}{
+		{"", 2, true},
+		{config.ClaimsSectionLenient, 2, true},
+		{config.ClaimsSectionStrict, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run("policy="+tt.policy, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.ClaimsSection = tt.policy
+			parsed, err := NewParser(cfg).ParseContent(content, "/test/credential.md")
+			if err != nil {
+				t.Fatalf("ParseContent() error = %v", err)
+			}
+			if len(parsed.Claims) != tt.wantClaims {
+				t.Errorf("Claims = %v, want %d", parsed.Claims, tt.wantClaims)
+			}
+			warned := len(parsed.Warnings) == 1 && strings.Contains(parsed.Warnings[0], "deprecated")
+			if warned != tt.wantWarn {
+				t.Errorf("Warnings = %v, want deprecation warning: %v", parsed.Warnings, tt.wantWarn)
+			}
+		})
+	}
+
+	// With a claims section, neither policy reads lists before it
+	sectioned := []byte("# Test\n\n- `stray` (string): Stray\n\n## Claims\n\n- `given_name` (string): Given name\n")
+	for _, policy := range []string{config.ClaimsSectionLenient, config.ClaimsSectionStrict} {
+		cfg := config.DefaultConfig()
+		cfg.ClaimsSection = policy
+		parsed, err := NewParser(cfg).ParseContent(sectioned, "/test/credential.md")
+		if err != nil {
+			t.Fatalf("ParseContent() error = %v", err)
+		}
+		if _, ok := parsed.Claims["stray"]; ok || len(parsed.Claims) != 1 || len(parsed.Warnings) != 0 {
+			t.Errorf("%s: Claims = %v, Warnings = %v, want only given_name", policy, parsed.Claims, parsed.Warnings)
+		}
+	}
+}
+
+func TestParseContent_ClaimsTable(t *testing.T) {
+	p := NewParser(config.DefaultConfig())
+
+	table := []byte(`# Test
+
+## Claims
+
+| Claim | Type | Label | Description | Mandatory | SD | SVG ID |
+|-------|------|-------|-------------|-----------|----|--------|
+| ` + "`given_name`" + ` | string | Given Name | The given name | yes | always | given |
+| ` + "`birth_date`" + ` | date | | Date of birth | | never | |
+| ` + "`nickname`" + ` | | | | no | | |
+
+## Notes
+
+| Term | Meaning |
+|------|---------|
+| sd | selective disclosure |
+`)
+
+	list := []byte("# Test\n\n## Claims\n\n" +
+		"- `given_name` \"Given Name\" (string): The given name [mandatory, sd=always, svg_id=given]\n" +
+		"- `birth_date` (date): Date of birth [sd=never]\n" +
+		"- `nickname`\n")
+
+	fromTable, err := p.ParseContent(table, "/test/credential.md")
+	if err != nil {
+		t.Fatalf("ParseContent(table) error = %v", err)
+	}
+	fromList, err := p.ParseContent(list, "/test/credential.md")
+	if err != nil {
+		t.Fatalf("ParseContent(list) error = %v", err)
+	}
+
+	if len(fromTable.Claims) != 3 {
+		t.Fatalf("table claims = %v, want 3", fromTable.Claims)
+	}
+	if !reflect.DeepEqual(fromTable.Claims, fromList.Claims) {
+		t.Errorf("table claims differ from list claims:\ntable: %+v\nlist:  %+v", fromTable.Claims, fromList.Claims)
+	}
+	if !reflect.DeepEqual(fromTable.ClaimOrder, fromList.ClaimOrder) {
+		t.Errorf("table claim order = %v, want %v", fromTable.ClaimOrder, fromList.ClaimOrder)
+	}
+}
+
+func TestParseContent_ClaimDescriptionFromParagraph(t *testing.T) {
+	p := NewParser(config.DefaultConfig())
+	content := []byte(`# Test
+
+A test.
+
+## Claims
+
+- ` + "`given_name`" + ` (string)
+
+  The given name of the holder,
+  as recorded in the civil registry.
+
+  Includes middle names.
+
+  - de-DE: "Vorname" - Der Vorname
+
+- ` + "`family_name`" + ` (string): Family name
+
+  Additional prose that does not replace the inline description.
+`)
+
+	parsed, err := p.ParseContent(content, "/test/credential.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+
+	givenName := parsed.Claims["given_name"]
+	want := "The given name of the holder, as recorded in the civil registry.\n\nIncludes middle names."
+	if givenName.Description != want {
+		t.Errorf("given_name Description = %q, want %q", givenName.Description, want)
+	}
+	if givenName.Localizations["de-DE"].Label != "Vorname" {
+		t.Errorf("given_name localizations = %v, want de-DE label", givenName.Localizations)
+	}
+	if got := parsed.Claims["family_name"].Description; got != "Family name" {
+		t.Errorf("family_name Description = %q, want inline description", got)
+	}
+}
+
 func TestExtractFrontMatter(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -498,6 +1120,23 @@ func TestCalculateIntegrity(t *testing.T) {
 	}
 }
 
+func TestCalculateIntegrityBytes(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.txt")
+	data := []byte("test content")
+	if err := os.WriteFile(testFile, data, 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	fromFile, err := CalculateIntegrity(testFile)
+	if err != nil {
+		t.Fatalf("CalculateIntegrity() error = %v", err)
+	}
+	if got := CalculateIntegrityBytes(data); got != fromFile {
+		t.Errorf("CalculateIntegrityBytes() = %q, want %q", got, fromFile)
+	}
+}
+
 func TestCalculateIntegrity_NotFound(t *testing.T) {
 	_, err := CalculateIntegrity("/nonexistent/file.txt")
 	if err == nil {
@@ -505,6 +1144,49 @@ func TestCalculateIntegrity_NotFound(t *testing.T) {
 	}
 }
 
+func TestParser_ParseContent_ReferenceAndHTMLImages(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"logo.png", "card.png", "seal.png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("png"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := NewParser(&config.Config{Language: "en-US"})
+
+	content := []byte(`# Identity Credential
+
+<img src="card.png" alt="Card &amp; holder">
+
+A credential for identity verification.
+
+![Logo][logo]
+
+Sealed by the issuer <img src='seal.png' alt=Seal>
+
+[logo]: logo.png
+`)
+
+	parsed, err := p.ParseContent(content, filepath.Join(dir, "credential.md"))
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+
+	want := []ImageRef{
+		{Path: "card.png", AltText: "Card & holder", AbsolutePath: filepath.Join(dir, "card.png")},
+		{Path: "logo.png", AltText: "Logo", AbsolutePath: filepath.Join(dir, "logo.png")},
+		{Path: "seal.png", AltText: "Seal", AbsolutePath: filepath.Join(dir, "seal.png")},
+	}
+	if !reflect.DeepEqual(parsed.Images, want) {
+		t.Errorf("Images = %+v, want %+v", parsed.Images, want)
+	}
+	if len(parsed.Warnings) != 0 {
+		t.Errorf("Warnings = %v, want none", parsed.Warnings)
+	}
+	if want := "A credential for identity verification.\n\nSealed by the issuer"; parsed.Description != want {
+		t.Errorf("Description = %q, want %q", parsed.Description, want)
+	}
+}
+
 func TestParser_buildImageURL(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -538,7 +1220,7 @@ func TestParser_buildImageURL(t *testing.T) {
 				BaseURL: tt.baseURL,
 			}
 			p := NewParser(cfg)
-			got := p.buildImageURL(tt.path)
+			got := p.buildImageURL(tt.path, "")
 			if got != tt.want {
 				t.Errorf("buildImageURL() = %q, want %q", got, tt.want)
 			}
@@ -643,33 +1325,6 @@ func TestParser_imageToLogo_Inline(t *testing.T) {
 	}
 }
 
-func TestGetMimeType(t *testing.T) {
-	tests := []struct {
-		path string
-		want string
-	}{
-		{"/path/to/image.png", "image/png"},
-		{"/path/to/image.PNG", "image/png"},
-		{"/path/to/image.svg", "image/svg+xml"},
-		{"/path/to/image.SVG", "image/svg+xml"},
-		{"/path/to/image.jpg", "image/jpeg"},
-		{"/path/to/image.jpeg", "image/jpeg"},
-		{"/path/to/image.gif", "image/gif"},
-		{"/path/to/image.webp", "image/webp"},
-		{"/path/to/image.ico", "image/x-icon"},
-		{"/path/to/unknown.xyz", "application/octet-stream"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.path, func(t *testing.T) {
-			got := getMimeType(tt.path)
-			if got != tt.want {
-				t.Errorf("getMimeType(%q) = %q, want %q", tt.path, got, tt.want)
-			}
-		})
-	}
-}
-
 func TestParseLocalizationFromListItem(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -811,6 +1466,44 @@ A test credential.
 	}
 }
 
+func TestParser_ToVCTM_LocalizedClaimFallback(t *testing.T) {
+	cfg := &config.Config{
+		Language: "en-US",
+		BaseURL:  "https://example.com",
+	}
+	p := NewParser(cfg)
+
+	content := []byte(`# Test Credential
+
+A test credential.
+
+## Claims
+
+- ` + "`given_name` \"Given Name\" (string): The given name" + `
+  - de-DE: "Vorname"
+`)
+
+	parsed, err := p.ParseContent(content, "/test/credential.md")
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+	vctmDoc, err := p.ToVCTM(parsed)
+	if err != nil {
+		t.Fatalf("ToVCTM() error = %v", err)
+	}
+	if len(vctmDoc.Claims) != 1 {
+		t.Fatalf("Expected 1 claim, got %d", len(vctmDoc.Claims))
+	}
+
+	want := []vctm.ClaimDisplay{
+		{Locale: "en-US", Label: "Given Name", Description: "The given name"},
+		{Locale: "de-DE", Label: "Vorname", Description: "The given name"},
+	}
+	if !reflect.DeepEqual(vctmDoc.Claims[0].Display, want) {
+		t.Errorf("Display = %+v, want %+v", vctmDoc.Claims[0].Display, want)
+	}
+}
+
 func TestParser_imageToLogo_URLBased(t *testing.T) {
 	// Test imageToLogo when InlineImages is false (URL-based)
 	tmpDir := t.TempDir()
@@ -931,7 +1624,7 @@ func TestParser_buildRendering_WithSVG(t *testing.T) {
 }
 
 func TestParser_buildRendering_NoBaseURL(t *testing.T) {
-	// When no BaseURL and there are images, should return nil
+	// When no BaseURL, inlining is disabled and there are images, should return nil
 	cfg := &config.Config{
 		BaseURL: "",
 	}
@@ -951,6 +1644,47 @@ func TestParser_buildRendering_NoBaseURL(t *testing.T) {
 	}
 }
 
+func TestParser_ToVCTM_InlineLogoWithoutBaseURL(t *testing.T) {
+	tmpDir := t.TempDir()
+	// Minimal PNG signature is enough for content type detection
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if err := os.WriteFile(filepath.Join(tmpDir, "logo.png"), png, 0644); err != nil {
+		t.Fatal(err)
+	}
+	mdPath := filepath.Join(tmpDir, "credential.md")
+	if err := os.WriteFile(mdPath, []byte("# Test\n\nA test.\n\n![Logo](logo.png)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		InputFile:    mdPath,
+		VCT:          "urn:test",
+		Language:     "en-US",
+		InlineImages: true,
+	}
+	p := NewParser(cfg)
+
+	parsed, err := p.Parse(mdPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	v, err := p.ToVCTM(parsed)
+	if err != nil {
+		t.Fatalf("ToVCTM() error = %v", err)
+	}
+
+	if len(v.Display) == 0 || v.Display[0].Rendering == nil || v.Display[0].Rendering.Simple == nil || v.Display[0].Rendering.Simple.Logo == nil {
+		t.Fatalf("logo dropped without base URL: %+v", v.Display)
+	}
+	logo := v.Display[0].Rendering.Simple.Logo
+	if !hasPrefix(logo.URI, "data:image/png;base64,") {
+		t.Errorf("logo URI = %q, want data: URI", logo.URI)
+	}
+	if logo.AltText != "Logo" {
+		t.Errorf("logo AltText = %q, want Logo", logo.AltText)
+	}
+}
+
 func TestParser_buildRendering_NoContent(t *testing.T) {
 	cfg := &config.Config{
 		BaseURL: "https://example.com",
@@ -4,7 +4,10 @@ package vctm
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"regexp"
 )
 
 // VCTM represents a Verifiable Credential Type Metadata document
@@ -25,10 +28,22 @@ type VCTM struct {
 	// ExtendsIntegrity is the integrity hash for the extended type metadata document
 	ExtendsIntegrity string `json:"extends#integrity,omitempty"`
 
+	// Schema is an embedded JSON Schema for the credential; it must not be used
+	// together with SchemaURI
+	Schema json.RawMessage `json:"schema,omitempty"`
+
+	// SchemaURI is a URI of a JSON Schema for the credential
+	SchemaURI string `json:"schema_uri,omitempty"`
+
+	// SchemaURIIntegrity is the integrity hash for the schema_uri document
+	SchemaURIIntegrity string `json:"schema_uri#integrity,omitempty"`
+
 	// Display contains display properties in different locales
 	Display []DisplayProperties `json:"display,omitempty"`
 
-	// Claims contains the metadata about claims in the credential (array with path)
+	// Claims contains the metadata about claims in the credential. The draft-12 array
+	// with path is the only representation; the vctm generator's --vctm-draft 11
+	// object keyed by name is produced from the format layer, not this type.
 	Claims []ClaimMetadataEntry `json:"claims,omitempty"`
 }
 
@@ -88,6 +103,9 @@ type ClaimMetadataEntry struct {
 
 	// SvgId is the ID of the claim for reference in SVG templates
 	SvgId string `json:"svg_id,omitempty"`
+
+	// Group is a non-standard UI grouping hint, namespaced as an extra
+	Group string `json:"x-group,omitempty"`
 }
 
 // ClaimDisplay contains locale-specific display information for a claim
@@ -109,6 +127,10 @@ type Rendering struct {
 
 	// SVGTemplates contains SVG template rendering information
 	SVGTemplates []SVGTemplate `json:"svg_templates,omitempty"`
+
+	// ColorSchemes holds simple rendering variants keyed by color scheme ("light" or
+	// "dark"); Simple carries the light variant for wallets that ignore this extension
+	ColorSchemes map[string]*SimpleRendering `json:"x-color_schemes,omitempty"`
 }
 
 // SimpleRendering contains simple rendering properties
@@ -150,14 +172,141 @@ type SVGTemplateProperties struct {
 	Contrast string `json:"contrast,omitempty"`
 }
 
-// Validate checks if the VCTM document is valid
-func (v *VCTM) Validate() error {
-	if v.VCT == "" {
-		return fmt.Errorf("vctm: vct field is required")
+// languageTagPattern matches the syntax of a BCP 47 language tag (RFC 5646 langtag or private use)
+var languageTagPattern = regexp.MustCompile(`(?i)^(?:` +
+	`[a-z]{2,3}(?:-[a-z]{3}){0,3}|[a-z]{4,8})` + // language and extlang
+	`(?:-[a-z]{4})?` + // script
+	`(?:-(?:[a-z]{2}|[0-9]{3}))?` + // region
+	`(?:-(?:[a-z0-9]{5,8}|[0-9][a-z0-9]{3}))*` + // variants
+	`(?:-[a-wyz0-9](?:-[a-z0-9]{2,8})+)*` + // extensions
+	`(?:-x(?:-[a-z0-9]{1,8})+)?$` + // private use
+	`|^x(?:-[a-z0-9]{1,8})+$`)
+
+// IsLanguageTag reports whether tag is a syntactically valid BCP 47 language tag
+func IsLanguageTag(tag string) bool {
+	return languageTagPattern.MatchString(tag)
+}
+
+// validSD lists the allowed selective disclosure values
+var validSD = map[string]bool{"always": true, "allowed": true, "never": true}
+
+// IsValidSD reports whether sd is an allowed selective disclosure value
+// (always, allowed or never)
+func IsValidSD(sd string) bool {
+	return validSD[sd]
+}
+
+// ValidateLocale checks that locale is present and a valid BCP 47 language tag
+func ValidateLocale(locale string) error {
+	switch {
+	case locale == "":
+		return errors.New("locale is required")
+	case !IsLanguageTag(locale):
+		return fmt.Errorf("invalid locale %q", locale)
+	}
+	return nil
+}
+
+// ValidateSD checks that sd is empty or an allowed selective disclosure value
+func ValidateSD(sd string) error {
+	if sd != "" && !validSD[sd] {
+		return fmt.Errorf("invalid sd %q (want always, allowed or never)", sd)
 	}
 	return nil
 }
 
+// MemberError is a problem with a top-level member of a VCTM document
+type MemberError struct {
+	// Member is the JSON name of the offending member
+	Member string
+
+	// Err describes the problem
+	Err error
+}
+
+func (e *MemberError) Error() string {
+	return "vctm: " + e.Err.Error()
+}
+
+func (e *MemberError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateMembers checks the top-level members of the document: vct must be
+// present and integrity and schema members must be used consistently
+func (v *VCTM) ValidateMembers() []*MemberError {
+	var errs []*MemberError
+	add := func(member, message string) {
+		errs = append(errs, &MemberError{Member: member, Err: errors.New(message)})
+	}
+	if v.VCT == "" {
+		add("vct", "vct field is required")
+	}
+	if v.ExtendsIntegrity != "" && v.Extends == "" {
+		add("extends#integrity", "extends#integrity requires extends")
+	}
+	if len(v.Schema) > 0 && v.SchemaURI != "" {
+		add("schema_uri", "schema and schema_uri must not both be present")
+	}
+	if v.SchemaURIIntegrity != "" && v.SchemaURI == "" {
+		add("schema_uri#integrity", "schema_uri#integrity requires schema_uri")
+	}
+	return errs
+}
+
+// Validate checks if the VCTM document is valid. All problems found are
+// returned joined into a single error.
+func (v *VCTM) Validate() error {
+	var errs []error
+	for _, err := range v.ValidateMembers() {
+		errs = append(errs, err)
+	}
+
+	for i, display := range v.Display {
+		if err := ValidateLocale(display.Locale); err != nil {
+			errs = append(errs, fmt.Errorf("vctm: display[%d]: %w", i, err))
+		}
+	}
+
+	for i, claim := range v.Claims {
+		if len(claim.Path) == 0 {
+			errs = append(errs, fmt.Errorf("vctm: claims[%d]: path must not be empty", i))
+		}
+		for j, element := range claim.Path {
+			if !IsPathElement(element) {
+				errs = append(errs, fmt.Errorf("vctm: claims[%d]: path[%d] must be a string, a non-negative integer or null, got %v", i, j, element))
+			}
+		}
+		if err := ValidateSD(claim.SD); err != nil {
+			errs = append(errs, fmt.Errorf("vctm: claims[%d]: %w", i, err))
+		}
+		for j, display := range claim.Display {
+			if err := ValidateLocale(display.Locale); err != nil {
+				errs = append(errs, fmt.Errorf("vctm: claims[%d]: display[%d]: %w", i, j, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsPathElement reports whether element is a valid claim path element. Integers
+// decoded from JSON arrive as float64 and are accepted when they are whole numbers.
+func IsPathElement(element interface{}) bool {
+	switch e := element.(type) {
+	case nil, string:
+		return true
+	case int:
+		return e >= 0
+	case int64:
+		return e >= 0
+	case float64:
+		return e >= 0 && e == math.Trunc(e)
+	default:
+		return false
+	}
+}
+
 // ToJSON serializes the VCTM to JSON
 func (v *VCTM) ToJSON() ([]byte, error) {
 	if err := v.Validate(); err != nil {
@@ -2,6 +2,8 @@ package vctm
 
 import (
 	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -56,6 +58,111 @@ func TestVCTM_Validate(t *testing.T) {
 	}
 }
 
+func TestVCTM_Validate_FailureModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		vctm    VCTM
+		wantErr string
+	}{
+		{
+			name:    "missing display locale",
+			vctm:    VCTM{VCT: "urn:test", Display: []DisplayProperties{{Name: "Test"}}},
+			wantErr: "display[0]: locale is required",
+		},
+		{
+			name:    "invalid display locale",
+			vctm:    VCTM{VCT: "urn:test", Display: []DisplayProperties{{Locale: "en-US"}, {Locale: "en_US"}}},
+			wantErr: `display[1]: invalid locale "en_US"`,
+		},
+		{
+			name:    "empty claim path",
+			vctm:    VCTM{VCT: "urn:test", Claims: []ClaimMetadataEntry{{Path: []interface{}{}}}},
+			wantErr: "claims[0]: path must not be empty",
+		},
+		{
+			name:    "invalid claim path element",
+			vctm:    VCTM{VCT: "urn:test", Claims: []ClaimMetadataEntry{{Path: []interface{}{"a", true}}}},
+			wantErr: "claims[0]: path[1] must be a string, a non-negative integer or null",
+		},
+		{
+			name:    "fractional claim path index",
+			vctm:    VCTM{VCT: "urn:test", Claims: []ClaimMetadataEntry{{Path: []interface{}{"a", 1.5}}}},
+			wantErr: "claims[0]: path[1] must be",
+		},
+		{
+			name:    "invalid sd",
+			vctm:    VCTM{VCT: "urn:test", Claims: []ClaimMetadataEntry{{Path: []interface{}{"a"}, SD: "sometimes"}}},
+			wantErr: `claims[0]: invalid sd "sometimes"`,
+		},
+		{
+			name:    "invalid claim display locale",
+			vctm:    VCTM{VCT: "urn:test", Claims: []ClaimMetadataEntry{{Path: []interface{}{"a"}, Display: []ClaimDisplay{{Locale: "en_US"}}}}},
+			wantErr: `claims[0]: display[0]: invalid locale "en_US"`,
+		},
+		{
+			name:    "extends integrity without extends",
+			vctm:    VCTM{VCT: "urn:test", ExtendsIntegrity: "sha256-abc"},
+			wantErr: "extends#integrity requires extends",
+		},
+		{
+			name:    "schema and schema_uri",
+			vctm:    VCTM{VCT: "urn:test", Schema: json.RawMessage(`{"type":"object"}`), SchemaURI: "https://example.com/schema.json"},
+			wantErr: "schema and schema_uri must not both be present",
+		},
+		{
+			name:    "schema_uri integrity without schema_uri",
+			vctm:    VCTM{VCT: "urn:test", SchemaURIIntegrity: "sha256-abc"},
+			wantErr: "schema_uri#integrity requires schema_uri",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.vctm.Validate()
+			if err == nil {
+				t.Fatalf("VCTM.Validate() error = nil, want %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("VCTM.Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVCTM_Validate_ReportsAllProblems(t *testing.T) {
+	v := VCTM{
+		ExtendsIntegrity: "sha256-abc",
+		Display:          []DisplayProperties{{Name: "Test"}},
+		Claims: []ClaimMetadataEntry{
+			{Path: []interface{}{"nationalities", nil}, SD: "always"},
+			{Path: []interface{}{"addresses", float64(0)}, SD: "never"},
+			{SD: "maybe"},
+		},
+	}
+
+	err := v.Validate()
+	if err == nil {
+		t.Fatal("VCTM.Validate() error = nil, want joined errors")
+	}
+	// vct, extends#integrity, display locale, empty path and sd
+	if got := len(strings.Split(err.Error(), "\n")); got != 5 {
+		t.Errorf("VCTM.Validate() reported %d problems, want 5:\n%v", got, err)
+	}
+}
+
+func TestIsLanguageTag(t *testing.T) {
+	for _, tag := range []string{"en", "en-US", "sv", "de-DE", "zh-Hant-TW", "sr-Latn-RS", "es-419", "de-CH-1901", "en-US-x-twain", "x-private"} {
+		if !IsLanguageTag(tag) {
+			t.Errorf("IsLanguageTag(%q) = false, want true", tag)
+		}
+	}
+	for _, tag := range []string{"", "e", "en_US", "english-", "en-US-", "123"} {
+		if IsLanguageTag(tag) {
+			t.Errorf("IsLanguageTag(%q) = true, want false", tag)
+		}
+	}
+}
+
 func TestVCTM_ToJSON(t *testing.T) {
 	vctm := &VCTM{
 		VCT:         "https://example.com/credential/test",
@@ -111,6 +218,42 @@ func TestVCTM_ToJSON(t *testing.T) {
 	}
 }
 
+func TestVCTM_ToJSON_Schema(t *testing.T) {
+	withURI := &VCTM{
+		VCT:                "urn:test",
+		SchemaURI:          "https://example.com/schema.json",
+		SchemaURIIntegrity: "sha256-abc",
+	}
+	data, err := withURI.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if doc["schema_uri"] != "https://example.com/schema.json" || doc["schema_uri#integrity"] != "sha256-abc" {
+		t.Errorf("schema_uri fields = %v, %v", doc["schema_uri"], doc["schema_uri#integrity"])
+	}
+	if _, ok := doc["schema"]; ok {
+		t.Error("empty schema should be omitted")
+	}
+
+	embedded := &VCTM{VCT: "urn:test", Schema: json.RawMessage(`{"type":"object"}`)}
+	data, err = embedded.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	parsed, err := FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(parsed.Schema, &schema); err != nil || schema["type"] != "object" {
+		t.Errorf("embedded schema = %s, want round-tripped object schema", parsed.Schema)
+	}
+}
+
 func TestVCTM_ToJSON_Invalid(t *testing.T) {
 	vctm := &VCTM{}
 	_, err := vctm.ToJSON()
@@ -139,6 +282,36 @@ func TestFromJSON(t *testing.T) {
 	}
 }
 
+func TestVCTM_ClaimsRoundTrip(t *testing.T) {
+	original := &VCTM{
+		VCT: "urn:test",
+		Claims: []ClaimMetadataEntry{
+			{Path: []interface{}{"given_name"}, Mandatory: true, SD: "never"},
+			{Path: []interface{}{"nationalities", nil}, SD: "always"},
+		},
+	}
+	data, err := original.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if claims, ok := doc["claims"].([]interface{}); !ok || len(claims) != 2 {
+		t.Fatalf("claims = %v, want an array of 2 entries", doc["claims"])
+	}
+
+	parsed, err := FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	if len(parsed.Claims) != 2 || parsed.Claims[1].Path[1] != nil || parsed.Claims[0].SD != "never" || !parsed.Claims[0].Mandatory {
+		t.Errorf("Claims = %+v", parsed.Claims)
+	}
+}
+
 func TestFromJSON_Invalid(t *testing.T) {
 	tests := []struct {
 		name string
@@ -152,6 +325,10 @@ func TestFromJSON_Invalid(t *testing.T) {
 			name: "missing vct",
 			json: `{"name": "Test"}`,
 		},
+		{
+			name: "claims object keyed by name",
+			json: `{"vct": "urn:test", "claims": {"given_name": {"sd": "always"}}}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -224,3 +401,63 @@ func TestRendering_JSON(t *testing.T) {
 		t.Errorf("SVGTemplates count mismatch")
 	}
 }
+
+func TestVCTM_RenderingRoundTrip(t *testing.T) {
+	original := &VCTM{
+		VCT:  "urn:test",
+		Name: "Test Credential",
+		Display: []DisplayProperties{
+			{
+				Locale: "en-US",
+				Name:   "Test Credential",
+				Rendering: &Rendering{
+					Simple: &SimpleRendering{
+						Logo: &Logo{
+							URI:          "https://example.com/logo.png",
+							URIIntegrity: "sha256-logo",
+							AltText:      "Logo",
+						},
+						BackgroundImage: &BackgroundImage{
+							URI:          "https://example.com/background.png",
+							URIIntegrity: "sha256-background",
+						},
+						BackgroundColor: "#12107c",
+						TextColor:       "#ffffff",
+					},
+					SVGTemplates: []SVGTemplate{
+						{
+							URI:          "https://example.com/portrait.svg",
+							URIIntegrity: "sha256-portrait",
+							Properties: &SVGTemplateProperties{
+								Orientation: "portrait",
+								ColorScheme: "light",
+								Contrast:    "normal",
+							},
+						},
+						{
+							URI:          "https://example.com/landscape.svg",
+							URIIntegrity: "sha256-landscape",
+							Properties: &SVGTemplateProperties{
+								Orientation: "landscape",
+								ColorScheme: "dark",
+								Contrast:    "high",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	data, err := original.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	parsed, err := FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, original) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", parsed.Display[0].Rendering, original.Display[0].Rendering)
+	}
+}